@@ -79,71 +79,182 @@ func run(appRoot string, opts Options) error {
 		return fmt.Errorf("constellation_config.yaml: name is required")
 	}
 
-	// ---- Validate and de-duplicate chars, build per-char constraints -------
+	// ---- Build team compositions -------------------------------------------
 
-	seen := make(map[string]struct{}, len(cfg.Chars))
-	chars := make([]string, 0, len(cfg.Chars))
-	charEntryStrs := make([]string, 0, len(cfg.Chars))
-	for _, entry := range cfg.Chars {
-		entry = strings.TrimSpace(entry)
-		if entry == "" {
-			continue
+	comps, err := resolveCompositions(configStr, cfg)
+	if err != nil {
+		return err
+	}
+	tracked := make(map[string]struct{})
+	for _, comp := range comps {
+		for _, entry := range comp.Entries {
+			tracked[appconfig.ExtractCharName(entry)] = struct{}{}
 		}
-		name := appconfig.ExtractCharName(entry)
-		if name == "" {
-			continue
+	}
+	for ch := range cfg.Cost.CharConsPulls {
+		if _, ok := tracked[ch]; !ok {
+			return fmt.Errorf("constellation_config.yaml: cost.char_cons_pulls: %q is not in chars", ch)
+		}
+	}
+
+	// ---- Resolve engine and shared settings --------------------------------
+
+	engineRoot, err := engine.ResolveRoot(appRoot, cfg)
+	if err != nil {
+		return err
+	}
+
+	maxAdditional := -1 // -1 = unlimited
+	if cfg.MaxAdditional != nil {
+		maxAdditional = *cfg.MaxAdditional
+		if maxAdditional < 0 {
+			return fmt.Errorf("constellation_config.yaml: max_additional must be >= 0")
+		}
+	}
+
+	strategy, beamWidth, err := resolveSearch(cfg.Search)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := ensureWorkDir(appRoot)
+	if err != nil {
+		return err
+	}
+
+	settings := runSettings{
+		appRoot:       appRoot,
+		cfg:           cfg,
+		maxAdditional: maxAdditional,
+		strategy:      strategy,
+		beamWidth:     beamWidth,
+		tempConfig:    filepath.Join(workDir, "temp_config.txt"),
+		runner: sim.CLIRunner{
+			EngineRoot:       engineRoot,
+			OptimizeSubstats: cfg.OptimizeSubstats == nil || *cfg.OptimizeSubstats,
+		},
+	}
+
+	// ---- Run every composition ---------------------------------------------
+
+	var simElapsed time.Duration
+	var reports []output.CompositionReport
+	for _, comp := range comps {
+		if ctx.Err() != nil {
+			break
+		}
+		runName := name
+		if comp.Name != "" {
+			runName = name + "_" + comp.Name
+			fmt.Printf("\n===== Composition %s =====\n", comp.Name)
+		}
+		report, elapsed, err := runComposition(ctx, settings, runName, comp)
+		simElapsed += elapsed
+		if err != nil {
+			if comp.Name != "" {
+				return fmt.Errorf("composition %q: %w", comp.Name, err)
+			}
+			return err
+		}
+		if report != nil {
+			reports = append(reports, output.CompositionReport{Name: comp.Name, Report: *report})
+		}
+	}
+
+	if len(comps) > 1 && len(reports) > 0 {
+		xlsxPath, err := output.ExportCompositionsXLSX(appRoot, name, reports)
+		if err != nil {
+			return err
 		}
+		fmt.Println("Exported composition comparison to", xlsxPath)
+	}
+
+	totalElapsed := time.Since(totalStart)
+	appElapsed := totalElapsed - simElapsed
+	if appElapsed < 0 {
+		appElapsed = 0
+	}
+	fmt.Printf("Timing: total=%s, app=%s, simulations=%s\n",
+		totalElapsed.Round(time.Second),
+		appElapsed.Round(time.Second),
+		simElapsed.Round(time.Second),
+	)
+	fmt.Println("Finished at", time.Now().Format(time.RFC3339))
+	return nil
+}
+
+// runSettings are the settings shared by all compositions of a run.
+type runSettings struct {
+	appRoot       string
+	cfg           domain.Config
+	maxAdditional int
+	strategy      string
+	beamWidth     int
+	tempConfig    string
+	runner        sim.CLIRunner
+}
+
+// runComposition simulates one team composition and exports its XLSX under runName.
+// It returns the exported report (nil when there was nothing to export) and the time spent in the engine.
+func runComposition(ctx context.Context, s runSettings, runName string, comp composition) (*output.Report, time.Duration, error) {
+	cfg := s.cfg
+	configStr := comp.ConfigStr
+
+	// ---- Validate and de-duplicate chars, build per-char constraints -------
+
+	seen := make(map[string]struct{}, len(comp.Entries))
+	chars := make([]string, 0, len(comp.Entries))
+	charEntryStrs := make([]string, 0, len(comp.Entries))
+	for _, entry := range comp.Entries {
+		name := appconfig.ExtractCharName(entry)
 		if _, ok := seen[name]; ok {
-			return fmt.Errorf("constellation_config.yaml: duplicate char %q", name)
+			return nil, 0, fmt.Errorf("constellation_config.yaml: duplicate char %q", name)
 		}
 		seen[name] = struct{}{}
 		chars = append(chars, name)
 		charEntryStrs = append(charEntryStrs, entry)
 	}
 	if len(chars) == 0 {
-		return fmt.Errorf("constellation_config.yaml: at least one char is required")
-	}
-	if len(chars) > 4 {
-		return fmt.Errorf("constellation_config.yaml: at most 4 chars allowed, got %d", len(chars))
+		return nil, 0, fmt.Errorf("constellation_config.yaml: at least one char is required")
 	}
 
 	// ---- Read baseline constellations from config.txt and build allowed levels
 
 	allowedByChar := make(map[string][]int, len(chars))
 	minLevels := make(map[string]int, len(chars))
+	weaponsByChar := make(map[string][]domain.Weapon)
 	for i, ch := range chars {
 		baselineCons, err := appconfig.ParseCurrentCons(configStr, ch)
 		if err != nil {
-			return fmt.Errorf("config.txt: %w", err)
+			return nil, 0, fmt.Errorf("config.txt: %w", err)
 		}
 		entry, err := appconfig.ParseCharEntry(charEntryStrs[i], baselineCons)
 		if err != nil {
-			return fmt.Errorf("constellation_config.yaml: %w", err)
+			return nil, 0, fmt.Errorf("constellation_config.yaml: %w", err)
 		}
 		allowedByChar[ch] = entry.AllowedLevels
 		minLevels[ch] = entry.AllowedLevels[0]
-	}
 
-	// ---- Resolve engine ----------------------------------------------------
-
-	engineRoot, err := engine.ResolveRoot(appRoot, cfg)
-	if err != nil {
-		return err
+		weapons, err := buildWeaponOptions(configStr, ch, entry.Weapons)
+		if err != nil {
+			return nil, 0, fmt.Errorf("config.txt: %w", err)
+		}
+		if len(weapons) > 0 {
+			weaponsByChar[ch] = weapons
+		}
 	}
 
 	// ---- Generate combinations ---------------------------------------------
 
-	maxAdditional := -1 // -1 = unlimited
-	if cfg.MaxAdditional != nil {
-		maxAdditional = *cfg.MaxAdditional
-		if maxAdditional < 0 {
-			return fmt.Errorf("constellation_config.yaml: max_additional must be >= 0")
-		}
+	maxAdditional := s.maxAdditional
+	var combos []domain.Combination
+	if s.strategy == searchExhaustive {
+		combos = GenerateCombinationsWithWeapons(chars, allowedByChar, weaponsByChar, maxAdditional)
+		fmt.Printf("Total combinations to simulate: %d\n", len(combos))
+	} else {
+		fmt.Printf("Search strategy: %s (beam width %d)\n", s.strategy, s.beamWidth)
 	}
 
-	combos := GenerateCombinations(chars, allowedByChar, maxAdditional)
-	fmt.Printf("Total combinations to simulate: %d\n", len(combos))
-
 	// ---- Resume: find and import existing results --------------------------
 
 	var existingResults []domain.RunResult
@@ -153,8 +264,8 @@ func run(appRoot string, opts Options) error {
 			// Explicit import path provided.
 			basePath = cfg.ImportPath
 			fmt.Printf("Importing results from: %s\n", basePath)
-		} else if existing, ok, err := findExistingResultTable(appRoot, name); err != nil {
-			return err
+		} else if existing, ok, err := findExistingResultTable(s.appRoot, runName); err != nil {
+			return nil, 0, err
 		} else if ok {
 			basePath = existing
 			fmt.Printf("Found existing results: %s\n", filepath.Base(basePath))
@@ -176,119 +287,72 @@ func run(appRoot string, opts Options) error {
 					}
 					imported[i].Combination.TotalAdditional = total
 				}
+				fixupImportedWeapons(imported, weaponsByChar)
 				existingResults = imported
 			}
 		}
 	}
 
-	// Build lookup of already-computed combination keys.
-	baseLookup := make(map[string]struct{}, len(existingResults))
-	for _, r := range existingResults {
-		baseLookup[r.Combination.Key()] = struct{}{}
-	}
-
-	// Partition combos: missing first, then already-computed ones.
-	missingCombos := make([]domain.Combination, 0, len(combos))
-	doneCombos := make([]domain.Combination, 0, len(combos))
-	for _, c := range combos {
-		if _, ok := baseLookup[c.Key()]; ok {
-			doneCombos = append(doneCombos, c)
-		} else {
-			missingCombos = append(missingCombos, c)
-		}
-	}
-	if len(baseLookup) > 0 {
-		fmt.Printf("Already computed: %d, remaining: %d\n", len(doneCombos), len(missingCombos))
-	}
-
-	// ---- Work dir & runner -------------------------------------------------
-
-	workDir, err := ensureWorkDir(appRoot)
-	if err != nil {
-		return err
-	}
-	tempConfig := filepath.Join(workDir, "temp_config.txt")
-	runner := sim.CLIRunner{
-		EngineRoot:       engineRoot,
-		OptimizeSubstats: cfg.OptimizeSubstats == nil || *cfg.OptimizeSubstats,
-	}
-
-	// Precompute per-block combo counts for block headers.
-	blockCounts := make(map[int]int, 8)
-	for _, c := range missingCombos {
-		blockCounts[c.TotalAdditional]++
-	}
-
 	// ---- Run simulations ---------------------------------------------------
 
-	newResults := make([]domain.RunResult, 0, len(missingCombos))
+	var err error
+	var newResults []domain.RunResult
 	var simElapsed time.Duration
 	var engineFailures []string
 	canceled := false
 
-	total := len(missingCombos)
-	completed := 0
-	blockCompleted := 0
-	startProgress := time.Now()
-	var lastProgressPrint time.Time
-	currentBlock := -1
-
-	for _, combo := range missingCombos {
+	// simulate runs one combination. Engine errors are non-fatal (0 DPS);
+	// it returns context.Canceled on interrupt and other errors only for broken configs.
+	simulate := func(combo domain.Combination) (domain.RunResult, error) {
 		if ctx.Err() != nil {
-			canceled = true
-			break
-		}
-
-		// Block header when entering a new TotalAdditional group.
-		if combo.TotalAdditional != currentBlock {
-			currentBlock = combo.TotalAdditional
-			blockCompleted = 0
-			label := fmt.Sprintf("+%d", currentBlock)
-			if currentBlock == 0 {
-				label = "+0 (baseline)"
-			}
-			fmt.Printf("\n--- %s constellations: %d simulation(s) ---\n", label, blockCounts[currentBlock])
+			return domain.RunResult{}, context.Canceled
 		}
 
-		// Apply all character cons patches.
-		patchedConfig := configStr
-		for _, ch := range chars {
-			patchedConfig, err = appconfig.SetCons(patchedConfig, ch, combo.ConsByChar[ch])
-			if err != nil {
-				return fmt.Errorf("set cons for %s: %w", ch, err)
-			}
+		// Apply all character cons and weapon patches.
+		patchedConfig, err := applyCombination(configStr, chars, combo)
+		if err != nil {
+			return domain.RunResult{}, err
 		}
-
-		if err := writeTempConfig(tempConfig, patchedConfig); err != nil {
-			return err
+		if err := writeTempConfig(s.tempConfig, patchedConfig); err != nil {
+			return domain.RunResult{}, err
 		}
 
 		simStart := time.Now()
-		res, err := runner.Run(ctx, tempConfig)
+		res, err := s.runner.Run(ctx, s.tempConfig)
 		simElapsed += time.Since(simStart)
 
+		var result domain.RunResult
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
-				canceled = true
-				break
+				return domain.RunResult{}, context.Canceled
 			}
 			// Non-fatal engine error.
 			errSummary := lastNonEmptyLine(err.Error())
 			fmt.Fprintf(os.Stderr, "WARN: engine error for %s, treating as 0 DPS: %s\n", combo.Key(), errSummary)
 			engineFailures = append(engineFailures, fmt.Sprintf("%s: %s", combo.Key(), errSummary))
-			newResults = append(newResults, domain.RunResult{Combination: combo, TeamDps: 0, ConfigFile: ""})
+			result = domain.RunResult{Combination: combo, TeamDps: 0, ConfigFile: ""}
 		} else {
 			teamDps := int(math.Round(*res.Statistics.DPS.Mean))
-			newResults = append(newResults, domain.RunResult{
+			result = domain.RunResult{
 				Combination: combo,
 				TeamDps:     teamDps,
+				CharDps:     res.CharacterDps(),
 				ConfigFile:  res.ConfigFile,
-			})
+			}
 		}
+		newResults = append(newResults, result)
+		return result, nil
+	}
 
-		completed++
-		blockCompleted++
-		maybePrintProgress(completed, total, blockCompleted, blockCounts[currentBlock], startProgress, &lastProgressPrint)
+	var upgradePath []domain.RunResult
+	if s.strategy == searchExhaustive {
+		canceled, err = runExhaustive(combos, existingResults, simulate)
+	} else {
+		space := SearchSpace{Chars: chars, AllowedByChar: allowedByChar, WeaponsByChar: weaponsByChar, MaxAdditional: maxAdditional}
+		upgradePath, canceled, err = runSearch(space, s.beamWidth, existingResults, simulate)
+	}
+	if err != nil {
+		return nil, simElapsed, err
 	}
 
 	if canceled {
@@ -308,14 +372,14 @@ func run(appRoot string, opts Options) error {
 	allResults := mergeResults(existingResults, newResults)
 	if len(allResults) == 0 {
 		fmt.Fprintln(os.Stderr, "No results to export (all simulations may have failed or been interrupted before any completed).")
-		return nil
+		return nil, simElapsed, nil
 	}
 
-	// Determine baseline DPS (TotalAdditional == 0).
-	baselineDps := 0
+	// Determine baseline result (no additional constellations or weapon copies).
+	var baseline domain.RunResult
 	for _, r := range allResults {
-		if r.Combination.TotalAdditional == 0 {
-			baselineDps = r.TeamDps
+		if r.Combination.IsBaseline() {
+			baseline = r
 			break
 		}
 	}
@@ -328,24 +392,20 @@ func run(appRoot string, opts Options) error {
 		xlsxPath = basePath
 	}
 
-	xlsxPath, err = output.ExportXLSXToPath(appRoot, name, chars, allResults, baselineDps, xlsxPath)
+	report := output.Report{
+		Chars:       chars,
+		Team:        appconfig.ParseCharOrder(configStr),
+		Results:     allResults,
+		Baseline:    baseline,
+		Cost:        domain.NewCostModel(cfg.Cost, minLevels),
+		UpgradePath: upgradePath,
+	}
+	xlsxPath, err = output.ExportXLSXToPath(s.appRoot, runName, report, xlsxPath)
 	if err != nil {
-		return err
+		return nil, simElapsed, err
 	}
 	fmt.Println("Exported results to", xlsxPath)
-
-	totalElapsed := time.Since(totalStart)
-	appElapsed := totalElapsed - simElapsed
-	if appElapsed < 0 {
-		appElapsed = 0
-	}
-	fmt.Printf("Timing: total=%s, app=%s, simulations=%s\n",
-		totalElapsed.Round(time.Second),
-		appElapsed.Round(time.Second),
-		simElapsed.Round(time.Second),
-	)
-	fmt.Println("Finished at", time.Now().Format(time.RFC3339))
-	return nil
+	return &report, simElapsed, nil
 }
 
 // mergeResults merges existing (from imported XLSX) with newly computed results.
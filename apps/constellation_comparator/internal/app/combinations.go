@@ -12,23 +12,42 @@ import (
 // maxAdditional < 0 means unlimited.
 // Results are sorted by TotalAdditional ASC; the baseline (TotalAdditional == 0) is always first.
 func GenerateCombinations(chars []string, allowedByChar map[string][]int, maxAdditional int) []domain.Combination {
+	return GenerateCombinationsWithWeapons(chars, allowedByChar, nil, maxAdditional)
+}
+
+// GenerateCombinationsWithWeapons is GenerateCombinations with an additional weapon dimension.
+// weaponsByChar maps a character to its weapon options; characters without options keep
+// config.txt's weapon and get no WeaponByChar entry. maxAdditional limits constellations only.
+// Results are sorted by TotalAdditional ASC, then TotalCopies ASC; the baseline is always first
+// as long as every character's first weapon option has zero copies.
+func GenerateCombinationsWithWeapons(chars []string, allowedByChar map[string][]int, weaponsByChar map[string][]domain.Weapon, maxAdditional int) []domain.Combination {
 	if len(chars) == 0 {
 		return []domain.Combination{{ConsByChar: map[string]int{}, TotalAdditional: 0}}
 	}
 
 	var results []domain.Combination
 	current := make(map[string]int, len(chars))
+	currentWeapons := make(map[string]domain.Weapon, len(weaponsByChar))
 
-	var recurse func(idx int, totalExtra int)
-	recurse = func(idx int, totalExtra int) {
+	var recurse func(idx int, totalExtra int, totalCopies int)
+	recurse = func(idx int, totalExtra int, totalCopies int) {
 		if idx == len(chars) {
 			cons := make(map[string]int, len(chars))
 			for k, v := range current {
 				cons[k] = v
 			}
+			var weapons map[string]domain.Weapon
+			if len(currentWeapons) > 0 {
+				weapons = make(map[string]domain.Weapon, len(currentWeapons))
+				for k, v := range currentWeapons {
+					weapons[k] = v
+				}
+			}
 			results = append(results, domain.Combination{
 				ConsByChar:      cons,
 				TotalAdditional: totalExtra,
+				WeaponByChar:    weapons,
+				TotalCopies:     totalCopies,
 			})
 			return
 		}
@@ -36,21 +55,33 @@ func GenerateCombinations(chars []string, allowedByChar map[string][]int, maxAdd
 		char := chars[idx]
 		levels := allowedByChar[char]
 		minLevel := levels[0]
+		options := weaponsByChar[char]
 		for _, level := range levels {
 			extra := level - minLevel
 			if maxAdditional >= 0 && totalExtra+extra > maxAdditional {
 				break // levels are sorted; further values only grow
 			}
 			current[char] = level
-			recurse(idx+1, totalExtra+extra)
+			if len(options) == 0 {
+				recurse(idx+1, totalExtra+extra, totalCopies)
+				continue
+			}
+			for _, w := range options {
+				currentWeapons[char] = w
+				recurse(idx+1, totalExtra+extra, totalCopies+w.Copies)
+			}
+			delete(currentWeapons, char)
 		}
 	}
 
-	recurse(0, 0)
+	recurse(0, 0, 0)
 
-	// Sort by TotalAdditional ASC (stable to preserve enumeration order within the same level).
+	// Sort by TotalAdditional, then TotalCopies (stable to preserve enumeration order within the same level).
 	sort.SliceStable(results, func(i, j int) bool {
-		return results[i].TotalAdditional < results[j].TotalAdditional
+		if results[i].TotalAdditional != results[j].TotalAdditional {
+			return results[i].TotalAdditional < results[j].TotalAdditional
+		}
+		return results[i].TotalCopies < results[j].TotalCopies
 	})
 	return results
 }
@@ -157,3 +157,43 @@ func TestGenerateCombinations_ConstrainedLevels(t *testing.T) {
 			combos[0].TotalAdditional, combos[1].TotalAdditional, combos[2].TotalAdditional)
 	}
 }
+
+func TestGenerateCombinationsWithWeapons(t *testing.T) {
+	chars := []string{"arlecchino", "fischl"}
+	allowed := map[string][]int{
+		"arlecchino": levelsFrom(0, 1),
+		"fischl":     levelsFrom(6, 6),
+	}
+	weapons := map[string][]domain.Weapon{
+		"arlecchino": {
+			{Name: "deathmatch", Refine: 1, Copies: 0},
+			{Name: "deathmatch", Refine: 2, Copies: 1},
+			{Name: "crimsonmoonssemblance", Refine: 1, Copies: 1},
+		},
+	}
+	combos := app.GenerateCombinationsWithWeapons(chars, allowed, weapons, -1)
+	// 2 cons levels × 3 weapons for arlecchino, fischl fixed.
+	if len(combos) != 6 {
+		t.Fatalf("expected 6 combinations, got %d", len(combos))
+	}
+	if !combos[0].IsBaseline() {
+		t.Errorf("first combination should be baseline, got %s", combos[0].Key())
+	}
+	if w := combos[0].WeaponByChar["arlecchino"]; w.Name != "deathmatch" || w.Refine != 1 {
+		t.Errorf("baseline weapon should be deathmatch R1, got %s", w.Label())
+	}
+	if _, ok := combos[0].WeaponByChar["fischl"]; ok {
+		t.Errorf("fischl weapon is not varied and must not be in WeaponByChar")
+	}
+	keys := make(map[string]struct{}, len(combos))
+	for _, c := range combos {
+		keys[c.Key()] = struct{}{}
+	}
+	if len(keys) != len(combos) {
+		t.Errorf("combination keys are not unique: %v", keys)
+	}
+	last := combos[len(combos)-1]
+	if last.TotalAdditional != 1 || last.TotalCopies != 1 {
+		t.Errorf("last combination should be +1 cons/+1 copy, got %d/%d", last.TotalAdditional, last.TotalCopies)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	appconfig "github.com/genshinsim/gcsim/apps/constellation_comparator/internal/config"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+// composition is a resolved team: its config and the chars entries tracked in it.
+type composition struct {
+	// Name is empty for a plain run without compositions.
+	Name      string
+	ConfigStr string
+	Entries   []string
+}
+
+// resolveCompositions builds the teams of a run. Without compositions the run is config.txt with
+// the top-level chars; otherwise every composition gets its own config and the top-level chars
+// plus its own.
+func resolveCompositions(configStr string, cfg domain.Config) ([]composition, error) {
+	shared := cleanCharEntries(cfg.Chars)
+	if len(cfg.Compositions) == 0 {
+		return []composition{{ConfigStr: configStr, Entries: shared}}, nil
+	}
+
+	if cfg.ImportPath != "" {
+		return nil, fmt.Errorf("constellation_config.yaml: import_path is not supported with compositions")
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Compositions))
+	comps := make([]composition, 0, len(cfg.Compositions))
+	for i, c := range cfg.Compositions {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			return nil, fmt.Errorf("constellation_config.yaml: compositions[%d]: name is required", i)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("constellation_config.yaml: duplicate composition %q", name)
+		}
+		seen[name] = struct{}{}
+
+		teamConfig := configStr
+		replace := strings.TrimSpace(c.Replace)
+		if strings.TrimSpace(c.Block) != "" {
+			var err error
+			teamConfig, err = appconfig.ComposeTeam(configStr, replace, c.Block)
+			if err != nil {
+				return nil, fmt.Errorf("constellation_config.yaml: composition %q: %w", name, err)
+			}
+		} else if replace != "" {
+			return nil, fmt.Errorf("constellation_config.yaml: composition %q: replace requires block", name)
+		}
+
+		entries := append(append([]string(nil), shared...), cleanCharEntries(c.Chars)...)
+		comps = append(comps, composition{Name: name, ConfigStr: teamConfig, Entries: entries})
+	}
+	return comps, nil
+}
+
+// cleanCharEntries drops blank chars entries and trims the rest.
+func cleanCharEntries(entries []string) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || appconfig.ExtractCharName(entry) == "" {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
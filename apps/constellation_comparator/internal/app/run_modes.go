@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+const (
+	searchExhaustive = "exhaustive"
+	searchGreedy     = "greedy"
+	searchBeam       = "beam"
+
+	defaultBeamWidth = 3
+)
+
+// resolveSearch validates the search section of constellation_config.yaml.
+func resolveSearch(cfg domain.SearchConfig) (string, int, error) {
+	strategy := strings.ToLower(strings.TrimSpace(cfg.Strategy))
+	switch strategy {
+	case "", searchExhaustive:
+		return searchExhaustive, 0, nil
+	case searchGreedy:
+		return searchGreedy, 1, nil
+	case searchBeam:
+		width := cfg.BeamWidth
+		if width == 0 {
+			width = defaultBeamWidth
+		}
+		if width < 1 {
+			return "", 0, fmt.Errorf("constellation_config.yaml: search.beam_width must be >= 1")
+		}
+		return searchBeam, width, nil
+	default:
+		return "", 0, fmt.Errorf("constellation_config.yaml: unknown search.strategy %q (expected exhaustive, greedy or beam)", cfg.Strategy)
+	}
+}
+
+// runExhaustive simulates every combination missing from existing, grouped into blocks by TotalAdditional.
+// It reports whether the run was interrupted.
+func runExhaustive(combos []domain.Combination, existing []domain.RunResult, simulate Evaluator) (bool, error) {
+	// Build lookup of already-computed combination keys.
+	baseLookup := make(map[string]struct{}, len(existing))
+	for _, r := range existing {
+		baseLookup[r.Combination.Key()] = struct{}{}
+	}
+
+	// Partition combos: missing first, then already-computed ones.
+	missingCombos := make([]domain.Combination, 0, len(combos))
+	doneCombos := make([]domain.Combination, 0, len(combos))
+	for _, c := range combos {
+		if _, ok := baseLookup[c.Key()]; ok {
+			doneCombos = append(doneCombos, c)
+		} else {
+			missingCombos = append(missingCombos, c)
+		}
+	}
+	if len(baseLookup) > 0 {
+		fmt.Printf("Already computed: %d, remaining: %d\n", len(doneCombos), len(missingCombos))
+	}
+
+	// Precompute per-block combo counts for block headers.
+	blockCounts := make(map[int]int, 8)
+	for _, c := range missingCombos {
+		blockCounts[c.TotalAdditional]++
+	}
+
+	total := len(missingCombos)
+	completed := 0
+	blockCompleted := 0
+	startProgress := time.Now()
+	var lastProgressPrint time.Time
+	currentBlock := -1
+
+	for _, combo := range missingCombos {
+		// Block header when entering a new TotalAdditional group.
+		if combo.TotalAdditional != currentBlock {
+			currentBlock = combo.TotalAdditional
+			blockCompleted = 0
+			label := fmt.Sprintf("+%d", currentBlock)
+			if currentBlock == 0 {
+				label = "+0 (baseline)"
+			}
+			fmt.Printf("\n--- %s constellations: %d simulation(s) ---\n", label, blockCounts[currentBlock])
+		}
+
+		if _, err := simulate(combo); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		completed++
+		blockCompleted++
+		maybePrintProgress(completed, total, blockCompleted, blockCounts[currentBlock], startProgress, &lastProgressPrint)
+	}
+	return false, nil
+}
+
+// runSearch runs greedy/beam search, reusing existing results (e.g. an imported full grid)
+// instead of simulating them again. It returns the upgrade path and whether the run was interrupted.
+func runSearch(space SearchSpace, width int, existing []domain.RunResult, simulate Evaluator) ([]domain.RunResult, bool, error) {
+	known := make(map[string]domain.RunResult, len(existing))
+	for _, r := range existing {
+		known[r.Combination.Key()] = r
+	}
+
+	simulated, reused := 0, 0
+	evaluate := func(combo domain.Combination) (domain.RunResult, error) {
+		if r, ok := known[combo.Key()]; ok {
+			reused++
+			// Keep the searched combination (with Copies/TotalAdditional) but the stored outcome.
+			r.Combination = combo
+			return r, nil
+		}
+		r, err := simulate(combo)
+		if err != nil {
+			return r, err
+		}
+		known[combo.Key()] = r
+		simulated++
+		fmt.Printf("Evaluated %d combination(s) (%d reused): %s -> %d\n", simulated+reused, reused, combo.Key(), r.TeamDps)
+		return r, nil
+	}
+
+	path, err := BeamSearch(space, width, evaluate)
+	canceled := false
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			return nil, false, err
+		}
+		canceled = true
+	}
+
+	fmt.Printf("\nSearch done: %d simulated, %d reused from existing results\n", simulated, reused)
+	if len(path) > 0 {
+		fmt.Println("Upgrade path:")
+		for i, r := range path {
+			fmt.Printf("  %d. %s: %d\n", i, r.Combination.Key(), r.TeamDps)
+		}
+	}
+	return path, canceled, nil
+}
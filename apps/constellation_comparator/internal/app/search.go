@@ -0,0 +1,185 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+// SearchSpace describes the combinations reachable by greedy/beam search.
+// It is the same space GenerateCombinationsWithWeapons enumerates.
+type SearchSpace struct {
+	Chars         []string
+	AllowedByChar map[string][]int
+	WeaponsByChar map[string][]domain.Weapon
+	MaxAdditional int // < 0 means unlimited
+}
+
+// Baseline returns the starting combination: every char at its lowest allowed level
+// with its first (baseline) weapon option.
+func (s SearchSpace) Baseline() domain.Combination {
+	c := domain.Combination{ConsByChar: make(map[string]int, len(s.Chars))}
+	for _, ch := range s.Chars {
+		c.ConsByChar[ch] = s.AllowedByChar[ch][0]
+		if opts := s.WeaponsByChar[ch]; len(opts) > 0 {
+			if c.WeaponByChar == nil {
+				c.WeaponByChar = make(map[string]domain.Weapon, len(s.WeaponsByChar))
+			}
+			c.WeaponByChar[ch] = opts[0]
+		}
+	}
+	return c
+}
+
+// Neighbours returns the combinations one upgrade step away from c: one char moves to its
+// next allowed constellation, to the next refine of its current weapon, or (from the
+// baseline weapon) to the lowest refine of an alternate weapon.
+func (s SearchSpace) Neighbours(c domain.Combination) []domain.Combination {
+	var out []domain.Combination
+	for _, ch := range s.Chars {
+		levels := s.AllowedByChar[ch]
+		for i, lvl := range levels {
+			if lvl != c.ConsByChar[ch] || i+1 >= len(levels) {
+				continue
+			}
+			delta := levels[i+1] - lvl
+			if s.MaxAdditional >= 0 && c.TotalAdditional+delta > s.MaxAdditional {
+				break
+			}
+			next := cloneCombination(c)
+			next.ConsByChar[ch] = levels[i+1]
+			next.TotalAdditional += delta
+			out = append(out, next)
+			break
+		}
+
+		cur, ok := c.WeaponByChar[ch]
+		if !ok {
+			continue
+		}
+		seenAlt := make(map[string]struct{})
+		for _, w := range s.WeaponsByChar[ch] {
+			step := false
+			switch {
+			case w.Name == cur.Name:
+				// next refine of the same weapon (options are sorted by refine per weapon)
+				step = w.Refine > cur.Refine && !hasRefineBetween(s.WeaponsByChar[ch], cur, w)
+			case cur.Copies == 0:
+				// switch from the baseline weapon to the lowest refine of an alternate
+				if _, seen := seenAlt[w.Name]; !seen {
+					seenAlt[w.Name] = struct{}{}
+					step = true
+				}
+			}
+			if !step {
+				continue
+			}
+			next := cloneCombination(c)
+			next.WeaponByChar[ch] = w
+			next.TotalCopies += w.Copies - cur.Copies
+			out = append(out, next)
+		}
+	}
+	return out
+}
+
+func hasRefineBetween(options []domain.Weapon, from, to domain.Weapon) bool {
+	for _, w := range options {
+		if w.Name == from.Name && w.Refine > from.Refine && w.Refine < to.Refine {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneCombination(c domain.Combination) domain.Combination {
+	out := c
+	out.ConsByChar = make(map[string]int, len(c.ConsByChar))
+	for k, v := range c.ConsByChar {
+		out.ConsByChar[k] = v
+	}
+	if c.WeaponByChar != nil {
+		out.WeaponByChar = make(map[string]domain.Weapon, len(c.WeaponByChar))
+		for k, v := range c.WeaponByChar {
+			out.WeaponByChar[k] = v
+		}
+	}
+	return out
+}
+
+// Evaluator returns the simulation result of a combination (computed or cached).
+type Evaluator func(domain.Combination) (domain.RunResult, error)
+
+// BeamSearch explores the space step by step from the baseline, keeping the width best
+// combinations (by team DPS) at every step; width 1 is the greedy "best next upgrade" search.
+// It returns the upgrade path from the baseline to the best fully invested combination
+// (one with no further upgrade steps). On an evaluation error it returns the best path
+// found so far together with the error.
+func BeamSearch(space SearchSpace, width int, evaluate Evaluator) ([]domain.RunResult, error) {
+	if width < 1 {
+		width = 1
+	}
+	type node struct {
+		res    domain.RunResult
+		parent *node
+	}
+	pathTo := func(n *node) []domain.RunResult {
+		var path []domain.RunResult
+		for ; n != nil; n = n.parent {
+			path = append(path, n.res)
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		return path
+	}
+	better := func(a, b *node) bool {
+		if a.res.TeamDps != b.res.TeamDps {
+			return a.res.TeamDps > b.res.TeamDps
+		}
+		return a.res.Combination.Key() < b.res.Combination.Key()
+	}
+
+	baseRes, err := evaluate(space.Baseline())
+	if err != nil {
+		return nil, err
+	}
+	beam := []*node{{res: baseRes}}
+	var finished []*node
+	for len(beam) > 0 {
+		var candidates []*node
+		seen := make(map[string]struct{})
+		for _, n := range beam {
+			neighbours := space.Neighbours(n.res.Combination)
+			if len(neighbours) == 0 {
+				finished = append(finished, n)
+				continue
+			}
+			for _, next := range neighbours {
+				key := next.Key()
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				res, err := evaluate(next)
+				if err != nil {
+					return pathTo(beam[0]), err
+				}
+				candidates = append(candidates, &node{res: res, parent: n})
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return better(candidates[i], candidates[j]) })
+		if len(candidates) > width {
+			candidates = candidates[:width]
+		}
+		beam = candidates
+	}
+
+	best := finished[0]
+	for _, n := range finished[1:] {
+		if better(n, best) {
+			best = n
+		}
+	}
+	return pathTo(best), nil
+}
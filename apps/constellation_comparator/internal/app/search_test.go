@@ -0,0 +1,110 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/app"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+// trapSpace: "b" C1 is a weak step but b C2 is a big one, so greedy gets stuck on "a".
+func trapSpace() (app.SearchSpace, app.Evaluator, *int) {
+	space := app.SearchSpace{
+		Chars:         []string{"a", "b"},
+		AllowedByChar: map[string][]int{"a": levelsFrom(0, 2), "b": levelsFrom(0, 2)},
+		MaxAdditional: 2,
+	}
+	gainA := []int{0, 10, 15}
+	gainB := []int{0, 5, 40}
+	calls := 0
+	eval := func(c domain.Combination) (domain.RunResult, error) {
+		calls++
+		dps := 100 + gainA[c.ConsByChar["a"]] + gainB[c.ConsByChar["b"]]
+		return domain.RunResult{Combination: c, TeamDps: dps}, nil
+	}
+	return space, eval, &calls
+}
+
+func TestBeamSearch_GreedyFollowsBestNextStep(t *testing.T) {
+	space, eval, _ := trapSpace()
+	path, err := app.BeamSearch(space, 1, eval)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(path) != 3 {
+		t.Fatalf("expected baseline + 2 steps, got %d", len(path))
+	}
+	if path[0].TeamDps != 100 || !path[0].Combination.IsBaseline() {
+		t.Errorf("path must start at baseline, got %+v", path[0])
+	}
+	if path[1].Combination.ConsByChar["a"] != 1 {
+		t.Errorf("greedy first step should be a C1, got %v", path[1].Combination.ConsByChar)
+	}
+	if path[2].TeamDps != 115 {
+		t.Errorf("expected greedy to end at 115, got %d", path[2].TeamDps)
+	}
+}
+
+func TestBeamSearch_WiderBeamEscapesTrap(t *testing.T) {
+	space, eval, calls := trapSpace()
+	path, err := app.BeamSearch(space, 2, eval)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := path[len(path)-1]
+	if last.TeamDps != 140 || last.Combination.ConsByChar["b"] != 2 {
+		t.Errorf("expected beam to reach b C2 (140), got %d %v", last.TeamDps, last.Combination.ConsByChar)
+	}
+	// Full grid with max_additional=2 has 6 combos; search must not evaluate more than that.
+	if *calls > 6 {
+		t.Errorf("expected at most 6 evaluations, got %d", *calls)
+	}
+}
+
+func TestBeamSearch_ErrorReturnsPartialPath(t *testing.T) {
+	space, eval, _ := trapSpace()
+	boom := errors.New("interrupted")
+	failing := func(c domain.Combination) (domain.RunResult, error) {
+		if c.TotalAdditional >= 2 {
+			return domain.RunResult{}, boom
+		}
+		return eval(c)
+	}
+	path, err := app.BeamSearch(space, 1, failing)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected evaluator error, got %v", err)
+	}
+	if len(path) != 2 || path[1].TeamDps != 110 {
+		t.Errorf("expected partial path baseline -> a C1, got %+v", path)
+	}
+}
+
+func TestSearchSpace_NeighboursWeapons(t *testing.T) {
+	space := app.SearchSpace{
+		Chars:         []string{"a"},
+		AllowedByChar: map[string][]int{"a": {0}},
+		WeaponsByChar: map[string][]domain.Weapon{"a": {
+			{Name: "base", Refine: 1},
+			{Name: "base", Refine: 2, Copies: 1},
+			{Name: "base", Refine: 3, Copies: 2},
+			{Name: "alt", Refine: 1, Copies: 1},
+			{Name: "alt", Refine: 2, Copies: 2},
+		}},
+		MaxAdditional: -1,
+	}
+	got := map[string]int{}
+	for _, n := range space.Neighbours(space.Baseline()) {
+		w := n.WeaponByChar["a"]
+		got[w.Label()] = n.TotalCopies
+	}
+	want := map[string]int{"base R2": 1, "alt R1": 1}
+	if len(got) != len(want) {
+		t.Fatalf("expected neighbours %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("neighbour %s: expected %d copies, got %d (all: %v)", k, v, got[k], got)
+		}
+	}
+}
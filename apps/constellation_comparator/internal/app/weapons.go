@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+
+	appconfig "github.com/genshinsim/gcsim/apps/constellation_comparator/internal/config"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+// buildWeaponOptions expands the weapon specs of a chars entry into concrete options.
+// The equipped weapon at its config.txt refine is always the baseline option (Copies = 0)
+// and comes first; r= refines above it cost the difference in copies, refines below it are
+// skipped (the higher refine is already owned). Alternate weapons count every refine as a copy.
+func buildWeaponOptions(configStr string, char string, specs []appconfig.WeaponSpec) ([]domain.Weapon, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	equipped, currentRefine, err := appconfig.ParseCurrentWeapon(configStr, char)
+	if err != nil {
+		return nil, err
+	}
+
+	var equippedRefines []int
+	var alternates []appconfig.WeaponSpec
+	for _, spec := range specs {
+		if spec.Name == "" || spec.Name == equipped {
+			equippedRefines = spec.Refines
+			continue
+		}
+		alternates = append(alternates, spec)
+	}
+
+	seen := make(map[string]struct{})
+	options := make([]domain.Weapon, 0, 1+len(equippedRefines))
+	add := func(w domain.Weapon) {
+		key := w.Label()
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		options = append(options, w)
+	}
+	add(domain.Weapon{Name: equipped, Refine: currentRefine})
+	for _, r := range equippedRefines {
+		if r < currentRefine {
+			continue
+		}
+		add(domain.Weapon{Name: equipped, Refine: r, Copies: r - currentRefine})
+	}
+	for _, spec := range alternates {
+		for _, r := range spec.Refines {
+			add(domain.Weapon{Name: spec.Name, Refine: r, Copies: r})
+		}
+	}
+	return options, nil
+}
+
+// fixupImportedWeapons restores Copies/TotalCopies of imported results from the current weapon options.
+// Results with a weapon that is no longer an option keep Copies = 0 and simply won't match any combination.
+func fixupImportedWeapons(results []domain.RunResult, weaponsByChar map[string][]domain.Weapon) {
+	for i := range results {
+		total := 0
+		for ch, w := range results[i].Combination.WeaponByChar {
+			for _, opt := range weaponsByChar[ch] {
+				if opt.Name == w.Name && opt.Refine == w.Refine {
+					w.Copies = opt.Copies
+					break
+				}
+			}
+			results[i].Combination.WeaponByChar[ch] = w
+			total += w.Copies
+		}
+		results[i].Combination.TotalCopies = total
+	}
+}
+
+// applyCombination patches constellations and weapons of combo into configStr.
+func applyCombination(configStr string, chars []string, combo domain.Combination) (string, error) {
+	patched := configStr
+	var err error
+	for _, ch := range chars {
+		patched, err = appconfig.SetCons(patched, ch, combo.ConsByChar[ch])
+		if err != nil {
+			return "", fmt.Errorf("set cons for %s: %w", ch, err)
+		}
+		if w, ok := combo.WeaponByChar[ch]; ok {
+			patched, err = appconfig.SetWeapon(patched, ch, w.Name, w.Refine)
+			if err != nil {
+				return "", fmt.Errorf("set weapon for %s: %w", ch, err)
+			}
+		}
+	}
+	return patched, nil
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	appconfig "github.com/genshinsim/gcsim/apps/constellation_comparator/internal/config"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+)
+
+const weaponsConfig = `arlecchino char lvl=90/90 cons=0 talent=9,9,9;
+arlecchino add weapon="deathmatch" refine=2 lvl=90/90;
+`
+
+func TestBuildWeaponOptions_CopiesFromConfigRefine(t *testing.T) {
+	specs := []appconfig.WeaponSpec{
+		{Refines: []int{1, 2, 3, 4}},
+		{Name: "crimsonmoonssemblance", Refines: []int{1}},
+	}
+	got, err := buildWeaponOptions(weaponsConfig, "arlecchino", specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.Weapon{
+		{Name: "deathmatch", Refine: 2, Copies: 0},
+		{Name: "deathmatch", Refine: 3, Copies: 1},
+		{Name: "deathmatch", Refine: 4, Copies: 2},
+		{Name: "crimsonmoonssemblance", Refine: 1, Copies: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestBuildWeaponOptions_RefinesAboveConfigKeepBaseline(t *testing.T) {
+	specs := []appconfig.WeaponSpec{{Refines: []int{4, 5}}}
+	got, err := buildWeaponOptions(weaponsConfig, "arlecchino", specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.Weapon{
+		{Name: "deathmatch", Refine: 2, Copies: 0},
+		{Name: "deathmatch", Refine: 4, Copies: 2},
+		{Name: "deathmatch", Refine: 5, Copies: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
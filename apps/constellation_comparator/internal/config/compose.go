@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxTeamSize is the number of characters a gcsim team can hold.
+const MaxTeamSize = 4
+
+func charDefinitionPrefix(char string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^%s\s+(char|add)\s+`, regexp.QuoteMeta(char)))
+}
+
+// ComposeTeam builds an alternative team from configStr and a block of character lines
+// ("<char> char ...", "<char> add ..."). With replace set, the definition lines of that character
+// are swapped for block and every other mention of it (rotation, active, .char.skill.ready ...)
+// is renamed to the block's character. With replace empty, block is added after the last
+// character definition.
+// It is intentionally pure (string in, string out) to be easy to test.
+func ComposeTeam(configStr, replace, block string) (string, error) {
+	blockChars := ParseCharOrder(block)
+	if len(blockChars) != 1 {
+		return "", fmt.Errorf("block must define exactly one character, got %d", len(blockChars))
+	}
+	newChar := blockChars[0]
+	team := ParseCharOrder(configStr)
+	for _, ch := range team {
+		if ch == newChar && ch != replace {
+			return "", fmt.Errorf("character %s is already in the team", newChar)
+		}
+	}
+
+	blockLines := strings.Split(strings.TrimRight(block, "\n"), "\n")
+	lines := strings.Split(configStr, "\n")
+	out := make([]string, 0, len(lines)+len(blockLines))
+
+	if replace == "" {
+		if len(team) >= MaxTeamSize {
+			return "", fmt.Errorf("team already has %d characters; set replace to swap one out", len(team))
+		}
+		last := -1
+		for i, line := range lines {
+			for _, ch := range team {
+				if charDefinitionPrefix(ch).MatchString(strings.TrimSpace(line)) {
+					last = i
+				}
+			}
+		}
+		out = append(out, lines[:last+1]...)
+		if last >= 0 {
+			out = append(out, "")
+		}
+		out = append(out, blockLines...)
+		out = append(out, lines[last+1:]...)
+		return strings.Join(out, "\n"), nil
+	}
+
+	prefix := charDefinitionPrefix(replace)
+	mention := regexp.MustCompile(`\b` + regexp.QuoteMeta(replace) + `\b`)
+	inserted := false
+	for _, line := range lines {
+		if prefix.MatchString(strings.TrimSpace(line)) {
+			if !inserted {
+				out = append(out, blockLines...)
+				inserted = true
+			}
+			continue
+		}
+		out = append(out, mention.ReplaceAllLiteralString(line, newChar))
+	}
+	if !inserted {
+		return "", fmt.Errorf("character %s: char line not found in config", replace)
+	}
+	return strings.Join(out, "\n"), nil
+}
@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/config"
+)
+
+const composeConfig = `xiangling char lvl=90/90 cons=6 talent=9,9,9;
+xiangling add weapon="thecatch" refine=5 lvl=90/90;
+sucrose char lvl=90/90 cons=6 talent=9,9,9;
+sucrose add weapon="sacrificialfragments" refine=1 lvl=90/90;
+sucrose add set="vv" count=4;
+
+active sucrose;
+sucrose skill;
+if .sucrose.burst.ready {
+  sucrose burst;
+}
+xiangling burst;
+`
+
+const kazuhaBlock = `kazuha char lvl=90/90 cons=0 talent=9,9,9;
+kazuha add weapon="freedomsworn" refine=1 lvl=90/90;
+kazuha add set="vv" count=4;
+`
+
+func TestComposeTeam_Replace(t *testing.T) {
+	got, err := config.ComposeTeam(composeConfig, "sucrose", kazuhaBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "sucrose") {
+		t.Errorf("replaced character still mentioned:\n%s", got)
+	}
+	order := config.ParseCharOrder(got)
+	if strings.Join(order, ",") != "xiangling,kazuha" {
+		t.Errorf("team order = %v, want [xiangling kazuha]", order)
+	}
+	for _, want := range []string{"active kazuha;", "if .kazuha.burst.ready {", `kazuha add weapon="freedomsworn"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestComposeTeam_Add(t *testing.T) {
+	got, err := config.ComposeTeam(composeConfig, "", kazuhaBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := config.ParseCharOrder(got)
+	if strings.Join(order, ",") != "xiangling,sucrose,kazuha" {
+		t.Errorf("team order = %v, want [xiangling sucrose kazuha]", order)
+	}
+	if !strings.Contains(got, "sucrose skill;") {
+		t.Errorf("rotation must be kept when adding a character:\n%s", got)
+	}
+}
+
+func TestComposeTeam_Errors(t *testing.T) {
+	if _, err := config.ComposeTeam(composeConfig, "bennett", kazuhaBlock); err == nil {
+		t.Error("expected error for unknown replaced character")
+	}
+	if _, err := config.ComposeTeam(composeConfig, "", "xiangling char lvl=90/90 cons=0 talent=9,9,9;"); err == nil {
+		t.Error("expected error for a character already in the team")
+	}
+	if _, err := config.ComposeTeam(composeConfig, "sucrose", "kazuha add set=\"vv\" count=4;"); err == nil {
+		t.Error("expected error for a block without a char line")
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reWeaponName   = regexp.MustCompile(`weapon="([^"]*)"`)
+	reWeaponRefine = regexp.MustCompile(`refine=([0-9]+)`)
+)
+
+func weaponLinePrefix(char string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^%s\s+add\s+weapon=`, regexp.QuoteMeta(char)))
+}
+
+// ParseCurrentWeapon reads the weapon key and refine from the "<char> add weapon=... refine=N" line.
+func ParseCurrentWeapon(configStr, char string) (string, int, error) {
+	prefix := weaponLinePrefix(char)
+	for _, line := range strings.Split(configStr, "\n") {
+		if !prefix.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		name := reWeaponName.FindStringSubmatch(line)
+		if name == nil {
+			return "", 0, fmt.Errorf("character %s: weapon line found but weapon is not quoted", char)
+		}
+		m := reWeaponRefine.FindStringSubmatch(line)
+		if m == nil {
+			return "", 0, fmt.Errorf("character %s: weapon line found but missing refine= token", char)
+		}
+		refine, err := strconv.Atoi(m[1])
+		if err != nil {
+			return "", 0, fmt.Errorf("character %s: invalid refine value %q", char, m[1])
+		}
+		return name[1], refine, nil
+	}
+	return "", 0, fmt.Errorf("character %s: weapon line not found in config", char)
+}
+
+// SetWeapon replaces the weapon key and refine on the "<char> add weapon=..." line.
+// It is intentionally pure (string in, string out) to be easy to test.
+func SetWeapon(configStr, char, weapon string, refine int) (string, error) {
+	if refine < 1 || refine > 5 {
+		return "", fmt.Errorf("character %s: refine must be in [1..5], got %d", char, refine)
+	}
+
+	lines := strings.Split(configStr, "\n")
+	prefix := weaponLinePrefix(char)
+	for i, line := range lines {
+		if !prefix.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		if !reWeaponName.MatchString(line) || !reWeaponRefine.MatchString(line) {
+			return "", fmt.Errorf("character %s: weapon line found but missing weapon=\"...\" or refine= token", char)
+		}
+		line = reWeaponName.ReplaceAllLiteralString(line, fmt.Sprintf("weapon=%q", weapon))
+		lines[i] = reWeaponRefine.ReplaceAllString(line, fmt.Sprintf("refine=%d", refine))
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", fmt.Errorf("character %s: weapon line not found in config", char)
+}
@@ -0,0 +1,43 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/config"
+)
+
+const weaponConfig = `arlecchino char lvl=90/90 cons=0 talent=9,9,9;
+arlecchino add weapon="deathmatch" refine=1 lvl=90/90;
+fischl char lvl=90/90 cons=6 talent=9,9,9;
+fischl add weapon="thestringless" refine=5 lvl=90/90;
+`
+
+func TestParseCurrentWeapon(t *testing.T) {
+	name, refine, err := config.ParseCurrentWeapon(weaponConfig, "fischl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "thestringless" || refine != 5 {
+		t.Errorf("got %s R%d, want thestringless R5", name, refine)
+	}
+	if _, _, err := config.ParseCurrentWeapon(weaponConfig, "bennett"); err == nil {
+		t.Error("expected error for missing weapon line")
+	}
+}
+
+func TestSetWeapon(t *testing.T) {
+	got, err := config.SetWeapon(weaponConfig, "arlecchino", "crimsonmoonssemblance", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `arlecchino add weapon="crimsonmoonssemblance" refine=2 lvl=90/90;`) {
+		t.Errorf("weapon line not replaced:\n%s", got)
+	}
+	if !strings.Contains(got, `fischl add weapon="thestringless" refine=5 lvl=90/90;`) {
+		t.Errorf("other weapon line changed:\n%s", got)
+	}
+	if _, err := config.SetWeapon(weaponConfig, "arlecchino", "deathmatch", 6); err == nil {
+		t.Error("expected error for refine 6")
+	}
+}
@@ -11,6 +11,15 @@ import (
 type CharEntry struct {
 	Name          string
 	AllowedLevels []int // sorted ascending, always non-empty
+	// Weapons lists weapon variations; empty means the weapon from config.txt is kept as is.
+	Weapons []WeaponSpec
+}
+
+// WeaponSpec is one weapon variation of a chars entry.
+type WeaponSpec struct {
+	// Name is the weapon key; empty means the weapon equipped in config.txt.
+	Name    string
+	Refines []int // sorted ascending, always non-empty
 }
 
 // ParseCharEntry parses a chars YAML entry string into a CharEntry.
@@ -22,6 +31,8 @@ type CharEntry struct {
 //	+N   force-include constellation N (0–6); excluded by -N if conflict
 //	-N   force-exclude constellation N (0–6); highest priority, overrides +N and range
 //	N    unsigned bound – one value sets the upper bound, two values set lower and upper
+//	r=A-B  refine range of the weapon equipped in config.txt (r=N for a single refine)
+//	w=KEY  alternate weapon at R1; w=KEY:A-B (or w=KEY:N) sets its refine range; may repeat
 //
 // Range defaults to [baselineCons..6] when no unsigned bounds are given.
 // Final allowed set = (range ∪ +N inclusions) \ -N exclusions.
@@ -37,8 +48,28 @@ func ParseCharEntry(entry string, baselineCons int) (CharEntry, error) {
 	includeSet := make(map[int]struct{})
 	excludeSet := make(map[int]struct{})
 
+	var weapons []WeaponSpec
 	for _, tok := range tokens {
 		switch {
+		case strings.HasPrefix(tok, "r="):
+			refines, err := parseRefineRange(tok[2:])
+			if err != nil {
+				return CharEntry{}, fmt.Errorf("char %s: invalid refine token %q: %w", name, tok, err)
+			}
+			weapons = append(weapons, WeaponSpec{Refines: refines})
+		case strings.HasPrefix(tok, "w="):
+			key, rng, hasRange := strings.Cut(tok[2:], ":")
+			if key == "" {
+				return CharEntry{}, fmt.Errorf("char %s: invalid weapon token %q (expected w=KEY or w=KEY:A-B)", name, tok)
+			}
+			refines := []int{1}
+			if hasRange {
+				var err error
+				if refines, err = parseRefineRange(rng); err != nil {
+					return CharEntry{}, fmt.Errorf("char %s: invalid weapon token %q: %w", name, tok, err)
+				}
+			}
+			weapons = append(weapons, WeaponSpec{Name: key, Refines: refines})
 		case strings.HasPrefix(tok, "+"):
 			n, err := strconv.Atoi(tok[1:])
 			if err != nil || n < 0 || n > 6 {
@@ -105,7 +136,30 @@ func ParseCharEntry(entry string, baselineCons int) (CharEntry, error) {
 	}
 	sort.Ints(sortedLevels)
 
-	return CharEntry{Name: name, AllowedLevels: sortedLevels}, nil
+	return CharEntry{Name: name, AllowedLevels: sortedLevels, Weapons: weapons}, nil
+}
+
+// parseRefineRange parses "N" or "A-B" (1 <= A <= B <= 5) into a sorted list of refines.
+func parseRefineRange(s string) ([]int, error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	from, err := strconv.Atoi(lo)
+	if err != nil {
+		return nil, fmt.Errorf("expected N or A-B with refines 1..5")
+	}
+	to := from
+	if isRange {
+		if to, err = strconv.Atoi(hi); err != nil {
+			return nil, fmt.Errorf("expected N or A-B with refines 1..5")
+		}
+	}
+	if from < 1 || to > 5 || from > to {
+		return nil, fmt.Errorf("refine range %d-%d out of [1..5]", from, to)
+	}
+	refines := make([]int, 0, to-from+1)
+	for r := from; r <= to; r++ {
+		refines = append(refines, r)
+	}
+	return refines, nil
 }
 
 // ExtractCharName returns the character key from a chars entry string (the first whitespace-separated token).
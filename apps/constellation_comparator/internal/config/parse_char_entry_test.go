@@ -150,3 +150,33 @@ func intsEqual(a, b []int) bool {
 	}
 	return true
 }
+
+func TestParseCharEntry_Weapons(t *testing.T) {
+	e, err := config.ParseCharEntry("arlecchino 0 1 r=1-3 w=crimsonmoonssemblance w=whiteblind:5", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !intsEqual(e.AllowedLevels, []int{0, 1}) {
+		t.Errorf("expected levels [0 1], got %v", e.AllowedLevels)
+	}
+	if len(e.Weapons) != 3 {
+		t.Fatalf("expected 3 weapon specs, got %+v", e.Weapons)
+	}
+	if e.Weapons[0].Name != "" || !intsEqual(e.Weapons[0].Refines, []int{1, 2, 3}) {
+		t.Errorf("unexpected equipped spec %+v", e.Weapons[0])
+	}
+	if e.Weapons[1].Name != "crimsonmoonssemblance" || !intsEqual(e.Weapons[1].Refines, []int{1}) {
+		t.Errorf("unexpected alternate spec %+v", e.Weapons[1])
+	}
+	if e.Weapons[2].Name != "whiteblind" || !intsEqual(e.Weapons[2].Refines, []int{5}) {
+		t.Errorf("unexpected alternate spec %+v", e.Weapons[2])
+	}
+}
+
+func TestParseCharEntry_BadRefine_Error(t *testing.T) {
+	for _, entry := range []string{"fischl r=0-2", "fischl r=3-1", "fischl w=:1", "fischl w=deathmatch:6"} {
+		if _, err := config.ParseCharEntry(entry, 0); err == nil {
+			t.Errorf("ParseCharEntry(%q): expected error", entry)
+		}
+	}
+}
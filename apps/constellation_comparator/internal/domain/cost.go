@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default expected pull costs (approximate, including 50/50 and Epitomized Path losses).
+const (
+	DefaultConsPulls        = 94.0
+	DefaultRefinePulls      = 80.0
+	DefaultPrimogemsPerPull = 160.0
+)
+
+// ConsCostPresets are named per-constellation pull costs for char_cons_pulls.
+var ConsCostPresets = map[string]float64{
+	"5star_limited": DefaultConsPulls, // featured 5★ with 50/50
+	"4star_banner":  45,               // one of three featured 4★
+	"free":          0,                // event/shop constellations
+}
+
+// PullCost is a number of pulls given either as a number or as a ConsCostPresets name.
+type PullCost float64
+
+func (p *PullCost) UnmarshalYAML(value *yaml.Node) error {
+	if v, err := strconv.ParseFloat(strings.TrimSpace(value.Value), 64); err == nil {
+		*p = PullCost(v)
+		return nil
+	}
+	preset := strings.TrimSpace(value.Value)
+	v, ok := ConsCostPresets[preset]
+	if !ok {
+		names := make([]string, 0, len(ConsCostPresets))
+		for name := range ConsCostPresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("line %d: unknown cost preset %q (expected a number or one of %s)", value.Line, preset, strings.Join(names, ", "))
+	}
+	*p = PullCost(v)
+	return nil
+}
+
+// CostConfig is the yaml cost model. Unset values fall back to the defaults above.
+type CostConfig struct {
+	// ConsPulls is the expected number of pulls per constellation.
+	ConsPulls *float64 `yaml:"cons_pulls"`
+	// CharConsPulls overrides ConsPulls per character (number or preset, e.g. 4star_banner).
+	CharConsPulls map[string]PullCost `yaml:"char_cons_pulls"`
+	// RefinePulls is the expected number of pulls per weapon copy.
+	RefinePulls *float64 `yaml:"refine_pulls"`
+	// WeaponPulls overrides RefinePulls per weapon key (e.g. 0 for craftable or owned copies).
+	WeaponPulls map[string]float64 `yaml:"weapon_pulls"`
+	// PrimogemsPerPull converts pulls to primogems.
+	PrimogemsPerPull *float64 `yaml:"primogems_per_pull"`
+}
+
+// CostModel computes the expected pull cost of a combination.
+type CostModel struct {
+	ConsPulls        float64
+	CharConsPulls    map[string]float64
+	RefinePulls      float64
+	WeaponPulls      map[string]float64
+	PrimogemsPerPull float64
+	// BaseCons is the baseline constellation per character; missing characters count from C0.
+	BaseCons map[string]int
+}
+
+// NewCostModel applies defaults to the yaml cost config.
+// baseCons is the baseline constellation per tracked character.
+func NewCostModel(cfg CostConfig, baseCons map[string]int) CostModel {
+	m := CostModel{
+		ConsPulls:        DefaultConsPulls,
+		CharConsPulls:    make(map[string]float64, len(cfg.CharConsPulls)),
+		RefinePulls:      DefaultRefinePulls,
+		WeaponPulls:      cfg.WeaponPulls,
+		PrimogemsPerPull: DefaultPrimogemsPerPull,
+		BaseCons:         baseCons,
+	}
+	for ch, v := range cfg.CharConsPulls {
+		m.CharConsPulls[ch] = float64(v)
+	}
+	if cfg.ConsPulls != nil {
+		m.ConsPulls = *cfg.ConsPulls
+	}
+	if cfg.RefinePulls != nil {
+		m.RefinePulls = *cfg.RefinePulls
+	}
+	if cfg.PrimogemsPerPull != nil {
+		m.PrimogemsPerPull = *cfg.PrimogemsPerPull
+	}
+	return m
+}
+
+// Pulls returns the expected number of pulls to go from the baseline to the combination.
+func (m CostModel) Pulls(c Combination) float64 {
+	pulls := 0.0
+	for ch, cons := range c.ConsByChar {
+		perCons := m.ConsPulls
+		if v, ok := m.CharConsPulls[ch]; ok {
+			perCons = v
+		}
+		if extra := cons - m.BaseCons[ch]; extra > 0 {
+			pulls += float64(extra) * perCons
+		}
+	}
+	for _, w := range c.WeaponByChar {
+		perCopy := m.RefinePulls
+		if v, ok := m.WeaponPulls[w.Name]; ok {
+			perCopy = v
+		}
+		pulls += float64(w.Copies) * perCopy
+	}
+	return pulls
+}
+
+// Primogems converts pulls to primogems.
+func (m CostModel) Primogems(pulls float64) float64 {
+	return pulls * m.PrimogemsPerPull
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "sort"
+
+// FrontierPoint is a combination on the cost-vs-team-DPS Pareto frontier.
+type FrontierPoint struct {
+	Result RunResult
+	Pulls  float64
+}
+
+// ParetoFrontier returns the combinations that no other combination beats on both cost and
+// team DPS, ordered by cost. Failed runs (TeamDps <= 0) are ignored. Among combinations with
+// equal cost only the one with the highest team DPS can be on the frontier.
+func ParetoFrontier(results []RunResult, cost CostModel) []FrontierPoint {
+	points := make([]FrontierPoint, 0, len(results))
+	for _, r := range results {
+		if r.TeamDps <= 0 {
+			continue
+		}
+		points = append(points, FrontierPoint{Result: r, Pulls: cost.Pulls(r.Combination)})
+	}
+	return FrontierOf(points)
+}
+
+// FrontierOf returns the points no other point beats on both pulls and team DPS, ordered by
+// pulls. Points may come from different cost models (e.g. one per team composition).
+func FrontierOf(points []FrontierPoint) []FrontierPoint {
+	points = append([]FrontierPoint(nil), points...)
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].Pulls != points[j].Pulls {
+			return points[i].Pulls < points[j].Pulls
+		}
+		if points[i].Result.TeamDps != points[j].Result.TeamDps {
+			return points[i].Result.TeamDps > points[j].Result.TeamDps
+		}
+		return points[i].Result.Combination.Key() < points[j].Result.Combination.Key()
+	})
+
+	frontier := make([]FrontierPoint, 0)
+	best := 0
+	for _, p := range points {
+		if p.Result.TeamDps > best {
+			frontier = append(frontier, p)
+			best = p.Result.TeamDps
+		}
+	}
+	return frontier
+}
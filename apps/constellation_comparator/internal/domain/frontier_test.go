@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+func result(cons map[string]int, dps int) domain.RunResult {
+	return domain.RunResult{Combination: domain.Combination{ConsByChar: cons}, TeamDps: dps}
+}
+
+func TestParetoFrontier(t *testing.T) {
+	var cfg domain.CostConfig
+	if err := yaml.Unmarshal([]byte("cons_pulls: 100\nchar_cons_pulls:\n  chevreuse: 4star_banner\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	cost := domain.NewCostModel(cfg, map[string]int{"arlecchino": 0, "chevreuse": 0})
+
+	results := []domain.RunResult{
+		result(map[string]int{"arlecchino": 0, "chevreuse": 0}, 1000), // 0 pulls
+		result(map[string]int{"arlecchino": 0, "chevreuse": 1}, 1050), // 45 pulls
+		result(map[string]int{"arlecchino": 1, "chevreuse": 0}, 1040), // 100 pulls, dominated by chevreuse C1
+		result(map[string]int{"arlecchino": 0, "chevreuse": 2}, 1150), // 90 pulls
+		result(map[string]int{"arlecchino": 1, "chevreuse": 1}, 1200), // 145 pulls
+		result(map[string]int{"arlecchino": 2, "chevreuse": 0}, 0),    // failed run
+	}
+	frontier := domain.ParetoFrontier(results, cost)
+
+	wantPulls := []float64{0, 45, 90, 145}
+	if len(frontier) != len(wantPulls) {
+		t.Fatalf("expected %d frontier points, got %d: %+v", len(wantPulls), len(frontier), frontier)
+	}
+	for i, want := range wantPulls {
+		if frontier[i].Pulls != want {
+			t.Errorf("point %d: pulls = %v, want %v (%s)", i, frontier[i].Pulls, want, frontier[i].Result.Combination.Key())
+		}
+	}
+}
+
+func TestPullCost_UnknownPreset(t *testing.T) {
+	var cfg domain.CostConfig
+	if err := yaml.Unmarshal([]byte("char_cons_pulls:\n  chevreuse: 3star\n"), &cfg); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
@@ -19,17 +19,69 @@ type Config struct {
 
 	IgnoreExistingResults bool   `yaml:"ignore_existing_results"`
 	ImportPath            string `yaml:"import_path"`
+
+	// Cost configures the expected pull cost of constellations and weapon copies.
+	Cost CostConfig `yaml:"cost"`
+
+	// Search selects how combinations are explored; default is the exhaustive grid.
+	Search SearchConfig `yaml:"search"`
+
+	// Compositions are alternative teams compared in one run; empty means config.txt as is.
+	Compositions []Composition `yaml:"compositions"`
+}
+
+// Composition is an alternative team built from config.txt.
+type Composition struct {
+	Name string `yaml:"name"`
+	// Replace is the config.txt character swapped out for Block's character (empty: Block is added).
+	Replace string `yaml:"replace"`
+	// Block holds the "<char> char ..." / "<char> add ..." lines of the new character (empty: config.txt team).
+	Block string `yaml:"block"`
+	// Chars are tracked in this composition only, in addition to the top-level chars.
+	Chars []string `yaml:"chars"`
+}
+
+// SearchConfig selects the exploration strategy.
+type SearchConfig struct {
+	// Strategy is exhaustive (default), greedy or beam.
+	Strategy string `yaml:"strategy"`
+	// BeamWidth is the number of combinations kept per step in beam mode (default 3).
+	BeamWidth int `yaml:"beam_width"`
 }
 
-// Combination holds a concrete set of constellation levels for the tracked characters.
+// Weapon is a concrete weapon choice of a tracked character.
+type Weapon struct {
+	Name   string
+	Refine int
+	// Copies is the number of weapon copies needed on top of the baseline weapon
+	// (extra refines of the equipped weapon, or all refines of an alternate weapon).
+	Copies int
+}
+
+// Label returns a short human-readable label, e.g. "deathmatch R2".
+func (w Weapon) Label() string {
+	return fmt.Sprintf("%s R%d", w.Name, w.Refine)
+}
+
+// Combination holds a concrete set of constellation levels (and optionally weapons) for the tracked characters.
 type Combination struct {
 	// ConsByChar maps character name to their constellation level (0–6).
 	ConsByChar      map[string]int
 	TotalAdditional int
+	// WeaponByChar holds the weapon of characters whose weapon is varied; other characters keep config.txt's weapon.
+	WeaponByChar map[string]Weapon
+	// TotalCopies is the total number of extra weapon copies over the baseline weapons.
+	TotalCopies int
+}
+
+// IsBaseline reports whether the combination adds neither constellations nor weapon copies.
+func (c Combination) IsBaseline() bool {
+	return c.TotalAdditional == 0 && c.TotalCopies == 0
 }
 
 // Key returns a stable, unique string key for this combination based on ConsByChar.
-// Chars are sorted alphabetically so the key is insertion-order independent.
+// Chars are keyed by name (not by team slot) and sorted alphabetically, so the key is
+// insertion-order independent and a character keeps its identity across compositions.
 func (c Combination) Key() string {
 	chars := make([]string, 0, len(c.ConsByChar))
 	for k := range c.ConsByChar {
@@ -38,7 +90,11 @@ func (c Combination) Key() string {
 	sort.Strings(chars)
 	parts := make([]string, 0, len(chars))
 	for _, ch := range chars {
-		parts = append(parts, fmt.Sprintf("%s=%d", ch, c.ConsByChar[ch]))
+		part := fmt.Sprintf("%s=%d", ch, c.ConsByChar[ch])
+		if w, ok := c.WeaponByChar[ch]; ok {
+			part += fmt.Sprintf("/%s:r%d", w.Name, w.Refine)
+		}
+		parts = append(parts, part)
 	}
 	return strings.Join(parts, ",")
 }
@@ -47,5 +103,7 @@ func (c Combination) Key() string {
 type RunResult struct {
 	Combination Combination
 	TeamDps     int
-	ConfigFile  string
+	// CharDps maps every team member (not only the tracked chars) to their DPS.
+	CharDps    map[string]int
+	ConfigFile string
 }
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+// CompositionReport is the report of one team composition of a multi-composition run.
+type CompositionReport struct {
+	Name   string
+	Report Report
+}
+
+type compositionPoint struct {
+	Composition int
+	Point       domain.FrontierPoint
+	Overall     bool
+}
+
+// buildCompositionPoints collects the cost-vs-DPS frontier of every composition and marks the
+// points that are also on the frontier across all compositions. Points are grouped by composition.
+func buildCompositionPoints(comps []CompositionReport) []compositionPoint {
+	var points []compositionPoint
+	var all []domain.FrontierPoint
+	for i, c := range comps {
+		for _, p := range domain.ParetoFrontier(c.Report.Results, c.Report.Cost) {
+			points = append(points, compositionPoint{Composition: i, Point: p})
+			all = append(all, p)
+		}
+	}
+	// Identify points by outcome as well: compositions tracking the same characters share keys.
+	id := func(p domain.FrontierPoint) string {
+		return fmt.Sprintf("%s|%g|%d", p.Result.Combination.Key(), p.Pulls, p.Result.TeamDps)
+	}
+	overall := make(map[string]struct{})
+	for _, p := range domain.FrontierOf(all) {
+		overall[id(p)] = struct{}{}
+	}
+	for i := range points {
+		_, points[i].Overall = overall[id(points[i].Point)]
+	}
+	return points
+}
+
+// investmentLabel lists the tracked characters of a combination, e.g. "kazuha C2, arlecchino C0 deathmatch R2".
+func investmentLabel(chars []string, c domain.Combination) string {
+	parts := make([]string, 0, len(chars))
+	for _, ch := range chars {
+		parts = append(parts, ch+" "+charCellLabel(c, ch))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExportCompositionsXLSX writes the comparison of team compositions to today's
+// "<name>_compositions" output file.
+//
+// Sheet "Compositions": # | Состав | Крутки | Примогемы | Team DPS | Общий фронт | Инвестиции | Sim Config
+// Every composition contributes its own Pareto frontier; "Общий фронт" marks the points that stay
+// on the frontier when all compositions are put together. A scatter chart has one line per composition.
+func ExportCompositionsXLSX(appRoot string, name string, comps []CompositionReport) (string, error) {
+	outPath, err := resolveOutPath(appRoot, name+"_compositions", "")
+	if err != nil {
+		return "", err
+	}
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	const sheet = "Compositions"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return "", err
+	}
+	headerStyle, boldStyle, configStyle, err := commonStyles(f)
+	if err != nil {
+		return "", err
+	}
+	cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
+
+	headers := []string{"#", "Состав", "Крутки", "Примогемы", "Team DPS", "Общий фронт", "Инвестиции", "Sim Config"}
+	for i, h := range headers {
+		f.SetCellStr(sheet, cell(i+1, 1), h)
+	}
+	lastCol := len(headers)
+	_ = f.SetCellStyle(sheet, cell(1, 1), cell(lastCol, 1), headerStyle)
+
+	points := buildCompositionPoints(comps)
+	firstRow := make(map[int]int, len(comps))
+	lastRow := make(map[int]int, len(comps))
+	for i, cp := range points {
+		row := i + 2
+		comp := comps[cp.Composition]
+		if _, ok := firstRow[cp.Composition]; !ok {
+			firstRow[cp.Composition] = row
+		}
+		lastRow[cp.Composition] = row
+		f.SetCellInt(sheet, cell(1, row), int64(i+1))
+		f.SetCellStr(sheet, cell(2, row), comp.Name)
+		f.SetCellFloat(sheet, cell(3, row), cp.Point.Pulls, 1, 64)
+		f.SetCellFloat(sheet, cell(4, row), comp.Report.Cost.Primogems(cp.Point.Pulls), 0, 64)
+		f.SetCellInt(sheet, cell(5, row), int64(cp.Point.Result.TeamDps))
+		if cp.Overall {
+			f.SetCellStr(sheet, cell(6, row), "да")
+			_ = f.SetCellStyle(sheet, cell(1, row), cell(lastCol-1, row), boldStyle)
+		}
+		f.SetCellStr(sheet, cell(7, row), investmentLabel(comp.Report.Chars, cp.Point.Result.Combination))
+		f.SetCellStr(sheet, cell(8, row), cp.Point.Result.ConfigFile)
+	}
+	if last := len(points) + 1; last >= 2 {
+		_ = f.SetCellStyle(sheet, cell(lastCol, 2), cell(lastCol, last), configStyle)
+	}
+
+	_ = f.SetColWidth(sheet, colName(1), colName(1), 6)
+	_ = f.SetColWidth(sheet, colName(2), colName(2), 18)
+	_ = f.SetColWidth(sheet, colName(3), colName(6), 14)
+	_ = f.SetColWidth(sheet, colName(7), colName(7), 50)
+	_ = f.SetColWidth(sheet, colName(lastCol), colName(lastCol), 90)
+
+	series := make([]excelize.ChartSeries, 0, len(comps))
+	for i, comp := range comps {
+		first, ok := firstRow[i]
+		if !ok {
+			continue
+		}
+		series = append(series, excelize.ChartSeries{
+			Name:       comp.Name,
+			Categories: fmt.Sprintf("%s!$C$%d:$C$%d", sheet, first, lastRow[i]),
+			Values:     fmt.Sprintf("%s!$E$%d:$E$%d", sheet, first, lastRow[i]),
+			Line:       excelize.ChartLine{Type: excelize.ChartLineSolid, Width: 2},
+			Marker:     excelize.ChartMarker{Symbol: "diamond", Size: 7},
+		})
+	}
+	if len(series) > 0 {
+		if err := f.AddChart(sheet, cell(lastCol+2, 1), &excelize.Chart{
+			Type:   excelize.Scatter,
+			Series: series,
+			Format: excelize.GraphicOptions{ScaleX: 2, ScaleY: 2},
+			Title:  []excelize.RichTextRun{{Text: "Team DPS vs крутки по составам"}},
+			Legend: excelize.ChartLegend{Position: "bottom"},
+			XAxis:  excelize.ChartAxis{MajorGridLines: true, Title: []excelize.RichTextRun{{Text: "Крутки"}}},
+			YAxis:  excelize.ChartAxis{MajorGridLines: true, Title: []excelize.RichTextRun{{Text: "Team DPS"}}},
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	if idx, _ := f.GetSheetIndex("Sheet1"); idx != -1 {
+		f.DeleteSheet("Sheet1")
+	}
+	if err := f.SaveAs(outPath); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
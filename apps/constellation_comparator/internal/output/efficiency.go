@@ -0,0 +1,100 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+// efficiencyRow is one combination ranked by team DPS gain per expected pull.
+type efficiencyRow struct {
+	Result domain.RunResult
+	Gain   int
+	Pulls  float64
+}
+
+// buildEfficiencyRows ranks all non-baseline combinations with a successful run by gain per pull.
+// Free combinations (0 pulls) come first, ordered by gain.
+func buildEfficiencyRows(results []domain.RunResult, baseline domain.RunResult, cost domain.CostModel) []efficiencyRow {
+	rows := make([]efficiencyRow, 0, len(results))
+	for _, r := range results {
+		if r.Combination.IsBaseline() || r.TeamDps <= 0 {
+			continue
+		}
+		rows = append(rows, efficiencyRow{
+			Result: r,
+			Gain:   r.TeamDps - baseline.TeamDps,
+			Pulls:  cost.Pulls(r.Combination),
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if (a.Pulls <= 0) != (b.Pulls <= 0) {
+			return a.Pulls <= 0
+		}
+		if a.Pulls <= 0 {
+			return a.Gain > b.Gain
+		}
+		ra, rb := float64(a.Gain)/a.Pulls, float64(b.Gain)/b.Pulls
+		if ra != rb {
+			return ra > rb
+		}
+		return a.Pulls < b.Pulls
+	})
+	return rows
+}
+
+const efficiencySheet = "Efficiency"
+
+// buildEfficiencySheet writes the "Efficiency" sheet and returns the number of data rows:
+// # | Team DPS | Прирост | Team % | Крутки | Примогемы | DPS / крутка | char1..charN | Sim Config
+func buildEfficiencySheet(f *excelize.File, chars []string, results []domain.RunResult, baseline domain.RunResult, cost domain.CostModel) (int, error) {
+	const sheet = efficiencySheet
+	if _, err := f.NewSheet(sheet); err != nil {
+		return 0, err
+	}
+	headerStyle, _, configStyle, err := commonStyles(f)
+	if err != nil {
+		return 0, err
+	}
+	cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
+
+	headers := append([]string{"#", "Team DPS", "Прирост", "Team %", "Крутки", "Примогемы", "DPS / крутка"}, chars...)
+	headers = append(headers, "Sim Config")
+	for i, h := range headers {
+		f.SetCellStr(sheet, cell(i+1, 1), h)
+	}
+	lastCol := len(headers)
+	_ = f.SetCellStyle(sheet, cell(1, 1), cell(lastCol, 1), headerStyle)
+
+	rows := buildEfficiencyRows(results, baseline, cost)
+	for i, e := range rows {
+		row := i + 2
+		f.SetCellInt(sheet, cell(1, row), int64(i+1))
+		f.SetCellInt(sheet, cell(2, row), int64(e.Result.TeamDps))
+		f.SetCellInt(sheet, cell(3, row), int64(e.Gain))
+		f.SetCellStr(sheet, cell(4, row), pctLabel(e.Result.TeamDps, baseline.TeamDps, false))
+		f.SetCellFloat(sheet, cell(5, row), e.Pulls, 1, 64)
+		f.SetCellFloat(sheet, cell(6, row), cost.Primogems(e.Pulls), 0, 64)
+		if e.Pulls > 0 {
+			f.SetCellFloat(sheet, cell(7, row), float64(e.Gain)/e.Pulls, 2, 64)
+		}
+		for j, ch := range chars {
+			f.SetCellStr(sheet, cell(8+j, row), charCellLabel(e.Result.Combination, ch))
+		}
+		f.SetCellStr(sheet, cell(lastCol, row), e.Result.ConfigFile)
+	}
+	if last := len(rows) + 1; last >= 2 {
+		_ = f.SetCellStyle(sheet, cell(lastCol, 2), cell(lastCol, last), configStyle)
+	}
+
+	_ = f.SetColWidth(sheet, colName(1), colName(1), 6)
+	_ = f.SetColWidth(sheet, colName(2), colName(7), 14)
+	if len(chars) > 0 {
+		_ = f.SetColWidth(sheet, colName(8), colName(7+len(chars)), 22)
+	}
+	_ = f.SetColWidth(sheet, colName(lastCol), colName(lastCol), 90)
+	return len(rows), nil
+}
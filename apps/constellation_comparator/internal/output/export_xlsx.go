@@ -1,244 +1,375 @@
 package output
 
 import (
-"fmt"
-"os"
-"path/filepath"
-"sort"
-"strings"
-"time"
-
-"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
-"github.com/xuri/excelize/v2"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/xuri/excelize/v2"
 )
 
-func ExportXLSX(appRoot string, name string, chars []string, results []domain.RunResult, baselineDps int) (string, error) {
-return ExportXLSXToPath(appRoot, name, chars, results, baselineDps, "")
+// Report is everything exported into one constellation_comparator XLSX file.
+type Report struct {
+	// Chars are the tracked characters whose constellations (and weapons) are varied.
+	Chars []string
+	// Team lists every team member in config order; their DPS is exported next to the tracked chars.
+	Team     []string
+	Results  []domain.RunResult
+	Baseline domain.RunResult
+	Cost     domain.CostModel
+	// UpgradePath is the search path from the baseline to full investment; empty in exhaustive mode.
+	UpgradePath []domain.RunResult
 }
 
-func ExportXLSXToPath(appRoot string, name string, chars []string, results []domain.RunResult, baselineDps int, outPath string) (string, error) {
-outDir := filepath.Join(appRoot, "output", "constellation_comparator")
-if err := os.MkdirAll(outDir, 0o755); err != nil {
-return "", fmt.Errorf("create output dir: %w", err)
-}
-if outPath == "" {
-fileBase := fmt.Sprintf("%s_constellation_comparator_%s.xlsx",
-time.Now().Format("20060102"),
-sanitizeFilenamePart(name),
-)
-outPath = filepath.Join(outDir, fileBase)
+// ExportXLSX writes the report to today's default output file.
+func ExportXLSX(appRoot string, name string, report Report) (string, error) {
+	return ExportXLSXToPath(appRoot, name, report, "")
 }
-f := excelize.NewFile()
-defer func() { _ = f.Close() }()
-if err := buildResultsSheet(f, chars, results, baselineDps); err != nil {
-return "", err
-}
-if idx, _ := f.GetSheetIndex("Sheet1"); idx != -1 {
-f.DeleteSheet("Sheet1")
+
+func ExportXLSXToPath(appRoot string, name string, report Report, outPath string) (string, error) {
+	outPath, err := resolveOutPath(appRoot, name, outPath)
+	if err != nil {
+		return "", err
+	}
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	if err := buildResultsSheet(f, report.Chars, report.Team, report.Results, report.Baseline); err != nil {
+		return "", err
+	}
+	effRows, err := buildEfficiencySheet(f, report.Chars, report.Results, report.Baseline, report.Cost)
+	if err != nil {
+		return "", err
+	}
+	if err := buildFrontierSheet(f, report.Chars, report.Results, report.Baseline, report.Cost, effRows); err != nil {
+		return "", err
+	}
+	if len(report.UpgradePath) > 0 {
+		if err := buildPathSheet(f, report.Chars, report.UpgradePath, report.Baseline, report.Cost); err != nil {
+			return "", err
+		}
+	}
+	if idx, _ := f.GetSheetIndex("Sheet1"); idx != -1 {
+		f.DeleteSheet("Sheet1")
+	}
+	if err := f.SaveAs(outPath); err != nil {
+		return "", err
+	}
+	return outPath, nil
 }
-if err := f.SaveAs(outPath); err != nil {
-return "", err
+
+// resolveOutPath returns outPath, or today's default output file for name when outPath is empty.
+func resolveOutPath(appRoot string, name string, outPath string) (string, error) {
+	outDir := filepath.Join(appRoot, "output", "constellation_comparator")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return "", fmt.Errorf("create output dir: %w", err)
+	}
+	if outPath != "" {
+		return outPath, nil
+	}
+	fileBase := fmt.Sprintf("%s_constellation_comparator_%s.xlsx",
+		time.Now().Format("20060102"),
+		sanitizeFilenamePart(name),
+	)
+	return filepath.Join(outDir, fileBase), nil
 }
-return outPath, nil
+
+// resultGroup is a row group of the Results sheet: additional constellations plus weapon copies,
+// so that weapon variants are not compared against constellation-only rows of the same level.
+type resultGroup struct {
+	cons   int
+	copies int
 }
 
-func buildSummaryRows(results []domain.RunResult) ([]domain.RunResult, map[int]domain.RunResult) {
-bestByLevel := make(map[int]domain.RunResult)
-for _, r := range results {
-if prev, ok := bestByLevel[r.Combination.TotalAdditional]; !ok || r.TeamDps > prev.TeamDps {
-bestByLevel[r.Combination.TotalAdditional] = r
+func groupOf(c domain.Combination) resultGroup {
+	return resultGroup{cons: c.TotalAdditional, copies: c.TotalCopies}
 }
+
+func (g resultGroup) less(o resultGroup) bool {
+	if g.cons != o.cons {
+		return g.cons < o.cons
+	}
+	return g.copies < o.copies
 }
-levels := make([]int, 0, len(bestByLevel))
-for lvl := range bestByLevel {
-levels = append(levels, lvl)
+
+// label is the group cell when weapons are varied, e.g. "2 / 1" for C+2 and one weapon copy.
+func (g resultGroup) label() string { return fmt.Sprintf("%d / %d", g.cons, g.copies) }
+
+// groupHeader names the group column; it lists weapon copies only when weapons are varied.
+func groupHeader(withWeapons bool) string {
+	if withWeapons {
+		return "Доп. конст / копии"
+	}
+	return "Доп. конст"
 }
-sort.Ints(levels)
-rows := make([]domain.RunResult, 0, len(levels))
-for _, lvl := range levels {
-rows = append(rows, bestByLevel[lvl])
+
+// hasWeapons reports whether any result varies a weapon.
+func hasWeapons(results []domain.RunResult) bool {
+	for _, r := range results {
+		if len(r.Combination.WeaponByChar) > 0 {
+			return true
+		}
+	}
+	return false
 }
-return rows, bestByLevel
+
+func buildSummaryRows(results []domain.RunResult) ([]domain.RunResult, map[resultGroup]domain.RunResult) {
+	bestByGroup := make(map[resultGroup]domain.RunResult)
+	for _, r := range results {
+		g := groupOf(r.Combination)
+		if prev, ok := bestByGroup[g]; !ok || r.TeamDps > prev.TeamDps {
+			bestByGroup[g] = r
+		}
+	}
+	groups := make([]resultGroup, 0, len(bestByGroup))
+	for g := range bestByGroup {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].less(groups[j]) })
+	rows := make([]domain.RunResult, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, bestByGroup[g])
+	}
+	return rows, bestByGroup
 }
 
 func buildFullRows(results []domain.RunResult) []domain.RunResult {
-sorted := make([]domain.RunResult, len(results))
-copy(sorted, results)
-sort.SliceStable(sorted, func(i, j int) bool {
-if sorted[i].Combination.TotalAdditional != sorted[j].Combination.TotalAdditional {
-return sorted[i].Combination.TotalAdditional < sorted[j].Combination.TotalAdditional
-}
-return sorted[i].TeamDps > sorted[j].TeamDps
-})
-return sorted
+	sorted := make([]domain.RunResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		gi, gj := groupOf(sorted[i].Combination), groupOf(sorted[j].Combination)
+		if gi != gj {
+			return gi.less(gj)
+		}
+		return sorted[i].TeamDps > sorted[j].TeamDps
+	})
+	return sorted
 }
 
 func pctLabel(value int, baseline int, isBaseline bool) string {
-if isBaseline {
-return "100%"
-}
-if baseline <= 0 {
-return ""
-}
-return fmt.Sprintf("%.1f%%", float64(value)/float64(baseline)*100.0)
+	if isBaseline {
+		return "100%"
+	}
+	if baseline <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", float64(value)/float64(baseline)*100.0)
 }
 
 func consLabel(level int) string { return fmt.Sprintf("C%d", level) }
 
-func colName(n int) string {
-result := ""
-for n > 0 {
-n--
-result = string(rune('A'+n%26)) + result
-n /= 26
+// charCellLabel is the per-character cell of a combination: "C2", or "C2 deathmatch R3"
+// when the character's weapon is varied. ImportResultsXLSX parses both forms.
+func charCellLabel(c domain.Combination, ch string) string {
+	label := consLabel(c.ConsByChar[ch])
+	if w, ok := c.WeaponByChar[ch]; ok {
+		label += " " + w.Label()
+	}
+	return label
 }
-return result
+
+func colName(n int) string {
+	result := ""
+	for n > 0 {
+		n--
+		result = string(rune('A'+n%26)) + result
+		n /= 26
+	}
+	return result
 }
 
 func sanitizeFilenamePart(s string) string {
-s = strings.TrimSpace(s)
-if s == "" {
-return "_"
-}
-repl := strings.NewReplacer("<","_",">","_",":","_","\"","_","/","_","\\","_","|","_","?","_","*","_")
-s = repl.Replace(s)
-s = strings.ReplaceAll(s, " ", "_")
-for strings.Contains(s, "__") {
-s = strings.ReplaceAll(s, "__", "_")
-}
-return s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "_"
+	}
+	repl := strings.NewReplacer("<", "_", ">", "_", ":", "_", "\"", "_", "/", "_", "\\", "_", "|", "_", "?", "_", "*", "_")
+	s = repl.Replace(s)
+	s = strings.ReplaceAll(s, " ", "_")
+	for strings.Contains(s, "__") {
+		s = strings.ReplaceAll(s, "__", "_")
+	}
+	return s
 }
 
 func commonStyles(f *excelize.File) (int, int, int, error) {
-headerStyle, err := f.NewStyle(&excelize.Style{
-Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-Font:      &excelize.Font{Bold: true},
-})
-if err != nil {
-return 0, 0, 0, err
-}
-boldStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
-if err != nil {
-return 0, 0, 0, err
-}
-configStyle, err := f.NewStyle(&excelize.Style{
-Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
-})
-if err != nil {
-return 0, 0, 0, err
-}
-return headerStyle, boldStyle, configStyle, nil
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Font:      &excelize.Font{Bold: true},
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	boldStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	configStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return headerStyle, boldStyle, configStyle, nil
 }
 
+// charDpsHeader and charPctHeader name the per-character DPS columns of a team member.
+func charDpsHeader(ch string) string { return ch + " DPS" }
+func charPctHeader(ch string) string { return ch + " %" }
+
 // buildResultsSheet writes both tables and config columns onto a single sheet.
 //
-// Layout (1-based cols, N = len(chars)):
-//   [1..3+N]    Summary: Доп. конст | Team DPS | Team % | char1..charN
-//   [4+N]       gap
-//   [5+N..8+2N] Full:    Доп. конст | Team DPS | Team % | Best % | char1..charN
-//   [9+2N]      gap
-//   [10+2N]     Sim Config (Summary)
-//   [11+2N]     Sim Config (Full)  <- adjacent, no gap
-func buildResultsSheet(f *excelize.File, chars []string, results []domain.RunResult, baselineDps int) error {
-const sheet = "Results"
-if _, err := f.NewSheet(sheet); err != nil {
-return err
-}
-headerStyle, boldStyle, configStyle, err := commonStyles(f)
-if err != nil {
-return err
-}
-summaryRows, bestByLevel := buildSummaryRows(results)
-fullRows := buildFullRows(results)
-n := len(chars)
-
-sumStart  := 1
-sumEnd    := 3 + n
-fullStart := sumEnd + 2
-fullEnd   := fullStart + 3 + n
-cfgSumCol := fullEnd + 2
-cfgFullCol := fullEnd + 3
-
-cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
-
-// Headers
-for i, h := range append([]string{"Доп. конст", "Team DPS", "Team %"}, chars...) {
-f.SetCellStr(sheet, cell(sumStart+i, 1), h)
-}
-_ = f.SetCellStyle(sheet, cell(sumStart, 1), cell(sumEnd, 1), headerStyle)
-for i, h := range append([]string{"Доп. конст", "Team DPS", "Team %", "Best %"}, chars...) {
-f.SetCellStr(sheet, cell(fullStart+i, 1), h)
-}
-_ = f.SetCellStyle(sheet, cell(fullStart, 1), cell(fullEnd, 1), headerStyle)
-f.SetCellStr(sheet, cell(cfgSumCol, 1), "Sim Config")
-_ = f.SetCellStyle(sheet, cell(cfgSumCol, 1), cell(cfgSumCol, 1), headerStyle)
-f.SetCellStr(sheet, cell(cfgFullCol, 1), "Sim Config")
-_ = f.SetCellStyle(sheet, cell(cfgFullCol, 1), cell(cfgFullCol, 1), headerStyle)
-
-// Summary rows
-for i, r := range summaryRows {
-row := i + 2
-lvl := r.Combination.TotalAdditional
-f.SetCellInt(sheet, cell(sumStart, row), int64(lvl))
-f.SetCellInt(sheet, cell(sumStart+1, row), int64(r.TeamDps))
-f.SetCellStr(sheet, cell(sumStart+2, row), pctLabel(r.TeamDps, baselineDps, lvl == 0))
-for j, ch := range chars {
-f.SetCellStr(sheet, cell(sumStart+3+j, row), consLabel(r.Combination.ConsByChar[ch]))
-}
-f.SetCellStr(sheet, cell(cfgSumCol, row), r.ConfigFile)
-if lvl == 0 {
-_ = f.SetCellStyle(sheet, cell(sumStart, row), cell(sumEnd, row), boldStyle)
-}
-}
-
-// Full rows
-for i, r := range fullRows {
-row := i + 2
-lvl := r.Combination.TotalAdditional
-bestPctStr := ""
-if best, ok := bestByLevel[lvl]; ok && best.TeamDps > 0 {
-if r.TeamDps == best.TeamDps {
-bestPctStr = "100%"
-} else {
-bestPctStr = fmt.Sprintf("%.1f%%", float64(r.TeamDps)/float64(best.TeamDps)*100.0)
-}
-}
-f.SetCellInt(sheet, cell(fullStart, row), int64(lvl))
-f.SetCellInt(sheet, cell(fullStart+1, row), int64(r.TeamDps))
-f.SetCellStr(sheet, cell(fullStart+2, row), pctLabel(r.TeamDps, baselineDps, lvl == 0))
-f.SetCellStr(sheet, cell(fullStart+3, row), bestPctStr)
-for j, ch := range chars {
-f.SetCellStr(sheet, cell(fullStart+4+j, row), consLabel(r.Combination.ConsByChar[ch]))
-}
-f.SetCellStr(sheet, cell(cfgFullCol, row), r.ConfigFile)
-if lvl == 0 {
-_ = f.SetCellStyle(sheet, cell(fullStart, row), cell(fullEnd, row), boldStyle)
-}
-}
-
-// Config styles
-if last := len(summaryRows) + 1; last >= 2 {
-_ = f.SetCellStyle(sheet, cell(cfgSumCol, 2), cell(cfgSumCol, last), configStyle)
-}
-if last := len(fullRows) + 1; last >= 2 {
-_ = f.SetCellStyle(sheet, cell(cfgFullCol, 2), cell(cfgFullCol, last), configStyle)
-}
-
-// Column widths
-_ = f.SetColWidth(sheet, colName(sumStart),   colName(sumStart),   14)
-_ = f.SetColWidth(sheet, colName(sumStart+1), colName(sumStart+1), 14)
-_ = f.SetColWidth(sheet, colName(sumStart+2), colName(sumStart+2), 10)
-if n > 0 {
-_ = f.SetColWidth(sheet, colName(sumStart+3), colName(sumEnd), 12)
-}
-_ = f.SetColWidth(sheet, colName(fullStart),   colName(fullStart),   14)
-_ = f.SetColWidth(sheet, colName(fullStart+1), colName(fullStart+1), 14)
-_ = f.SetColWidth(sheet, colName(fullStart+2), colName(fullStart+3), 10)
-if n > 0 {
-_ = f.SetColWidth(sheet, colName(fullStart+4), colName(fullEnd), 12)
-}
-_ = f.SetColWidth(sheet, colName(cfgSumCol),  colName(cfgSumCol),  90)
-_ = f.SetColWidth(sheet, colName(cfgFullCol), colName(cfgFullCol), 90)
-
-return nil
+// Layout (1-based cols, N = len(chars), M = len(team)):
+//
+//	[1..3+N+2M]          Summary: Доп. конст | Team DPS | Team % | char1..charN | member1 DPS | member1 % ...
+//	[4+N+2M]             gap
+//	[5+N+2M..8+2N+4M]    Full:    Доп. конст | Team DPS | Team % | Best % | char1..charN | member1 DPS | member1 % ...
+//	[9+2N+4M]            gap
+//	[10+2N+4M]           Sim Config (Summary)
+//	[11+2N+4M]           Sim Config (Full)  <- adjacent, no gap
+//
+// Rows are grouped by additional constellations and, when weapons are varied, weapon copies
+// ("Доп. конст / копии"); Summary keeps the best row of each group and Best % compares against it.
+// Member % is the member's DPS relative to their DPS in the baseline combination.
+func buildResultsSheet(f *excelize.File, chars []string, team []string, results []domain.RunResult, baseline domain.RunResult) error {
+	const sheet = "Results"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	headerStyle, boldStyle, configStyle, err := commonStyles(f)
+	if err != nil {
+		return err
+	}
+	summaryRows, bestByGroup := buildSummaryRows(results)
+	fullRows := buildFullRows(results)
+	withWeapons := hasWeapons(results)
+	n := len(chars)
+	m := len(team)
+
+	sumStart := 1
+	sumEnd := 3 + n + 2*m
+	fullStart := sumEnd + 2
+	fullEnd := fullStart + 3 + n + 2*m
+	cfgSumCol := fullEnd + 2
+	cfgFullCol := fullEnd + 3
+
+	cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
+
+	memberHeaders := make([]string, 0, 2*m)
+	for _, ch := range team {
+		memberHeaders = append(memberHeaders, charDpsHeader(ch), charPctHeader(ch))
+	}
+	writeMembers := func(startCol, row int, r domain.RunResult, isBaseline bool) {
+		for j, ch := range team {
+			dps, ok := r.CharDps[ch]
+			if !ok {
+				continue
+			}
+			f.SetCellInt(sheet, cell(startCol+2*j, row), int64(dps))
+			f.SetCellStr(sheet, cell(startCol+2*j+1, row), pctLabel(dps, baseline.CharDps[ch], isBaseline))
+		}
+	}
+
+	setGroupCell := func(axis string, g resultGroup) {
+		if withWeapons {
+			f.SetCellStr(sheet, axis, g.label())
+		} else {
+			f.SetCellInt(sheet, axis, int64(g.cons))
+		}
+	}
+
+	// Headers
+	sumHeaders := append(append([]string{groupHeader(withWeapons), "Team DPS", "Team %"}, chars...), memberHeaders...)
+	for i, h := range sumHeaders {
+		f.SetCellStr(sheet, cell(sumStart+i, 1), h)
+	}
+	_ = f.SetCellStyle(sheet, cell(sumStart, 1), cell(sumEnd, 1), headerStyle)
+	fullHeaders := append(append([]string{groupHeader(withWeapons), "Team DPS", "Team %", "Best %"}, chars...), memberHeaders...)
+	for i, h := range fullHeaders {
+		f.SetCellStr(sheet, cell(fullStart+i, 1), h)
+	}
+	_ = f.SetCellStyle(sheet, cell(fullStart, 1), cell(fullEnd, 1), headerStyle)
+	f.SetCellStr(sheet, cell(cfgSumCol, 1), "Sim Config")
+	_ = f.SetCellStyle(sheet, cell(cfgSumCol, 1), cell(cfgSumCol, 1), headerStyle)
+	f.SetCellStr(sheet, cell(cfgFullCol, 1), "Sim Config")
+	_ = f.SetCellStyle(sheet, cell(cfgFullCol, 1), cell(cfgFullCol, 1), headerStyle)
+
+	// Summary rows
+	for i, r := range summaryRows {
+		row := i + 2
+		isBaseline := r.Combination.IsBaseline()
+		setGroupCell(cell(sumStart, row), groupOf(r.Combination))
+		f.SetCellInt(sheet, cell(sumStart+1, row), int64(r.TeamDps))
+		f.SetCellStr(sheet, cell(sumStart+2, row), pctLabel(r.TeamDps, baseline.TeamDps, isBaseline))
+		for j, ch := range chars {
+			f.SetCellStr(sheet, cell(sumStart+3+j, row), charCellLabel(r.Combination, ch))
+		}
+		writeMembers(sumStart+3+n, row, r, isBaseline)
+		f.SetCellStr(sheet, cell(cfgSumCol, row), r.ConfigFile)
+		if isBaseline {
+			_ = f.SetCellStyle(sheet, cell(sumStart, row), cell(sumEnd, row), boldStyle)
+		}
+	}
+
+	// Full rows
+	for i, r := range fullRows {
+		row := i + 2
+		g := groupOf(r.Combination)
+		isBaseline := r.Combination.IsBaseline()
+		bestPctStr := ""
+		if best, ok := bestByGroup[g]; ok && best.TeamDps > 0 {
+			if r.TeamDps == best.TeamDps {
+				bestPctStr = "100%"
+			} else {
+				bestPctStr = fmt.Sprintf("%.1f%%", float64(r.TeamDps)/float64(best.TeamDps)*100.0)
+			}
+		}
+		setGroupCell(cell(fullStart, row), g)
+		f.SetCellInt(sheet, cell(fullStart+1, row), int64(r.TeamDps))
+		f.SetCellStr(sheet, cell(fullStart+2, row), pctLabel(r.TeamDps, baseline.TeamDps, isBaseline))
+		f.SetCellStr(sheet, cell(fullStart+3, row), bestPctStr)
+		for j, ch := range chars {
+			f.SetCellStr(sheet, cell(fullStart+4+j, row), charCellLabel(r.Combination, ch))
+		}
+		writeMembers(fullStart+4+n, row, r, isBaseline)
+		f.SetCellStr(sheet, cell(cfgFullCol, row), r.ConfigFile)
+		if isBaseline {
+			_ = f.SetCellStyle(sheet, cell(fullStart, row), cell(fullEnd, row), boldStyle)
+		}
+	}
+
+	// Config styles
+	if last := len(summaryRows) + 1; last >= 2 {
+		_ = f.SetCellStyle(sheet, cell(cfgSumCol, 2), cell(cfgSumCol, last), configStyle)
+	}
+	if last := len(fullRows) + 1; last >= 2 {
+		_ = f.SetCellStyle(sheet, cell(cfgFullCol, 2), cell(cfgFullCol, last), configStyle)
+	}
+
+	// Column widths
+	_ = f.SetColWidth(sheet, colName(sumStart), colName(sumStart), 14)
+	_ = f.SetColWidth(sheet, colName(sumStart+1), colName(sumStart+1), 14)
+	_ = f.SetColWidth(sheet, colName(sumStart+2), colName(sumStart+2), 10)
+	if n > 0 {
+		_ = f.SetColWidth(sheet, colName(sumStart+3), colName(sumStart+2+n), 12)
+	}
+	if m > 0 {
+		_ = f.SetColWidth(sheet, colName(sumStart+3+n), colName(sumEnd), 14)
+	}
+	_ = f.SetColWidth(sheet, colName(fullStart), colName(fullStart), 14)
+	_ = f.SetColWidth(sheet, colName(fullStart+1), colName(fullStart+1), 14)
+	_ = f.SetColWidth(sheet, colName(fullStart+2), colName(fullStart+3), 10)
+	if n > 0 {
+		_ = f.SetColWidth(sheet, colName(fullStart+4), colName(fullStart+3+n), 12)
+	}
+	if m > 0 {
+		_ = f.SetColWidth(sheet, colName(fullStart+4+n), colName(fullEnd), 14)
+	}
+	_ = f.SetColWidth(sheet, colName(cfgSumCol), colName(cfgSumCol), 90)
+	_ = f.SetColWidth(sheet, colName(cfgFullCol), colName(cfgFullCol), 90)
+
+	return nil
 }
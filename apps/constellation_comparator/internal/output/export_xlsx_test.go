@@ -0,0 +1,74 @@
+package output_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/output"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportXLSX_WeaponVariantsGroupedSeparately(t *testing.T) {
+	dir := t.TempDir()
+	chars := []string{"arlecchino"}
+	result := func(cons, refine, copies, dps int) domain.RunResult {
+		return domain.RunResult{
+			Combination: domain.Combination{
+				ConsByChar:      map[string]int{"arlecchino": cons},
+				WeaponByChar:    map[string]domain.Weapon{"arlecchino": {Name: "deathmatch", Refine: refine, Copies: copies}},
+				TotalAdditional: cons,
+				TotalCopies:     copies,
+			},
+			TeamDps: dps,
+		}
+	}
+	baseline := result(0, 1, 0, 1000)
+	report := output.Report{
+		Chars:    chars,
+		Team:     chars,
+		Results:  []domain.RunResult{baseline, result(0, 2, 1, 1100), result(1, 1, 0, 1050), result(1, 2, 1, 1200)},
+		Baseline: baseline,
+		Cost:     domain.NewCostModel(domain.CostConfig{}, nil),
+	}
+	path, err := output.ExportXLSXToPath(dir, "demo", report, filepath.Join(dir, "out.xlsx"))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	rows, err := f.GetRows("Results")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rows[0][0] != "Доп. конст / копии" {
+		t.Errorf("summary header = %q", rows[0][0])
+	}
+	// Every (constellations, copies) group has its own Summary row; Best % is 100% within each.
+	want := [][2]string{{"0 / 0", "1000"}, {"0 / 1", "1100"}, {"1 / 0", "1050"}, {"1 / 1", "1200"}}
+	if len(rows) != len(want)+1 {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want)+1)
+	}
+	bestPct := -1
+	for col, h := range rows[0] {
+		if h == "Best %" {
+			bestPct = col
+		}
+	}
+	if bestPct < 0 {
+		t.Fatal("no Best % column")
+	}
+	for i, w := range want {
+		row := rows[i+1]
+		if row[0] != w[0] || row[1] != w[1] {
+			t.Errorf("summary row %d = %q %q, want %q %q", i+1, row[0], row[1], w[0], w[1])
+		}
+		if row[bestPct-3] != w[0] || row[bestPct] != "100%" {
+			t.Errorf("full row %d = %q Best %% %q, want %q 100%%", i+1, row[bestPct-3], row[bestPct], w[0])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+// buildFrontierSheet writes the "Frontier" sheet with the cost-vs-team-DPS Pareto frontier
+// and a scatter chart of all combinations (from the Efficiency sheet) against the frontier line.
+//
+// Columns: # | Крутки | Примогемы | Team DPS | Team % | Прирост шага | DPS / крутка шага | char1..charN
+// "Прирост шага" and "DPS / крутка шага" compare a point with the previous frontier point.
+func buildFrontierSheet(f *excelize.File, chars []string, results []domain.RunResult, baseline domain.RunResult, cost domain.CostModel, effRows int) error {
+	const sheet = "Frontier"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	headerStyle, boldStyle, _, err := commonStyles(f)
+	if err != nil {
+		return err
+	}
+	cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
+
+	headers := append([]string{"#", "Крутки", "Примогемы", "Team DPS", "Team %", "Прирост шага", "DPS / крутка шага"}, chars...)
+	for i, h := range headers {
+		f.SetCellStr(sheet, cell(i+1, 1), h)
+	}
+	lastCol := len(headers)
+	_ = f.SetCellStyle(sheet, cell(1, 1), cell(lastCol, 1), headerStyle)
+
+	frontier := domain.ParetoFrontier(results, cost)
+	for i, p := range frontier {
+		row := i + 2
+		isBaseline := p.Result.Combination.IsBaseline()
+		f.SetCellInt(sheet, cell(1, row), int64(i+1))
+		f.SetCellFloat(sheet, cell(2, row), p.Pulls, 1, 64)
+		f.SetCellFloat(sheet, cell(3, row), cost.Primogems(p.Pulls), 0, 64)
+		f.SetCellInt(sheet, cell(4, row), int64(p.Result.TeamDps))
+		f.SetCellStr(sheet, cell(5, row), pctLabel(p.Result.TeamDps, baseline.TeamDps, isBaseline))
+		if i > 0 {
+			prev := frontier[i-1]
+			gain := p.Result.TeamDps - prev.Result.TeamDps
+			f.SetCellInt(sheet, cell(6, row), int64(gain))
+			if dp := p.Pulls - prev.Pulls; dp > 0 {
+				f.SetCellFloat(sheet, cell(7, row), float64(gain)/dp, 2, 64)
+			}
+		}
+		for j, ch := range chars {
+			f.SetCellStr(sheet, cell(8+j, row), charCellLabel(p.Result.Combination, ch))
+		}
+		if isBaseline {
+			_ = f.SetCellStyle(sheet, cell(1, row), cell(lastCol, row), boldStyle)
+		}
+	}
+
+	_ = f.SetColWidth(sheet, colName(1), colName(1), 6)
+	_ = f.SetColWidth(sheet, colName(2), colName(7), 14)
+	if len(chars) > 0 {
+		_ = f.SetColWidth(sheet, colName(8), colName(lastCol), 22)
+	}
+
+	if len(frontier) == 0 {
+		return nil
+	}
+	series := make([]excelize.ChartSeries, 0, 2)
+	if effRows > 0 {
+		series = append(series, excelize.ChartSeries{
+			Name:       "Все комбинации",
+			Categories: fmt.Sprintf("%s!$E$2:$E$%d", efficiencySheet, effRows+1),
+			Values:     fmt.Sprintf("%s!$B$2:$B$%d", efficiencySheet, effRows+1),
+			Marker:     excelize.ChartMarker{Symbol: "circle", Size: 4},
+		})
+	}
+	series = append(series, excelize.ChartSeries{
+		Name:       "Фронт Парето",
+		Categories: fmt.Sprintf("%s!$B$2:$B$%d", sheet, len(frontier)+1),
+		Values:     fmt.Sprintf("%s!$D$2:$D$%d", sheet, len(frontier)+1),
+		Line:       excelize.ChartLine{Type: excelize.ChartLineSolid, Width: 2},
+		Marker:     excelize.ChartMarker{Symbol: "diamond", Size: 7},
+	})
+	return f.AddChart(sheet, cell(lastCol+2, 1), &excelize.Chart{
+		Type:   excelize.Scatter,
+		Series: series,
+		Format: excelize.GraphicOptions{ScaleX: 2, ScaleY: 2},
+		Title:  []excelize.RichTextRun{{Text: "Team DPS vs крутки"}},
+		Legend: excelize.ChartLegend{Position: "bottom"},
+		XAxis:  excelize.ChartAxis{MajorGridLines: true, Title: []excelize.RichTextRun{{Text: "Крутки"}}},
+		YAxis:  excelize.ChartAxis{MajorGridLines: true, Title: []excelize.RichTextRun{{Text: "Team DPS"}}},
+	})
+}
@@ -48,6 +48,8 @@ func ImportResultsXLSX(path string, chars []string) ([]domain.RunResult, error)
 	// Locate columns by header name, searching only from scanStart onward.
 	teamDpsCol := -1
 	charCols := make(map[string]int, len(chars))
+	// Per-member DPS columns ("<char> DPS"); absent in files exported before they were added.
+	memberDpsCols := make(map[string]int)
 
 	for col := scanStart; col < len(header); col++ {
 		h := strings.TrimSpace(header[col])
@@ -55,6 +57,12 @@ func ImportResultsXLSX(path string, chars []string) ([]domain.RunResult, error)
 			teamDpsCol = col
 			continue
 		}
+		if member, ok := strings.CutSuffix(h, " DPS"); ok && teamDpsCol != -1 {
+			if _, already := memberDpsCols[member]; !already {
+				memberDpsCols[member] = col
+			}
+			continue
+		}
 		for _, ch := range chars {
 			if _, already := charCols[ch]; !already && strings.EqualFold(h, ch) {
 				charCols[ch] = col
@@ -99,22 +107,48 @@ func ImportResultsXLSX(path string, chars []string) ([]domain.RunResult, error)
 		}
 
 		consByChar := make(map[string]int, len(chars))
+		var weaponByChar map[string]domain.Weapon
 		for _, ch := range chars {
 			col := charCols[ch]
 			var consLevel int
 			if col < len(row) {
-				cell := strings.TrimSpace(row[col])
-				if strings.HasPrefix(strings.ToUpper(cell), "C") {
-					n, err := strconv.Atoi(cell[1:])
+				// "C2" or "C2 <weapon> R3" (weapon varied).
+				fields := strings.Fields(row[col])
+				if len(fields) > 0 && strings.HasPrefix(strings.ToUpper(fields[0]), "C") {
+					n, err := strconv.Atoi(fields[0][1:])
 					if err == nil && n >= 0 && n <= 6 {
 						consLevel = n
 					}
 				}
+				if len(fields) == 3 && strings.HasPrefix(strings.ToUpper(fields[2]), "R") {
+					refine, err := strconv.Atoi(fields[2][1:])
+					if err == nil && refine >= 1 && refine <= 5 {
+						if weaponByChar == nil {
+							weaponByChar = make(map[string]domain.Weapon, len(chars))
+						}
+						weaponByChar[ch] = domain.Weapon{Name: fields[1], Refine: refine}
+					}
+				}
 			}
 			consByChar[ch] = consLevel
 		}
 
-		combo := domain.Combination{ConsByChar: consByChar}
+		combo := domain.Combination{ConsByChar: consByChar, WeaponByChar: weaponByChar}
+
+		var charDps map[string]int
+		for member, col := range memberDpsCols {
+			if col >= len(row) {
+				continue
+			}
+			v, err := strconv.Atoi(strings.TrimSpace(row[col]))
+			if err != nil {
+				continue
+			}
+			if charDps == nil {
+				charDps = make(map[string]int, len(memberDpsCols))
+			}
+			charDps[member] = v
+		}
 
 		cfg := ""
 		if configCol >= 0 && configCol < len(row) {
@@ -124,6 +158,7 @@ func ImportResultsXLSX(path string, chars []string) ([]domain.RunResult, error)
 		results = append(results, domain.RunResult{
 			Combination: combo,
 			TeamDps:     teamDps,
+			CharDps:     charDps,
 			ConfigFile:  cfg,
 		})
 	}
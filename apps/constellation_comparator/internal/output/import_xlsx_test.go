@@ -0,0 +1,60 @@
+package output_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/output"
+)
+
+func TestImportResultsXLSX_RoundTripCharDps(t *testing.T) {
+	dir := t.TempDir()
+	chars := []string{"chevreuse", "arlecchino"}
+	team := []string{"arlecchino", "chevreuse", "bennett"}
+	baseline := domain.RunResult{
+		Combination: domain.Combination{ConsByChar: map[string]int{"chevreuse": 0, "arlecchino": 0}},
+		TeamDps:     1000,
+		CharDps:     map[string]int{"arlecchino": 700, "chevreuse": 100, "bennett": 200},
+		ConfigFile:  "cfg0",
+	}
+	upgraded := domain.RunResult{
+		Combination: domain.Combination{ConsByChar: map[string]int{"chevreuse": 2, "arlecchino": 0}, TotalAdditional: 2},
+		TeamDps:     1100,
+		CharDps:     map[string]int{"arlecchino": 780, "chevreuse": 110, "bennett": 210},
+		ConfigFile:  "cfg2",
+	}
+
+	report := output.Report{
+		Chars:    chars,
+		Team:     team,
+		Results:  []domain.RunResult{baseline, upgraded},
+		Baseline: baseline,
+		Cost:     domain.NewCostModel(domain.CostConfig{}, nil),
+	}
+	path, err := output.ExportXLSXToPath(dir, "demo", report, filepath.Join(dir, "out.xlsx"))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	imported, err := output.ImportResultsXLSX(path, chars)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(imported))
+	}
+	for _, r := range imported {
+		want := baseline
+		if r.Combination.ConsByChar["chevreuse"] == 2 {
+			want = upgraded
+		}
+		if r.TeamDps != want.TeamDps || r.ConfigFile != want.ConfigFile {
+			t.Errorf("%s: got team dps %d config %q", r.Combination.Key(), r.TeamDps, r.ConfigFile)
+		}
+		for _, ch := range team {
+			if r.CharDps[ch] != want.CharDps[ch] {
+				t.Errorf("%s: %s dps = %d, want %d", r.Combination.Key(), ch, r.CharDps[ch], want.CharDps[ch])
+			}
+		}
+	}
+}
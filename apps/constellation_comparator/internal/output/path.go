@@ -0,0 +1,61 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/genshinsim/gcsim/apps/constellation_comparator/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+// buildPathSheet writes the "Path" sheet: the upgrade path found by greedy/beam search.
+//
+// Columns: Шаг | Team DPS | Прирост шага | Team % | Крутки | char1..charN | Sim Config
+func buildPathSheet(f *excelize.File, chars []string, path []domain.RunResult, baseline domain.RunResult, cost domain.CostModel) error {
+	const sheet = "Path"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	headerStyle, boldStyle, configStyle, err := commonStyles(f)
+	if err != nil {
+		return err
+	}
+	cell := func(col, row int) string { return fmt.Sprintf("%s%d", colName(col), row) }
+
+	headers := append([]string{"Шаг", "Team DPS", "Прирост шага", "Team %", "Крутки"}, chars...)
+	headers = append(headers, "Sim Config")
+	for i, h := range headers {
+		f.SetCellStr(sheet, cell(i+1, 1), h)
+	}
+	lastCol := len(headers)
+	_ = f.SetCellStyle(sheet, cell(1, 1), cell(lastCol, 1), headerStyle)
+
+	for i, r := range path {
+		row := i + 2
+		isBaseline := r.Combination.IsBaseline()
+		f.SetCellInt(sheet, cell(1, row), int64(i))
+		f.SetCellInt(sheet, cell(2, row), int64(r.TeamDps))
+		if i > 0 {
+			f.SetCellInt(sheet, cell(3, row), int64(r.TeamDps-path[i-1].TeamDps))
+		}
+		f.SetCellStr(sheet, cell(4, row), pctLabel(r.TeamDps, baseline.TeamDps, isBaseline))
+		f.SetCellFloat(sheet, cell(5, row), cost.Pulls(r.Combination), 1, 64)
+		for j, ch := range chars {
+			f.SetCellStr(sheet, cell(6+j, row), charCellLabel(r.Combination, ch))
+		}
+		f.SetCellStr(sheet, cell(lastCol, row), r.ConfigFile)
+		if isBaseline {
+			_ = f.SetCellStyle(sheet, cell(1, row), cell(lastCol-1, row), boldStyle)
+		}
+	}
+	if last := len(path) + 1; last >= 2 {
+		_ = f.SetCellStyle(sheet, cell(lastCol, 2), cell(lastCol, last), configStyle)
+	}
+
+	_ = f.SetColWidth(sheet, colName(1), colName(1), 6)
+	_ = f.SetColWidth(sheet, colName(2), colName(5), 14)
+	if len(chars) > 0 {
+		_ = f.SetColWidth(sheet, colName(6), colName(5+len(chars)), 22)
+	}
+	_ = f.SetColWidth(sheet, colName(lastCol), colName(lastCol), 90)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,7 +25,27 @@ type SimulationResult struct {
 		DPS struct {
 			Mean *float64 `json:"mean"`
 		} `json:"dps"`
+		CharacterDps []struct {
+			Mean *float64 `json:"mean"`
+		} `json:"character_dps"`
 	} `json:"statistics"`
+
+	CharacterDetails []struct {
+		Name string `json:"name"`
+	} `json:"character_details"`
+}
+
+// CharacterDps returns the mean DPS of every team member keyed by character name.
+// Members without statistics are omitted.
+func (r *SimulationResult) CharacterDps() map[string]int {
+	out := make(map[string]int, len(r.CharacterDetails))
+	for i, ch := range r.CharacterDetails {
+		if i >= len(r.Statistics.CharacterDps) || r.Statistics.CharacterDps[i].Mean == nil {
+			continue
+		}
+		out[ch.Name] = int(math.Round(*r.Statistics.CharacterDps[i].Mean))
+	}
+	return out
 }
 
 type CLIRunner struct {
@@ -2,17 +2,21 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/artifacts"
 	"github.com/genshinsim/gcsim/apps/enka_import/internal/config"
 	"github.com/genshinsim/gcsim/apps/enka_import/internal/engine"
 	"github.com/genshinsim/gcsim/apps/enka_import/internal/enka"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/good"
 	"github.com/genshinsim/gcsim/apps/enka_import/internal/output"
 	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/team"
 )
 
 func Run(ctx context.Context, cfg config.Config) error {
@@ -31,13 +35,185 @@ func Run(ctx context.Context, cfg config.Config) error {
 		return err
 	}
 
-	client := enka.NewClient("gcsim-rostering enka_import")
-	avatars, profileName, err := client.FetchAvatars(ctx, cfg.UID, cfg.IncludeBuilds)
+	imports, err := importAll(ctx, appRoot, cfg, data)
 	if err != nil {
 		return err
 	}
+	for i := range imports {
+		imports[i].chars = cfg.Filter.Apply(imports[i].chars)
+	}
+	if cfg.Merge && len(imports) > 1 {
+		imports = []imported{mergeImports(imports)}
+	}
+
+	date := time.Now().Format("20060102")
+	outDir := strings.TrimSpace(cfg.OutDir)
+	if outDir == "" {
+		outDir = filepath.Join("output", "enka_import")
+	}
+	for _, imp := range imports {
+		outPath := strings.TrimSpace(cfg.OutPath)
+		if outPath == "" {
+			name := imp.name
+			if len(cfg.UIDs) > 1 && imp.uid != "" && imp.uid != name {
+				// Profile names are not unique; keep one file per UID.
+				name += "_" + imp.uid
+			}
+			outPath = filepath.Join(outDir, fmt.Sprintf("%s_%s.txt", date, name))
+		}
+		outPath = filepath.Clean(outPath)
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return fmt.Errorf("create output dir: %w", err)
+		}
+		if err := output.WriteTextFile(outPath, simcfg.RenderRoster(imp.chars)); err != nil {
+			return err
+		}
+
+		fmt.Printf("Wrote %d character(s) to %s\n", len(imp.chars), outPath)
+
+		if cfg.WriteGood {
+			goodPath, err := writeGOOD(appRoot, outPath, imp.chars)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Wrote GOOD export to %s\n", goodPath)
+		}
+
+		if cfg.ArtifactReport {
+			reportPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".artifacts.xlsx"
+			if err := output.WriteArtifactReportXLSX(reportPath, artifacts.Analyze(imp.chars)); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote artifact report to %s\n", reportPath)
+		}
+	}
+
+	if len(cfg.Team.Members) > 0 {
+		// config.Load allows a team only with a single output.
+		imp := imports[0]
+		teamPath := cfg.Team.OutPath
+		if teamPath == "" {
+			teamPath = filepath.Join(outDir, fmt.Sprintf("%s_%s_team.txt", date, imp.name))
+		}
+		if err := writeTeam(ctx, appRoot, engineRoot, cfg.Team, filepath.Clean(teamPath), imp.chars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imported is the result of one source: a UID, a saved response or a GOOD file.
+type imported struct {
+	// name is the output file base: the profile name, or the UID if it has none.
+	name string
+	// uid is the Enka UID the characters were imported from (empty for GOOD files).
+	uid   string
+	chars []simcfg.SimChar
+}
+
+// importAll converts the configured sources. In a batch of UIDs a profile that cannot be
+// fetched is reported and skipped; the batch fails only if no profile was imported.
+func importAll(ctx context.Context, appRoot string, cfg config.Config, data *engine.EngineData) ([]imported, error) {
+	if cfg.GoodFile != "" {
+		db, err := good.Load(cfg.GoodFile)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Loaded %s (%d character(s), source: %s)\n", cfg.GoodFile, len(db.Characters), db.Source)
+		chars, warnings, skipped := simcfg.ConvertGOODToSimChars(db, data)
+		reportDiagnostics(warnings, skipped)
+		name := safeFilename(strings.TrimSuffix(filepath.Base(cfg.GoodFile), filepath.Ext(cfg.GoodFile)))
+		return []imported{{name: name, chars: chars}}, nil
+	}
+
+	if cfg.FromFile != "" {
+		raw, uid, err := loadSaved(cfg)
+		if err != nil {
+			return nil, err
+		}
+		imp, err := convertEnka(raw, uid, data)
+		if err != nil {
+			return nil, err
+		}
+		return []imported{imp}, nil
+	}
+
+	client := enka.NewClient("gcsim-rostering enka_import")
+	client.Warn = func(err error) { fmt.Fprintf(os.Stderr, "WARN: %v\n", err) }
+	if cfg.Cache {
+		client.CacheDir = filepath.Join(appRoot, "work", "enka_import", "cache")
+	}
+	var out []imported
+	for i, uid := range cfg.UIDs {
+		if len(cfg.UIDs) > 1 {
+			fmt.Printf("[%d/%d] UID %s\n", i+1, len(cfg.UIDs), uid)
+		}
+		imp, err := fetchAndConvert(ctx, appRoot, cfg, client, uid, data)
+		if err != nil {
+			if len(cfg.UIDs) == 1 || ctx.Err() != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "WARN: UID %s skipped: %v\n", uid, err)
+			continue
+		}
+		out = append(out, imp)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("none of %d uid(s) could be imported", len(cfg.UIDs))
+	}
+	return out, nil
+}
+
+func fetchAndConvert(ctx context.Context, appRoot string, cfg config.Config, client *enka.Client, uid string, data *engine.EngineData) (imported, error) {
+	raw, warns, err := client.FetchRaw(ctx, uid, cfg.IncludeBuilds)
+	for _, w := range warns {
+		fmt.Fprintf(os.Stderr, "WARN: %v\n", w)
+	}
+	if err != nil {
+		return imported{}, err
+	}
+	if raw.Cached {
+		fmt.Printf("Using cached Enka response for %s (ttl not expired)\n", uid)
+	}
+	if cfg.SaveRaw {
+		paths, err := enka.SaveRaw(filepath.Join(appRoot, "work", "enka_import"), uid, raw)
+		if err != nil {
+			return imported{}, err
+		}
+		for _, p := range paths {
+			fmt.Println("Saved raw response to", p)
+		}
+	}
+	return convertEnka(raw, uid, data)
+}
 
+func convertEnka(raw enka.RawResponses, uid string, data *engine.EngineData) (imported, error) {
+	avatars, profileName, err := enka.ParseAvatars(raw)
+	if err != nil {
+		return imported{}, err
+	}
 	chars, warnings, skipped := simcfg.ConvertAvatarsToSimChars(avatars, data)
+	reportDiagnostics(warnings, skipped)
+	for i := range chars {
+		chars[i].UID = uid
+	}
+	name := safeFilename(profileName)
+	if name == "" {
+		name = uid
+	}
+	return imported{name: name, uid: uid, chars: chars}, nil
+}
+
+// mergeImports joins several imports into one, in import order.
+func mergeImports(imports []imported) imported {
+	merged := imported{name: fmt.Sprintf("%s_and_%d_more", imports[0].name, len(imports)-1)}
+	for _, imp := range imports {
+		merged.chars = append(merged.chars, imp.chars...)
+	}
+	return merged
+}
+
+func reportDiagnostics(warnings, skipped []error) {
 	if len(warnings) > 0 {
 		fmt.Fprintf(os.Stderr, "WARN: %d warning(s) during import\n", len(warnings))
 		for _, e := range warnings {
@@ -50,34 +226,104 @@ func Run(ctx context.Context, cfg config.Config) error {
 			fmt.Fprintf(os.Stderr, "  - %v\n", e)
 		}
 	}
+}
 
-	text := simcfg.RenderSimConfig(chars)
-
-	outPath := strings.TrimSpace(cfg.OutPath)
-	if outPath == "" {
-		base := safeFilename(strings.TrimSpace(profileName))
-		if base == "" {
-			base = cfg.UID
-		}
-		date := time.Now().Format("20060102")
-		outDir := strings.TrimSpace(cfg.OutDir)
-		if outDir == "" {
-			outDir = filepath.Join("output", "enka_import")
-		}
-		outPath = filepath.Join(outDir, fmt.Sprintf("%s_%s.txt", date, base))
+// writeTeam renders the team config from the rotation template, writes it to teamPath and
+// optionally dry-runs it with the engine (from a copy in work/enka_import/).
+func writeTeam(ctx context.Context, appRoot, engineRoot string, tc config.TeamConfig, teamPath string, chars []simcfg.SimChar) error {
+	members, err := team.ResolveMembers(chars, tc.Members)
+	if err != nil {
+		return err
+	}
+	tmpl, err := os.ReadFile(tc.Template)
+	if err != nil {
+		return fmt.Errorf("read team template: %w", err)
+	}
+	text, err := team.Render(string(tmpl), members)
+	if err != nil {
+		return err
 	}
-	outPath = filepath.Clean(outPath)
-	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
-		return fmt.Errorf("create output dir: %w", err)
+	if err := os.MkdirAll(filepath.Dir(teamPath), 0o755); err != nil {
+		return fmt.Errorf("create team output dir: %w", err)
 	}
-	if err := output.WriteTextFile(outPath, text); err != nil {
+	if err := output.WriteTextFile(teamPath, text); err != nil {
 		return err
 	}
+	fmt.Printf("Wrote team config (%d member(s)) to %s\n", len(members), teamPath)
 
-	fmt.Printf("Wrote %d character(s) to %s\n", len(chars), outPath)
+	if !tc.Validate {
+		return nil
+	}
+	workDir := filepath.Join(appRoot, "work", "enka_import")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return fmt.Errorf("create work dir: %w", err)
+	}
+	dryPath := filepath.Join(workDir, "team_validate.txt")
+	if err := output.WriteTextFile(dryPath, engine.DryRunConfig(text)); err != nil {
+		return err
+	}
+	dps, err := engine.Validate(ctx, engineRoot, dryPath)
+	if err != nil {
+		return fmt.Errorf("team config validation failed: %w", err)
+	}
+	fmt.Printf("Team config validated (1 iteration, %.0f DPS)\n", dps)
 	return nil
 }
 
+// writeGOOD exports chars as GOOD JSON next to outPath ("<out>.good.json").
+func writeGOOD(appRoot, outPath string, chars []simcfg.SimChar) (string, error) {
+	keys, err := good.LoadKeys(filepath.Join(appRoot, "data", "good_keys.yaml"))
+	if err != nil {
+		return "", err
+	}
+	db, warnings := simcfg.BuildGOOD(chars, keys, "gcsim-rostering enka_import")
+	if len(warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "WARN: %d warning(s) during GOOD export\n", len(warnings))
+		for _, e := range warnings {
+			fmt.Fprintf(os.Stderr, "  - %v\n", e)
+		}
+	}
+	goodPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".good.json"
+	if err := good.Write(goodPath, db); err != nil {
+		return "", err
+	}
+	return goodPath, nil
+}
+
+// loadSaved returns the saved Enka responses of cfg.FromFile and the UID they belong to.
+func loadSaved(cfg config.Config) (enka.RawResponses, string, error) {
+	buildsPath := cfg.FromBuildsFile
+	if buildsPath == "" && cfg.IncludeBuilds {
+		buildsPath = siblingBuildsFile(cfg.FromFile)
+	}
+	raw, err := enka.LoadRaw(cfg.FromFile, buildsPath)
+	if err != nil {
+		return enka.RawResponses{}, "", err
+	}
+	if buildsPath != "" {
+		fmt.Printf("Loaded %s and %s\n", cfg.FromFile, buildsPath)
+	} else {
+		fmt.Printf("Loaded %s\n", cfg.FromFile)
+	}
+	var head enka.UIDResponse
+	if err := json.Unmarshal(raw.UID, &head); err == nil && head.UID != "" {
+		return raw, head.UID, nil
+	}
+	return raw, cfg.UID, nil
+}
+
+// siblingBuildsFile returns "<x>_builds.json" for a "<x>_uid.json" saved by -save-raw, if it exists.
+func siblingBuildsFile(uidPath string) string {
+	if !strings.HasSuffix(uidPath, "_uid.json") {
+		return ""
+	}
+	p := strings.TrimSuffix(uidPath, "_uid.json") + "_builds.json"
+	if _, err := os.Stat(p); err != nil {
+		return ""
+	}
+	return p
+}
+
 func safeFilename(name string) string {
 	name = strings.TrimSpace(name)
 	if name == "" {
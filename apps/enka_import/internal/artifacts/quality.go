@@ -0,0 +1,124 @@
+package artifacts
+
+import (
+	"math"
+	"sort"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+)
+
+// maxRoll is the highest value of one substat roll by rarity, in GOOD units.
+// A roll is 70%, 80%, 90% or 100% of it.
+var maxRoll = map[int]map[string]float64{
+	5: {
+		"hp": 298.75, "atk": 19.45, "def": 23.15,
+		"hp_": 5.83, "atk_": 5.83, "def_": 7.29,
+		"eleMas": 23.31, "enerRech_": 6.48,
+		"critRate_": 3.89, "critDMG_": 7.77,
+	},
+	4: {
+		"hp": 239, "atk": 15.56, "def": 18.52,
+		"hp_": 4.66, "atk_": 4.66, "def_": 5.83,
+		"eleMas": 18.65, "enerRech_": 5.18,
+		"critRate_": 3.11, "critDMG_": 6.22,
+	},
+}
+
+// Displayed values are rounded, so a single max roll can look slightly above maxRoll.
+const rollTolerance = 0.02
+
+var slotOrder = map[string]int{"flower": 0, "plume": 1, "sands": 2, "goblet": 3, "circlet": 4}
+
+// Piece is one artifact with its roll analysis.
+type Piece struct {
+	simcfg.Artifact
+	// CritValue is 2×CR + CD.
+	CritValue float64
+	// Rolls is the estimated number of substat rolls, initial substats included; 0 if the
+	// rarity is not analysed (below 4★).
+	Rolls int
+	// RollValue is the sum of substat values in max rolls, in percent (one max roll = 100).
+	RollValue float64
+	// Quality is the average roll tier, RollValue / Rolls: 70..100.
+	Quality float64
+	// Weakest marks the piece with the lowest RollValue of its character.
+	Weakest bool
+}
+
+// Report is the artifact analysis of one imported character.
+type Report struct {
+	Char      simcfg.SimChar
+	Pieces    []Piece
+	CritValue float64
+	Rolls     int
+	RollValue float64
+}
+
+// Analyze returns one report per character, with pieces in slot order.
+func Analyze(chars []simcfg.SimChar) []Report {
+	out := make([]Report, 0, len(chars))
+	for _, c := range chars {
+		r := Report{Char: c}
+		for _, a := range c.Artifacts {
+			p := AnalyzePiece(a)
+			r.Pieces = append(r.Pieces, p)
+			r.CritValue += p.CritValue
+			r.Rolls += p.Rolls
+			r.RollValue += p.RollValue
+		}
+		sort.SliceStable(r.Pieces, func(i, j int) bool {
+			return slotOrder[r.Pieces[i].Slot] < slotOrder[r.Pieces[j].Slot]
+		})
+		markWeakest(r.Pieces)
+		out = append(out, r)
+	}
+	return out
+}
+
+// AnalyzePiece computes crit value, roll count and roll quality of one artifact.
+func AnalyzePiece(a simcfg.Artifact) Piece {
+	p := Piece{Artifact: a}
+	table := maxRoll[a.Rarity]
+	for _, s := range a.Substats {
+		switch s.Key {
+		case "critRate_":
+			p.CritValue += 2 * s.Value
+		case "critDMG_":
+			p.CritValue += s.Value
+		}
+		max, ok := table[s.Key]
+		if !ok || s.Value <= 0 {
+			continue
+		}
+		p.Rolls += estimateRolls(s.Value, max)
+		p.RollValue += s.Value / max * 100
+	}
+	if p.Rolls > 0 {
+		p.Quality = p.RollValue / float64(p.Rolls)
+	}
+	return p
+}
+
+// estimateRolls returns the fewest rolls that can add up to value.
+func estimateRolls(value, max float64) int {
+	n := int(math.Ceil(value/max - rollTolerance))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func markWeakest(pieces []Piece) {
+	weakest := -1
+	for i, p := range pieces {
+		if p.Rolls == 0 {
+			continue
+		}
+		if weakest < 0 || p.RollValue < pieces[weakest].RollValue {
+			weakest = i
+		}
+	}
+	if weakest >= 0 && len(pieces) > 1 {
+		pieces[weakest].Weakest = true
+	}
+}
@@ -12,16 +12,54 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Engine        string
-	EnginePath    string
-	UID           string
+	Engine     string
+	EnginePath string
+	UID        string
+	// UIDs is every profile to import: UID, the uids list and the UIDsFile roster, deduplicated.
+	UIDs []string
+	// UIDsFile is a roster file with one UID per line ("#" starts a comment).
+	UIDsFile string
+	// Merge writes all UIDs into one file instead of one file per UID.
+	Merge bool
+	// Filter selects which imported characters are written.
+	Filter        simcfg.Filter
 	OutPath       string
 	OutDir        string
 	IncludeBuilds bool
+
+	// FromFile is a saved UID response to convert instead of calling Enka.
+	FromFile string
+	// FromBuildsFile is a saved builds response used together with FromFile.
+	FromBuildsFile string
+	// SaveRaw stores the fetched responses under work/enka_import/.
+	SaveRaw bool
+	// Cache reuses Enka responses from work/enka_import/cache/ until their ttl expires.
+	Cache bool
+
+	// GoodFile is a GOOD (Genshin Optimizer) export to convert instead of an Enka profile.
+	GoodFile string
+	// WriteGood also writes the imported characters as GOOD JSON next to the .txt output.
+	WriteGood bool
+	// ArtifactReport also writes an XLSX artifact quality report next to the .txt output.
+	ArtifactReport bool
+
+	// Team generates a runnable sim config from imported characters and a rotation template.
+	Team TeamConfig
+}
+
+// TeamConfig selects up to four imported characters (by key or Enka build name) for a team config.
+type TeamConfig struct {
+	Members  []string
+	Template string
+	// OutPath defaults to <outDir>/<YYYYMMDD>_<name>_team.txt.
+	OutPath string
+	// Validate runs the engine once on the generated config.
+	Validate bool
 }
 
 var uidRe = regexp.MustCompile(`^([1,2,5-9])\d{8}$`)
@@ -49,6 +87,7 @@ func (o *boolOpt) String() string {
 	}
 	return "false"
 }
+
 func (o *boolOpt) Set(v string) error {
 	b, err := strconv.ParseBool(strings.TrimSpace(v))
 	if err != nil {
@@ -59,13 +98,48 @@ func (o *boolOpt) Set(v string) error {
 	return nil
 }
 
+// switchOpt is a boolOpt that may be given without a value ("-merge"); other boolean options
+// take one ("-cache false" or "-cache=false").
+type switchOpt struct{ boolOpt }
+
+func (o *switchOpt) IsBoolFlag() bool { return true }
+
 type FileConfig struct {
-	Engine        string `yaml:"engine"`
-	EnginePath    string `yaml:"enginePath"`
-	UID           string `yaml:"uid"`
-	OutPath       string `yaml:"outPath"`
-	OutDir        string `yaml:"outDir"`
-	IncludeBuilds *bool  `yaml:"includeBuilds"`
+	Engine        string            `yaml:"engine"`
+	EnginePath    string            `yaml:"enginePath"`
+	UID           string            `yaml:"uid"`
+	UIDs          []string          `yaml:"uids"`
+	UIDsFile      string            `yaml:"uidsFile"`
+	Merge         *bool             `yaml:"merge"`
+	Filter        *FileFilterConfig `yaml:"filter"`
+	OutPath       string            `yaml:"outPath"`
+	OutDir        string            `yaml:"outDir"`
+	IncludeBuilds *bool             `yaml:"includeBuilds"`
+
+	FromFile       string `yaml:"fromFile"`
+	FromBuildsFile string `yaml:"fromBuildsFile"`
+	SaveRaw        *bool  `yaml:"saveRaw"`
+	Cache          *bool  `yaml:"cache"`
+
+	GoodFile  string `yaml:"goodFile"`
+	WriteGood *bool  `yaml:"writeGood"`
+
+	ArtifactReport *bool `yaml:"artifactReport"`
+
+	Team *FileTeamConfig `yaml:"team"`
+}
+
+type FileFilterConfig struct {
+	Chars  []string `yaml:"chars"`
+	Builds []string `yaml:"builds"`
+	Source string   `yaml:"source"`
+}
+
+type FileTeamConfig struct {
+	Members  []string `yaml:"members"`
+	Template string   `yaml:"template"`
+	OutPath  string   `yaml:"outPath"`
+	Validate *bool    `yaml:"validate"`
 }
 
 func Load(appRoot string, args []string) (Config, error) {
@@ -78,28 +152,67 @@ func Load(appRoot string, args []string) (Config, error) {
 	var engineOpt stringOpt
 	var enginePathOpt stringOpt
 	var uidOpt stringOpt
+	var uidsOpt stringOpt
+	var uidsFileOpt stringOpt
+	var mergeOpt switchOpt
+	var charsOpt stringOpt
+	var buildsOpt stringOpt
+	var sourceOpt stringOpt
 	var outOpt stringOpt
 	var outDirOpt stringOpt
 	var includeBuildsOpt boolOpt
 	includeBuildsOpt.v = true
+	var fromFileOpt stringOpt
+	var fromBuildsFileOpt stringOpt
+	var saveRawOpt boolOpt
+	var cacheOpt boolOpt
+	var goodFileOpt stringOpt
+	var writeGoodOpt boolOpt
+	var artifactReportOpt boolOpt
+	var teamOpt stringOpt
+	var teamTemplateOpt stringOpt
+	var teamOutOpt stringOpt
+	var validateTeamOpt boolOpt
 
 	fs.Var(&configPath, "config", "path to config yaml (default: input/enka_import/config.yaml)")
 	fs.BoolVar(&useExamples, "useExamples", false, "use example config from input/enka_import/examples/")
 	fs.Var(&engineOpt, "engine", "engine name under ./engines (e.g. gcsim, wfpsim, wfpsim-custom, custom)")
 	fs.Var(&enginePathOpt, "engine-path", "explicit path to engine root (overrides -engine)")
 	fs.Var(&uidOpt, "uid", "Enka UID (9 digits)")
+	fs.Var(&uidsOpt, "uids", "comma-separated list of UIDs to import")
+	fs.Var(&uidsFileOpt, "uids-file", "roster file with one UID per line")
+	fs.Var(&mergeOpt, "merge", "write all UIDs into one file instead of one file per UID")
+	fs.Var(&charsOpt, "chars", "comma-separated character keys to keep")
+	fs.Var(&buildsOpt, "builds", "comma-separated Enka build names to keep (showcase is not affected)")
+	fs.Var(&sourceOpt, "source", "characters to keep: all, showcase or builds (default all)")
 	fs.Var(&outOpt, "out", "output .txt path (overrides outDir)")
 	fs.Var(&outDirOpt, "out-dir", "output directory (default: output/enka_import)")
 	fs.Var(&includeBuildsOpt, "include-builds", "also fetch Enka profile builds if available")
+	fs.Var(&fromFileOpt, "from-file", "convert a saved Enka UID response (json) instead of fetching")
+	fs.Var(&fromBuildsFileOpt, "from-builds-file", "saved Enka builds response (json) for -from-file")
+	fs.Var(&saveRawOpt, "save-raw", "save fetched Enka responses to work/enka_import/")
+	fs.Var(&cacheOpt, "cache", "reuse Enka responses from work/enka_import/cache/ until their ttl expires (default true)")
+	fs.Var(&goodFileOpt, "good-file", "convert a GOOD (Genshin Optimizer) json export instead of an Enka profile")
+	fs.Var(&writeGoodOpt, "write-good", "also write the imported characters as GOOD json (<out>.good.json)")
+
+	fs.Var(&artifactReportOpt, "artifact-report", "also write an artifact quality report (<out>.artifacts.xlsx)")
+	fs.Var(&teamOpt, "team", "comma-separated team members (character key or build name) for a team config")
+	fs.Var(&teamTemplateOpt, "team-template", "rotation template for the team config ({{team}}, {{char1}}..{{char4}})")
+	fs.Var(&teamOutOpt, "team-out", "team config output path (e.g. input/weapon_roster/config.txt)")
+	fs.Var(&validateTeamOpt, "validate-team", "dry-run the team config with the engine (default true)")
 
 	if err := fs.Parse(args); err != nil {
 		return Config{}, err
 	}
+	if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
 
 	// Defaults
 	cfg := Config{
 		Engine:        "gcsim",
 		IncludeBuilds: true,
+		Cache:         true,
 		OutDir:        filepath.Join("output", "enka_import"),
 	}
 
@@ -126,6 +239,18 @@ func Load(appRoot string, args []string) (Config, error) {
 	}
 	cfg.EnginePath = strings.TrimSpace(fc.EnginePath)
 	cfg.UID = strings.TrimSpace(fc.UID)
+	uids := fc.UIDs
+	cfg.UIDsFile = strings.TrimSpace(fc.UIDsFile)
+	if fc.Merge != nil {
+		cfg.Merge = *fc.Merge
+	}
+	if fc.Filter != nil {
+		cfg.Filter = simcfg.Filter{
+			Chars:  fc.Filter.Chars,
+			Builds: fc.Filter.Builds,
+			Source: strings.TrimSpace(fc.Filter.Source),
+		}
+	}
 	cfg.OutPath = strings.TrimSpace(fc.OutPath)
 	if strings.TrimSpace(fc.OutDir) != "" {
 		cfg.OutDir = strings.TrimSpace(fc.OutDir)
@@ -133,6 +258,31 @@ func Load(appRoot string, args []string) (Config, error) {
 	if fc.IncludeBuilds != nil {
 		cfg.IncludeBuilds = *fc.IncludeBuilds
 	}
+	cfg.FromFile = strings.TrimSpace(fc.FromFile)
+	cfg.FromBuildsFile = strings.TrimSpace(fc.FromBuildsFile)
+	if fc.SaveRaw != nil {
+		cfg.SaveRaw = *fc.SaveRaw
+	}
+	if fc.Cache != nil {
+		cfg.Cache = *fc.Cache
+	}
+	cfg.GoodFile = strings.TrimSpace(fc.GoodFile)
+	if fc.WriteGood != nil {
+		cfg.WriteGood = *fc.WriteGood
+	}
+	if fc.ArtifactReport != nil {
+		cfg.ArtifactReport = *fc.ArtifactReport
+	}
+
+	cfg.Team.Validate = true
+	if fc.Team != nil {
+		cfg.Team.Members = fc.Team.Members
+		cfg.Team.Template = strings.TrimSpace(fc.Team.Template)
+		cfg.Team.OutPath = strings.TrimSpace(fc.Team.OutPath)
+		if fc.Team.Validate != nil {
+			cfg.Team.Validate = *fc.Team.Validate
+		}
+	}
 
 	// Overlay flags (only if provided)
 	if engineOpt.set {
@@ -144,6 +294,24 @@ func Load(appRoot string, args []string) (Config, error) {
 	if uidOpt.set {
 		cfg.UID = strings.TrimSpace(uidOpt.v)
 	}
+	if uidsOpt.set {
+		uids = strings.Split(uidsOpt.v, ",")
+	}
+	if uidsFileOpt.set {
+		cfg.UIDsFile = strings.TrimSpace(uidsFileOpt.v)
+	}
+	if mergeOpt.set {
+		cfg.Merge = mergeOpt.v
+	}
+	if charsOpt.set {
+		cfg.Filter.Chars = strings.Split(charsOpt.v, ",")
+	}
+	if buildsOpt.set {
+		cfg.Filter.Builds = strings.Split(buildsOpt.v, ",")
+	}
+	if sourceOpt.set {
+		cfg.Filter.Source = strings.TrimSpace(sourceOpt.v)
+	}
 	if outOpt.set {
 		cfg.OutPath = strings.TrimSpace(outOpt.v)
 	}
@@ -153,6 +321,40 @@ func Load(appRoot string, args []string) (Config, error) {
 	if includeBuildsOpt.set {
 		cfg.IncludeBuilds = includeBuildsOpt.v
 	}
+	if fromFileOpt.set {
+		cfg.FromFile = strings.TrimSpace(fromFileOpt.v)
+	}
+	if fromBuildsFileOpt.set {
+		cfg.FromBuildsFile = strings.TrimSpace(fromBuildsFileOpt.v)
+	}
+	if saveRawOpt.set {
+		cfg.SaveRaw = saveRawOpt.v
+	}
+	if cacheOpt.set {
+		cfg.Cache = cacheOpt.v
+	}
+	if goodFileOpt.set {
+		cfg.GoodFile = strings.TrimSpace(goodFileOpt.v)
+	}
+	if writeGoodOpt.set {
+		cfg.WriteGood = writeGoodOpt.v
+	}
+	if artifactReportOpt.set {
+		cfg.ArtifactReport = artifactReportOpt.v
+	}
+
+	if teamOpt.set {
+		cfg.Team.Members = strings.Split(teamOpt.v, ",")
+	}
+	if teamTemplateOpt.set {
+		cfg.Team.Template = strings.TrimSpace(teamTemplateOpt.v)
+	}
+	if teamOutOpt.set {
+		cfg.Team.OutPath = strings.TrimSpace(teamOutOpt.v)
+	}
+	if validateTeamOpt.set {
+		cfg.Team.Validate = validateTeamOpt.v
+	}
 
 	cfg.Engine = strings.TrimSpace(cfg.Engine)
 	cfg.EnginePath = strings.TrimSpace(cfg.EnginePath)
@@ -160,16 +362,90 @@ func Load(appRoot string, args []string) (Config, error) {
 	cfg.OutPath = strings.TrimSpace(cfg.OutPath)
 	cfg.OutDir = strings.TrimSpace(cfg.OutDir)
 
-	if cfg.UID == "" {
+	cfg.Filter.Chars = trimList(cfg.Filter.Chars)
+	cfg.Filter.Builds = trimList(cfg.Filter.Builds)
+	if err := cfg.Filter.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	all := append([]string{cfg.UID}, uids...)
+	if cfg.UIDsFile != "" {
+		fromFile, err := readUIDsFile(cfg.UIDsFile)
+		if err != nil {
+			return Config{}, err
+		}
+		all = append(all, fromFile...)
+	}
+	seenUID := make(map[string]bool, len(all))
+	for _, uid := range trimList(all) {
+		if !uidRe.MatchString(uid) {
+			return Config{}, fmt.Errorf("invalid uid %q (expected 9 digits, e.g. 123456789)", uid)
+		}
+		if !seenUID[uid] {
+			seenUID[uid] = true
+			cfg.UIDs = append(cfg.UIDs, uid)
+		}
+	}
+
+	if cfg.FromBuildsFile != "" && cfg.FromFile == "" {
+		return Config{}, errors.New("-from-builds-file requires -from-file")
+	}
+	if cfg.GoodFile != "" && cfg.FromFile != "" {
+		return Config{}, errors.New("-good-file and -from-file cannot be used together")
+	}
+	cfg.Team.Members = trimList(cfg.Team.Members)
+	if len(cfg.Team.Members) > 0 && cfg.Team.Template == "" {
+		return Config{}, errors.New("team members are set but team template is missing (-team-template)")
+	}
+	if len(cfg.Team.Members) == 0 && cfg.Team.Template != "" {
+		return Config{}, errors.New("team template is set but team members are missing (-team)")
+	}
+	if len(cfg.UIDs) == 0 && cfg.FromFile == "" && cfg.GoodFile == "" {
 		return Config{}, errors.New("missing uid (provide -uid or set uid in input/enka_import/config.yaml)")
 	}
-	if !uidRe.MatchString(cfg.UID) {
-		return Config{}, fmt.Errorf("invalid uid %q (expected 9 digits, e.g. 123456789)", cfg.UID)
+	if len(cfg.UIDs) > 1 {
+		if cfg.FromFile != "" || cfg.GoodFile != "" {
+			return Config{}, errors.New("several uids cannot be combined with -from-file or -good-file")
+		}
+		if !cfg.Merge && cfg.OutPath != "" {
+			return Config{}, errors.New("-out with several uids requires -merge (otherwise one file per uid is written to outDir)")
+		}
+		if !cfg.Merge && len(cfg.Team.Members) > 0 {
+			return Config{}, errors.New("team config with several uids requires -merge")
+		}
 	}
 
 	return cfg, nil
 }
 
+// readUIDsFile reads a roster file: one UID per line, "#" starts a comment.
+func readUIDsFile(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read uids file: %w", err)
+	}
+	var uids []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			uids = append(uids, line)
+		}
+	}
+	return uids, nil
+}
+
+func trimList(list []string) []string {
+	var out []string
+	for _, v := range list {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func loadFileConfig(path string) (FileConfig, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	// reIteration matches the iteration count of the options statement (up to its ';').
+	reIteration = regexp.MustCompile(`(?m)^(\s*options\b[^;]*?\biteration\s*=\s*)\d+`)
+	reOptions   = regexp.MustCompile(`(?m)^(\s*options\b)`)
+)
+
+// DryRunConfig forces a single iteration in a sim config, so that validating it is fast.
+func DryRunConfig(configStr string) string {
+	if reIteration.MatchString(configStr) {
+		return reIteration.ReplaceAllString(configStr, "${1}1")
+	}
+	if loc := reOptions.FindStringSubmatchIndex(configStr); loc != nil {
+		return configStr[:loc[3]] + " iteration=1" + configStr[loc[3]:]
+	}
+	return "options iteration=1;\n" + configStr
+}
+
+// Validate runs the engine CLI once on configPath (already prepared with DryRunConfig) and
+// returns the team DPS of that run. Parse or runtime errors of the config are returned as-is.
+func Validate(ctx context.Context, engineRoot, configPath string) (float64, error) {
+	exe, err := resolveCLI(engineRoot)
+	if err != nil {
+		return 0, err
+	}
+	outPath := filepath.Join(filepath.Dir(configPath), "validate_result.json")
+	_ = os.Remove(outPath)
+
+	cmd := exec.CommandContext(ctx, exe, "-c", configPath, "-out", outPath)
+	cmd.Dir = engineRoot
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		msg := fmt.Sprintf("engine rejected %s: %v", configPath, err)
+		if b := bytes.TrimSpace(out.Bytes()); len(b) > 0 {
+			msg += "\n" + string(b)
+		}
+		return 0, errors.New(msg)
+	}
+
+	b, err := os.ReadFile(outPath)
+	if err != nil {
+		return 0, fmt.Errorf("read engine result %q: %w", outPath, err)
+	}
+	var res struct {
+		Statistics struct {
+			DPS struct {
+				Mean *float64 `json:"mean"`
+			} `json:"dps"`
+		} `json:"statistics"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("parse engine result %q: %w", outPath, err)
+	}
+	if res.Statistics.DPS.Mean == nil {
+		return 0, fmt.Errorf("engine result missing statistics.dps.mean (%q)", outPath)
+	}
+	return *res.Statistics.DPS.Mean, nil
+}
+
+// resolveCLI returns engines/bins/<engine>/gcsim.exe for an engine root under engines/.
+func resolveCLI(engineRoot string) (string, error) {
+	parent := filepath.Dir(engineRoot)
+	if filepath.Base(parent) != "engines" {
+		return "", fmt.Errorf("engine root %q is not under an 'engines' directory; cannot derive engines/bins path", engineRoot)
+	}
+	exe := filepath.Join(parent, "bins", filepath.Base(engineRoot), "gcsim.exe")
+	if _, err := os.Stat(exe); err != nil {
+		return "", fmt.Errorf("cannot find engine CLI at %q (run scripts/engines/bootstrap.ps1)", exe)
+	}
+	return exe, nil
+}
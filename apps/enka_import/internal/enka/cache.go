@@ -0,0 +1,69 @@
+package enka
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheEntry is one cached fetch: the UID response (with builds, if they were requested)
+// and when it stops being fresh according to Enka's ttl.
+type cacheEntry struct {
+	Expires       time.Time       `json:"expires"`
+	IncludeBuilds bool            `json:"includeBuilds"`
+	UID           json.RawMessage `json:"uid"`
+	Builds        json.RawMessage `json:"builds,omitempty"`
+}
+
+func (c *Client) cachePath(uid string) string {
+	return filepath.Join(c.CacheDir, uid+".json")
+}
+
+// readCache returns a fresh cached response; entries fetched without builds do not serve
+// requests that include them.
+func (c *Client) readCache(uid string, includeBuilds bool) (RawResponses, bool) {
+	if c.CacheDir == "" {
+		return RawResponses{}, false
+	}
+	b, err := os.ReadFile(c.cachePath(uid))
+	if err != nil {
+		return RawResponses{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil || !time.Now().Before(e.Expires) {
+		return RawResponses{}, false
+	}
+	if includeBuilds && !e.IncludeBuilds {
+		return RawResponses{}, false
+	}
+	raw := RawResponses{UID: e.UID, Cached: true}
+	if includeBuilds && len(e.Builds) > 0 {
+		raw.Builds = e.Builds
+	}
+	return raw, true
+}
+
+func (c *Client) writeCache(uid string, includeBuilds bool, ttl int, raw RawResponses) error {
+	if c.CacheDir == "" || ttl <= 0 {
+		return nil
+	}
+	e := cacheEntry{
+		Expires:       time.Now().Add(time.Duration(ttl) * time.Second),
+		IncludeBuilds: includeBuilds,
+		UID:           raw.UID,
+		Builds:        raw.Builds,
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode enka cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.CacheDir, 0o755); err != nil {
+		return fmt.Errorf("create enka cache dir: %w", err)
+	}
+	if err := os.WriteFile(c.cachePath(uid), b, 0o644); err != nil {
+		return fmt.Errorf("write enka cache: %w", err)
+	}
+	return nil
+}
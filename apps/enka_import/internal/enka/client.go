@@ -1,27 +1,67 @@
 package enka
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// DefaultBaseURL is the Enka.Network origin used by NewClient.
+const DefaultBaseURL = "https://enka.network"
+
+var (
+	// ErrInvalidUID is returned for a UID Enka rejects as malformed (HTTP 400).
+	ErrInvalidUID = errors.New("enka: invalid uid")
+	// ErrUIDNotFound is returned for a UID without a player (HTTP 404).
+	ErrUIDNotFound = errors.New("enka: uid not found")
+	// ErrProfilePrivate is returned when the profile has no public character showcase and no builds.
+	ErrProfilePrivate = errors.New("enka: profile has no public characters (enable \"Show Character Details\" in game)")
+	// ErrUnavailable is returned when Enka kept answering with maintenance/rate limit/server errors.
+	ErrUnavailable = errors.New("enka: service unavailable")
+)
+
 type Client struct {
 	httpClient *http.Client
 	userAgent  string
+
+	// BaseURL is the Enka origin; tests point it at a local stand-in.
+	BaseURL string
+	// MaxRetries is the number of retries after a 424/429/5xx answer or a network error.
+	MaxRetries int
+	// Backoff is the first retry delay; it doubles on every retry up to MaxBackoff.
+	// A longer Retry-After from Enka is honoured, also capped by MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// CacheDir keeps UID responses until their Enka ttl expires; empty disables the cache.
+	CacheDir string
+	// Sleep waits between retries (replaced in tests).
+	Sleep func(ctx context.Context, d time.Duration) error
+	// Warn, if set, is told about every failed attempt that is going to be retried.
+	Warn func(err error)
 }
 
 func NewClient(userAgent string) *Client {
 	return &Client{
 		httpClient: &http.Client{Timeout: 25 * time.Second},
 		userAgent:  userAgent,
+		BaseURL:    DefaultBaseURL,
+		MaxRetries: 4,
+		Backoff:    2 * time.Second,
+		MaxBackoff: time.Minute,
+		Sleep:      sleepContext,
 	}
 }
 
 type UIDResponse struct {
+	UID            string       `json:"uid"`
+	TTL            int          `json:"ttl"`
 	AvatarInfoList []AvatarInfo `json:"avatarInfoList"`
 	PlayerInfo     *struct {
 		Nickname string `json:"nickname"`
@@ -33,16 +73,74 @@ type UIDResponse struct {
 }
 
 type ProfileBuild struct {
+	ID         int        `json:"id"`
 	Live       bool       `json:"live"`
 	Name       string     `json:"name"`
 	AvatarData AvatarInfo `json:"avatar_data"`
 }
 
-func (c *Client) FetchAvatars(ctx context.Context, uid string, includeBuilds bool) ([]AvatarInfo, string, error) {
-	uidURL := fmt.Sprintf("https://enka.network/api/uid/%s", uid)
+// FetchRaw downloads the UID response and, if requested and the profile is linked to an
+// Enka account, its builds. A failed builds request is not an error: Builds stays nil and the
+// failure is returned as a warning. Responses come from the cache while their ttl lasts.
+func (c *Client) FetchRaw(ctx context.Context, uid string, includeBuilds bool) (RawResponses, []error, error) {
+	if raw, ok := c.readCache(uid, includeBuilds); ok {
+		return raw, nil, nil
+	}
+
+	uidURL := fmt.Sprintf("%s/api/uid/%s", c.BaseURL, uid)
+	uidBody, err := c.get(ctx, uidURL)
+	if err != nil {
+		return RawResponses{}, nil, uidError(err)
+	}
+	raw := RawResponses{UID: uidBody}
+
+	var uidResp UIDResponse
+	if err := decodeJSON(uidBody, &uidResp); err != nil {
+		return RawResponses{}, nil, fmt.Errorf("decode json from %s: %w", uidURL, err)
+	}
+
+	var warns []error
+	buildsFailed := false
+	if includeBuilds {
+		if uidResp.Owner != nil && uidResp.Owner.Username != "" && uidResp.Owner.Hash != "" {
+			buildsURL := fmt.Sprintf(
+				"%s/api/profile/%s/hoyos/%s/builds/",
+				c.BaseURL,
+				uidResp.Owner.Username,
+				uidResp.Owner.Hash,
+			)
+			body, err := c.get(ctx, buildsURL)
+			if err != nil {
+				if ctx.Err() != nil {
+					return RawResponses{}, nil, ctx.Err()
+				}
+				warns = append(warns, fmt.Errorf("builds not fetched: %w", err))
+				buildsFailed = true
+			} else {
+				raw.Builds = body
+			}
+		} else {
+			warns = append(warns, fmt.Errorf("builds not fetched: uid %s is not linked to an Enka account", uid))
+		}
+	}
+
+	if len(uidResp.AvatarInfoList) == 0 && raw.Builds == nil {
+		return RawResponses{}, warns, fmt.Errorf("%w: uid %s", ErrProfilePrivate, uid)
+	}
+
+	// Without the builds the entry may only serve requests that do not ask for them.
+	if err := c.writeCache(uid, includeBuilds && !buildsFailed, uidResp.TTL, raw); err != nil {
+		warns = append(warns, err)
+	}
+	return raw, warns, nil
+}
+
+// ParseAvatars decodes raw responses into the avatar list (showcase first, then non-live builds)
+// and the profile nickname.
+func ParseAvatars(raw RawResponses) ([]AvatarInfo, string, error) {
 	var uidResp UIDResponse
-	if err := c.getJSON(ctx, uidURL, &uidResp); err != nil {
-		return nil, "", err
+	if err := decodeJSON(raw.UID, &uidResp); err != nil {
+		return nil, "", fmt.Errorf("decode uid response: %w", err)
 	}
 
 	profileName := ""
@@ -53,55 +151,153 @@ func (c *Client) FetchAvatars(ctx context.Context, uid string, includeBuilds boo
 	avatars := make([]AvatarInfo, 0, len(uidResp.AvatarInfoList)+16)
 	avatars = append(avatars, uidResp.AvatarInfoList...)
 
-	if includeBuilds && uidResp.Owner != nil && uidResp.Owner.Username != "" && uidResp.Owner.Hash != "" {
-		buildsURL := fmt.Sprintf(
-			"https://enka.network/api/profile/%s/hoyos/%s/builds/",
-			uidResp.Owner.Username,
-			uidResp.Owner.Hash,
-		)
-
-		var raw map[string][]ProfileBuild
-		if err := c.getJSON(ctx, buildsURL, &raw); err == nil {
-			for _, builds := range raw {
-				for _, b := range builds {
-					if b.Live {
-						continue
-					}
-					ai := b.AvatarData
-					if b.Name != "" {
-						ai.Name = &b.Name
-					}
-					avatars = append(avatars, ai)
-				}
+	if len(raw.Builds) > 0 {
+		var builds map[string][]ProfileBuild
+		if err := decodeJSON(raw.Builds, &builds); err != nil {
+			return nil, "", fmt.Errorf("decode builds response: %w", err)
+		}
+		for _, b := range sortedBuilds(builds) {
+			if b.Live {
+				continue
 			}
+			ai := b.AvatarData
+			// Unnamed builds get a stable name so they can be told apart and selected.
+			name := b.Name
+			if name == "" {
+				name = fmt.Sprintf("build %d", b.ID)
+			}
+			ai.Name = &name
+			avatars = append(avatars, ai)
 		}
 	}
 
 	return avatars, profileName, nil
 }
 
-func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+// get requests url, retrying 424 (maintenance), 429 (rate limit), 5xx and network errors with
+// exponential backoff. Other non-2xx answers are returned as *StatusError.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	delay := c.Backoff
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.getOnce(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var re *retryableError
+		if !errors.As(err, &re) {
+			return nil, err
+		}
+		if attempt >= c.MaxRetries {
+			return nil, fmt.Errorf("%w after %d attempt(s): %v", ErrUnavailable, attempt+1, re.err)
+		}
+
+		wait := delay
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+			wait = c.MaxBackoff
+		}
+		if c.Warn != nil {
+			c.Warn(fmt.Errorf("%v; retrying in %s", re.err, wait))
+		}
+		if err := c.Sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+// StatusError is a non-2xx answer that is not retried.
+type StatusError struct {
+	Code int
+	URL  string
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("enka api status %d for %s: %s", e.Code, e.URL, e.Body)
+}
+
+// uidError maps the answers of the UID endpoint: 400 to ErrInvalidUID and 404 to ErrUIDNotFound.
+func uidError(err error) error {
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return err
+	}
+	switch se.Code {
+	case http.StatusBadRequest:
+		return fmt.Errorf("%w (%v)", ErrInvalidUID, err)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w (%v)", ErrUIDNotFound, err)
+	}
+	return err
+}
+
+func (c *Client) getOnce(ctx context.Context, url string) ([]byte, time.Duration, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, 0, &retryableError{err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
-		return fmt.Errorf("enka api status %d for %s: %s", resp.StatusCode, url, string(b))
+		statusErr := &StatusError{Code: resp.StatusCode, URL: url, Body: strings.TrimSpace(string(b))}
+		if resp.StatusCode == http.StatusFailedDependency || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), &retryableError{statusErr}
+		}
+		return nil, 0, statusErr
 	}
 
-	dec := json.NewDecoder(resp.Body)
-	dec.UseNumber()
-	if err := dec.Decode(out); err != nil {
-		return fmt.Errorf("decode json from %s: %w", url, err)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, &retryableError{fmt.Errorf("read response from %s: %w", url, err)}
+	}
+	return body, 0, nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
 	}
-	return nil
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func decodeJSON(body []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return dec.Decode(out)
 }
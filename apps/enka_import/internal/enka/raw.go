@@ -0,0 +1,82 @@
+package enka
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RawResponses are the unparsed Enka API responses of one UID, as fetched or saved on disk.
+type RawResponses struct {
+	UID []byte
+	// Builds is nil when builds were not requested or are unavailable.
+	Builds []byte
+	// Cached is set when the responses came from the client cache instead of Enka.
+	Cached bool
+}
+
+// LoadRaw reads a saved UID response and, if buildsPath is not empty, a saved builds response.
+func LoadRaw(uidPath, buildsPath string) (RawResponses, error) {
+	uidBody, err := os.ReadFile(uidPath)
+	if err != nil {
+		return RawResponses{}, fmt.Errorf("read enka uid response %s: %w", uidPath, err)
+	}
+	raw := RawResponses{UID: uidBody}
+	if buildsPath != "" {
+		raw.Builds, err = os.ReadFile(buildsPath)
+		if err != nil {
+			return RawResponses{}, fmt.Errorf("read enka builds response %s: %w", buildsPath, err)
+		}
+	}
+	return raw, nil
+}
+
+// RawFileNames returns the file names SaveRaw uses: <YYYYMMDD>_<uid>_uid.json and <YYYYMMDD>_<uid>_builds.json.
+func RawFileNames(uid string, now time.Time) (string, string) {
+	base := now.Format("20060102") + "_" + uid
+	return base + "_uid.json", base + "_builds.json"
+}
+
+// SaveRaw writes the responses unchanged into dir and returns the written paths.
+func SaveRaw(dir, uid string, raw RawResponses) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create raw dir: %w", err)
+	}
+	uidName, buildsName := RawFileNames(uid, time.Now())
+	paths := []string{filepath.Join(dir, uidName)}
+	if err := os.WriteFile(paths[0], raw.UID, 0o644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", paths[0], err)
+	}
+	if raw.Builds != nil {
+		p := filepath.Join(dir, buildsName)
+		if err := os.WriteFile(p, raw.Builds, 0o644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", p, err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// sortedBuilds flattens the builds map by avatar id so the output does not depend on map order.
+func sortedBuilds(builds map[string][]ProfileBuild) []ProfileBuild {
+	keys := make([]string, 0, len(builds))
+	for k := range builds {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	var out []ProfileBuild
+	for _, k := range keys {
+		out = append(out, builds[k]...)
+	}
+	return out
+}
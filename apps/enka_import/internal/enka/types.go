@@ -7,7 +7,9 @@ type AvatarInfo struct {
 	PropMap       map[string]PropMapItem `json:"propMap"`
 	SkillDepotID  int                    `json:"skillDepotId"`
 	SkillLevelMap map[string]int         `json:"skillLevelMap"`
-	EquipList     []EquipItem            `json:"equipList"`
+	// ProudSkillExtraLevelMap holds the +3 talent levels granted by C3/C5, keyed by proud skill group.
+	ProudSkillExtraLevelMap map[string]int `json:"proudSkillExtraLevelMap,omitempty"`
+	EquipList               []EquipItem    `json:"equipList"`
 }
 
 type PropMapItem struct {
@@ -32,7 +34,8 @@ type EquipReliquary struct {
 }
 
 type EquipFlat struct {
-	ItemType string `json:"itemType"`
+	ItemType  string `json:"itemType"`
+	EquipType string `json:"equipType"`
 
 	SetNameTextMapHash string `json:"setNameTextMapHash"`
 	RankLevel          int    `json:"rankLevel"`
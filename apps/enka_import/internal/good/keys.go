@@ -0,0 +1,41 @@
+package good
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys lists the GOOD keys Genshin Optimizer accepts (data/good_keys.yaml).
+type Keys struct {
+	Characters []string `yaml:"characters"`
+	Weapons    []string `yaml:"weapons"`
+	Artifacts  []string `yaml:"artifacts"`
+}
+
+// LoadKeys reads the GOOD key list.
+func LoadKeys(path string) (Keys, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Keys{}, fmt.Errorf("read GOOD keys %s: %w", path, err)
+	}
+	var k Keys
+	if err := yaml.Unmarshal(b, &k); err != nil {
+		return Keys{}, fmt.Errorf("parse GOOD keys %s: %w", path, err)
+	}
+	return k, nil
+}
+
+// Write saves db as indented GOOD JSON.
+func Write(path string, db Database) error {
+	b, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode GOOD: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package good
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Load reads a GOOD JSON file.
+func Load(path string) (Database, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Database{}, fmt.Errorf("read GOOD file %s: %w", path, err)
+	}
+	var db Database
+	if err := json.Unmarshal(b, &db); err != nil {
+		return Database{}, fmt.Errorf("parse GOOD file %s: %w", path, err)
+	}
+	if db.Format != "GOOD" {
+		return Database{}, fmt.Errorf("parse GOOD file %s: format is %q, expected \"GOOD\"", path, db.Format)
+	}
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package good
+
+// Database is a GOOD (Genshin Open Object Description) export, as written by Genshin Optimizer
+// or Inventory Kamera. Keys are PascalCase GOOD keys (e.g. "KaedeharaKazuha", "GladiatorsFinale").
+type Database struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	Source     string      `json:"source"`
+	Characters []Character `json:"characters"`
+	Weapons    []Weapon    `json:"weapons"`
+	Artifacts  []Artifact  `json:"artifacts"`
+}
+
+type Character struct {
+	Key           string  `json:"key"`
+	Level         int     `json:"level"`
+	Constellation int     `json:"constellation"`
+	Ascension     int     `json:"ascension"`
+	Talent        Talents `json:"talent"`
+}
+
+type Talents struct {
+	Auto  int `json:"auto"`
+	Skill int `json:"skill"`
+	Burst int `json:"burst"`
+}
+
+type Weapon struct {
+	Key        string `json:"key"`
+	Level      int    `json:"level"`
+	Ascension  int    `json:"ascension"`
+	Refinement int    `json:"refinement"`
+	Location   string `json:"location"`
+	Lock       bool   `json:"lock"`
+}
+
+type Artifact struct {
+	SetKey      string    `json:"setKey"`
+	SlotKey     string    `json:"slotKey"`
+	Level       int       `json:"level"`
+	Rarity      int       `json:"rarity"`
+	MainStatKey string    `json:"mainStatKey"`
+	Location    string    `json:"location"`
+	Lock        bool      `json:"lock"`
+	Substats    []Substat `json:"substats"`
+}
+
+// Substat values are in GOOD units: percent stats (keys ending in "_") as percents, e.g. 3.9 for 3.9% CR.
+type Substat struct {
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/artifacts"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+	"github.com/xuri/excelize/v2"
+)
+
+var statLabels = map[string]string{
+	"hp": "HP", "hp_": "HP%", "atk": "ATK", "atk_": "ATK%", "def": "DEF", "def_": "DEF%",
+	"eleMas": "EM", "enerRech_": "ER%", "critRate_": "CR%", "critDMG_": "CD%", "heal_": "Heal%",
+	"pyro_dmg_": "Pyro%", "hydro_dmg_": "Hydro%", "cryo_dmg_": "Cryo%", "electro_dmg_": "Electro%",
+	"anemo_dmg_": "Anemo%", "geo_dmg_": "Geo%", "dendro_dmg_": "Dendro%", "physical_dmg_": "Phys%",
+}
+
+// WriteArtifactReportXLSX writes the "Artifacts" sheet: every artifact of every character with
+// crit value, estimated rolls and roll quality, a total row per character and the weakest piece highlighted.
+//
+// Columns: Персонаж | Источник | Сет | Слот | Ур. | Осн. стат | Субстаты | CV | Роллы | RV % | Качество % | Слабейший
+func WriteArtifactReportXLSX(path string, reports []artifacts.Report) error {
+	const sheet = "Artifacts"
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#D9E1F2"}},
+	})
+	if err != nil {
+		return err
+	}
+	totalStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	weakStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#F8CBAD"}},
+	})
+	if err != nil {
+		return err
+	}
+	cell := func(col, row int) string {
+		name, _ := excelize.CoordinatesToCellName(col, row)
+		return name
+	}
+
+	headers := []string{"Персонаж", "Источник", "Сет", "Слот", "Ур.", "Осн. стат", "Субстаты", "CV", "Роллы", "RV %", "Качество %", "Слабейший"}
+	for i, h := range headers {
+		f.SetCellStr(sheet, cell(i+1, 1), h)
+	}
+	lastCol := len(headers)
+	_ = f.SetCellStyle(sheet, cell(1, 1), cell(lastCol, 1), headerStyle)
+
+	row := 2
+	for _, r := range reports {
+		source := charSource(r.Char)
+		for _, p := range r.Pieces {
+			f.SetCellStr(sheet, cell(1, row), r.Char.Name)
+			f.SetCellStr(sheet, cell(2, row), source)
+			f.SetCellStr(sheet, cell(3, row), p.Set)
+			f.SetCellStr(sheet, cell(4, row), p.Slot)
+			f.SetCellStr(sheet, cell(5, row), fmt.Sprintf("+%d %d★", p.Level, p.Rarity))
+			f.SetCellStr(sheet, cell(6, row), statLabel(p.MainStat, p.MainValue))
+			f.SetCellStr(sheet, cell(7, row), substatsLabel(p.Substats))
+			f.SetCellFloat(sheet, cell(8, row), p.CritValue, 1, 64)
+			if p.Rolls > 0 {
+				f.SetCellInt(sheet, cell(9, row), int64(p.Rolls))
+				f.SetCellFloat(sheet, cell(10, row), p.RollValue, 0, 64)
+				f.SetCellFloat(sheet, cell(11, row), p.Quality, 1, 64)
+			}
+			if p.Weakest {
+				f.SetCellStr(sheet, cell(12, row), "да")
+				_ = f.SetCellStyle(sheet, cell(1, row), cell(lastCol, row), weakStyle)
+			}
+			row++
+		}
+		f.SetCellStr(sheet, cell(1, row), r.Char.Name)
+		f.SetCellStr(sheet, cell(2, row), source)
+		f.SetCellStr(sheet, cell(3, row), fmt.Sprintf("Итого: %d арт.", len(r.Pieces)))
+		f.SetCellFloat(sheet, cell(8, row), r.CritValue, 1, 64)
+		f.SetCellInt(sheet, cell(9, row), int64(r.Rolls))
+		f.SetCellFloat(sheet, cell(10, row), r.RollValue, 0, 64)
+		if r.Rolls > 0 {
+			f.SetCellFloat(sheet, cell(11, row), r.RollValue/float64(r.Rolls), 1, 64)
+		}
+		_ = f.SetCellStyle(sheet, cell(1, row), cell(lastCol, row), totalStyle)
+		row += 2
+	}
+
+	_ = f.SetColWidth(sheet, "A", "A", 16)
+	_ = f.SetColWidth(sheet, "B", "B", 28)
+	_ = f.SetColWidth(sheet, "C", "C", 26)
+	_ = f.SetColWidth(sheet, "D", "E", 9)
+	_ = f.SetColWidth(sheet, "F", "F", 16)
+	_ = f.SetColWidth(sheet, "G", "G", 48)
+	_ = f.SetColWidth(sheet, "H", "L", 11)
+	_ = f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func charSource(c simcfg.SimChar) string {
+	var parts []string
+	if c.BuildName != "" {
+		parts = append(parts, "build "+strconv.Quote(c.BuildName))
+	} else if c.UID != "" {
+		parts = append(parts, "showcase")
+	}
+	if c.UID != "" {
+		parts = append(parts, "uid "+c.UID)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func statLabel(key string, value float64) string {
+	label, ok := statLabels[key]
+	if !ok {
+		label = key
+	}
+	if !strings.HasSuffix(key, "_") {
+		return label + " " + strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	return label + " " + strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+// substatsLabel lists the substats with crit first, otherwise in artifact order.
+func substatsLabel(subs []simcfg.Substat) string {
+	sorted := append([]simcfg.Substat(nil), subs...)
+	rank := func(k string) int {
+		switch k {
+		case "critRate_":
+			return 0
+		case "critDMG_":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return rank(sorted[i].Key) < rank(sorted[j].Key) })
+	parts := make([]string, 0, len(sorted))
+	for _, s := range sorted {
+		parts = append(parts, statLabel(s.Key, s.Value))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -90,26 +90,72 @@ func convertOne(a enka.AvatarInfo, data *engine.EngineData) (SimChar, []error, e
 	for _, w := range statWarns {
 		warns = append(warns, fmt.Errorf("%s: %v", charKey, w))
 	}
+	artifacts := extractArtifacts(a.EquipList, data)
 
-	tal := Talents{
-		Attack: a.SkillLevelMap[strconv.Itoa(skillDetails.Attack)],
-		Skill:  a.SkillLevelMap[strconv.Itoa(skillDetails.Skill)],
-		Burst:  a.SkillLevelMap[strconv.Itoa(skillDetails.Burst)],
+	tal, talWarns := extractTalents(a, skillDetails)
+	for _, w := range talWarns {
+		warns = append(warns, fmt.Errorf("%s: %v", charKey, w))
+	}
+
+	buildName := ""
+	if a.Name != nil {
+		buildName = *a.Name
 	}
 
 	return SimChar{
-		Name:     charKey,
-		Level:    lvl,
-		MaxLevel: maxLvl,
-		Cons:     len(a.TalentIDList),
-		Talents:  tal,
-		Weapon:   weapon,
-		Sets:     sets,
-		Main:     main,
-		Subs:     subs,
+		Name:      charKey,
+		BuildName: buildName,
+		Level:     lvl,
+		MaxLevel:  maxLvl,
+		Cons:      len(a.TalentIDList),
+		Talents:   tal,
+		Weapon:    weapon,
+		Sets:      sets,
+		Main:      main,
+		Subs:      subs,
+		Artifacts: artifacts,
 	}, warns, nil
 }
 
+// enkaEquipTypeToSlot maps Enka artifact equip types to GOOD slot keys.
+var enkaEquipTypeToSlot = map[string]string{
+	"EQUIP_BRACER":   "flower",
+	"EQUIP_NECKLACE": "plume",
+	"EQUIP_SHOES":    "sands",
+	"EQUIP_RING":     "goblet",
+	"EQUIP_DRESS":    "circlet",
+}
+
+// extractArtifacts keeps the equipped artifacts piece by piece. Pieces with an unknown set keep
+// an empty Set; problems are already reported by extractArtifactSet/extractArtifactStatsSplit.
+func extractArtifacts(items []enka.EquipItem, data *engine.EngineData) []Artifact {
+	var out []Artifact
+	for _, it := range items {
+		if it.Flat.ItemType != "ITEM_RELIQUARY" || it.Reliquary == nil || it.Flat.ReliquaryMainstat == nil {
+			continue
+		}
+		lvl := it.Reliquary.Level - 1
+		if lvl < 0 {
+			lvl = 0
+		}
+		art := Artifact{
+			Set:       data.ArtifactTextMapToKey[it.Flat.SetNameTextMapHash],
+			Slot:      enkaEquipTypeToSlot[it.Flat.EquipType],
+			Level:     lvl,
+			Rarity:    it.Flat.RankLevel,
+			MainStat:  fightPropToGOODKey(it.Flat.ReliquaryMainstat.MainPropID),
+			MainValue: it.Flat.ReliquaryMainstat.StatValue,
+		}
+		for _, sub := range it.Flat.ReliquarySubstats {
+			if k := fightPropToGOODKey(sub.AppendPropID); k != "" {
+				art.Substats = append(art.Substats, Substat{Key: k, Value: sub.StatValue})
+			}
+		}
+		out = append(out, art)
+	}
+	return out
+}
+
 func atoiDefault(propMap map[string]enka.PropMapItem, key string, def int) int {
 	if propMap == nil {
 		return def
@@ -0,0 +1,58 @@
+package simcfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Character sources selectable with Filter.Source.
+const (
+	SourceAll      = "all"
+	SourceShowcase = "showcase"
+	SourceBuilds   = "builds"
+)
+
+// Filter selects imported characters. Empty lists select everything.
+type Filter struct {
+	// Chars keeps only these character keys.
+	Chars []string
+	// Builds keeps only builds with these names; showcase characters are not affected.
+	Builds []string
+	// Source is SourceAll (default), SourceShowcase or SourceBuilds.
+	Source string
+}
+
+// Validate reports an unknown Source.
+func (f Filter) Validate() error {
+	switch f.Source {
+	case "", SourceAll, SourceShowcase, SourceBuilds:
+		return nil
+	}
+	return fmt.Errorf("unknown filter source %q (expected %s, %s or %s)", f.Source, SourceAll, SourceShowcase, SourceBuilds)
+}
+
+// Apply returns the characters the filter keeps, in their original order.
+func (f Filter) Apply(chars []SimChar) []SimChar {
+	out := make([]SimChar, 0, len(chars))
+	for _, c := range chars {
+		isBuild := c.BuildName != ""
+		switch {
+		case f.Source == SourceShowcase && isBuild,
+			f.Source == SourceBuilds && !isBuild,
+			len(f.Chars) > 0 && !containsFold(f.Chars, c.Name),
+			len(f.Builds) > 0 && isBuild && !containsFold(f.Builds, c.BuildName):
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return true
+		}
+	}
+	return false
+}
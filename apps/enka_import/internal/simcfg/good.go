@@ -0,0 +1,226 @@
+package simcfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/engine"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/good"
+)
+
+// goodCharAliases maps normalized GOOD character keys to engine keys where they differ.
+var goodCharAliases = map[string]string{
+	"kaedeharakazuha":   "kazuha",
+	"kamisatoayaka":     "ayaka",
+	"kamisatoayato":     "ayato",
+	"kujousara":         "sara",
+	"raidenshogun":      "raiden",
+	"sangonomiyakokomi": "kokomi",
+	"aratakiitto":       "itto",
+	"shikanoinheizou":   "heizou",
+	"kukishinobu":       "kuki",
+	"yumemizukimizuki":  "mizuki",
+}
+
+// goodKeys resolves GOOD keys (PascalCase) to engine keys (lowercase).
+type goodKeys struct {
+	chars   map[string]string
+	weapons map[string]string
+	sets    map[string]string
+}
+
+func newGoodKeys(data *engine.EngineData) goodKeys {
+	k := goodKeys{
+		chars:   make(map[string]string, len(data.CharIDToData)),
+		weapons: make(map[string]string, len(data.WeaponIDToKey)),
+		sets:    make(map[string]string, len(data.ArtifactTextMapToKey)),
+	}
+	for _, c := range data.CharIDToData {
+		k.chars[normalizeGoodKey(c.Key)] = c.Key
+	}
+	for _, w := range data.WeaponIDToKey {
+		k.weapons[normalizeGoodKey(w)] = w
+	}
+	for _, s := range data.ArtifactTextMapToKey {
+		k.sets[normalizeGoodKey(s)] = s
+	}
+	return k
+}
+
+func (k goodKeys) char(goodKey string) (string, bool) {
+	n := normalizeGoodKey(goodKey)
+	if alias, ok := goodCharAliases[n]; ok {
+		n = alias
+	}
+	if key, ok := k.chars[n]; ok {
+		return key, true
+	}
+	// GOOD has one Traveler per element; the engine keys them by Aether/Lumine.
+	if elem, ok := strings.CutPrefix(n, "traveler"); ok {
+		for _, prefix := range []string{"lumine", "aether"} {
+			if key, ok := k.chars[prefix+elem]; ok {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+func normalizeGoodKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return -1
+	}, key)
+}
+
+// goodLocationMatches reports whether an item location belongs to a GOOD character.
+// Traveler items are located at "Traveler" regardless of element.
+func goodLocationMatches(location, charKey string) bool {
+	if location == "" {
+		return false
+	}
+	if location == charKey {
+		return true
+	}
+	return location == "Traveler" && strings.HasPrefix(charKey, "Traveler")
+}
+
+// ConvertGOODToSimChars converts every character of a GOOD database together with its equipped
+// weapon and artifacts. Characters missing from engine data are returned as skipped errors.
+func ConvertGOODToSimChars(db good.Database, data *engine.EngineData) ([]SimChar, []error, []error) {
+	keys := newGoodKeys(data)
+	chars := make([]SimChar, 0, len(db.Characters))
+	var warnings []error
+	var skipped []error
+
+	for _, gc := range db.Characters {
+		c, warns, err := convertGoodChar(gc, db, keys, data)
+		if err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		chars = append(chars, c)
+		warnings = append(warnings, warns...)
+	}
+	return chars, warnings, skipped
+}
+
+func convertGoodChar(gc good.Character, db good.Database, keys goodKeys, data *engine.EngineData) (SimChar, []error, error) {
+	charKey, ok := keys.char(gc.Key)
+	if !ok {
+		return SimChar{}, nil, fmt.Errorf("GOOD character %s not found in engine data", gc.Key)
+	}
+	var warns []error
+
+	lvl := gc.Level
+	if lvl < 1 {
+		lvl = 1
+	}
+	maxLvl := ascToMaxLvl(gc.Ascension)
+	if lvl > maxLvl {
+		maxLvl = lvl
+	}
+
+	weapon, err := goodWeapon(gc.Key, db.Weapons, keys)
+	if err != nil {
+		warns = append(warns, fmt.Errorf("%s: %v (using dullblade)", charKey, err))
+		weapon = Weapon{Name: "dullblade", Refine: 1, Level: 1, MaxLevel: 20}
+	}
+
+	sets := map[string]int{}
+	main := make([]float64, 22)
+	subs := make([]float64, 22)
+	var artifacts []Artifact
+	for _, a := range db.Artifacts {
+		if !goodLocationMatches(a.Location, gc.Key) {
+			continue
+		}
+		art := Artifact{Slot: a.SlotKey, Level: a.Level, Rarity: a.Rarity, MainStat: a.MainStatKey}
+		for _, sub := range a.Substats {
+			art.Substats = append(art.Substats, Substat{Key: sub.Key, Value: sub.Value})
+		}
+		if setKey, ok := keys.sets[normalizeGoodKey(a.SetKey)]; ok {
+			sets[setKey]++
+			art.Set = setKey
+		} else {
+			warns = append(warns, fmt.Errorf("%s: unrecognized artifact set %s", charKey, a.SetKey))
+		}
+
+		if idx, ok := goodStatToIndex[a.MainStatKey]; ok {
+			val, err := artifactMainValue(data, strconv.Itoa(a.Rarity), a.MainStatKey, a.Level)
+			if err != nil {
+				warns = append(warns, fmt.Errorf("%s: %v", charKey, err))
+			} else {
+				main[idx] += val
+				art.MainValue = val
+				if strings.HasSuffix(a.MainStatKey, "_") {
+					art.MainValue = val * 100
+				}
+			}
+		} else {
+			warns = append(warns, fmt.Errorf("%s: unrecognized artifact main stat %s", charKey, a.MainStatKey))
+		}
+
+		for _, sub := range a.Substats {
+			j, ok := goodStatToIndex[sub.Key]
+			if !ok {
+				continue
+			}
+			v := sub.Value
+			if strings.HasSuffix(sub.Key, "_") {
+				v = v / 100.0
+			}
+			subs[j] += v
+		}
+		artifacts = append(artifacts, art)
+	}
+
+	return SimChar{
+		Name:     charKey,
+		Level:    lvl,
+		MaxLevel: maxLvl,
+		Cons:     gc.Constellation,
+		Talents: Talents{
+			Attack: gc.Talent.Auto,
+			Skill:  gc.Talent.Skill,
+			Burst:  gc.Talent.Burst,
+		},
+		Weapon:    weapon,
+		Sets:      sets,
+		Main:      main,
+		Subs:      subs,
+		Artifacts: artifacts,
+	}, warns, nil
+}
+
+func goodWeapon(charKey string, weapons []good.Weapon, keys goodKeys) (Weapon, error) {
+	for _, w := range weapons {
+		if !goodLocationMatches(w.Location, charKey) {
+			continue
+		}
+		key, ok := keys.weapons[normalizeGoodKey(w.Key)]
+		if !ok {
+			return Weapon{}, fmt.Errorf("unrecognized weapon %s", w.Key)
+		}
+		refine := w.Refinement
+		if refine < 1 {
+			refine = 1
+		}
+		maxLvl := ascLvlMax(w.Ascension)
+		lvl := w.Level
+		if lvl < 1 {
+			lvl = 1
+		}
+		if lvl > maxLvl {
+			lvl = maxLvl
+		}
+		return Weapon{Name: key, Refine: refine, Level: lvl, MaxLevel: maxLvl}, nil
+	}
+	return Weapon{}, fmt.Errorf("no weapon equipped")
+}
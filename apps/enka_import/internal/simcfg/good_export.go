@@ -0,0 +1,144 @@
+package simcfg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/good"
+)
+
+// goodKeyIndex resolves engine keys to GOOD keys by their normalized form.
+type goodKeyIndex map[string]string
+
+func newGoodKeyIndex(keys []string) goodKeyIndex {
+	idx := make(goodKeyIndex, len(keys))
+	for _, k := range keys {
+		idx[normalizeGoodKey(k)] = k
+	}
+	return idx
+}
+
+// resolve returns the GOOD key, or the engine key itself when it is unknown.
+func (idx goodKeyIndex) resolve(engineKey string) (string, bool) {
+	if k, ok := idx[normalizeGoodKey(engineKey)]; ok {
+		return k, true
+	}
+	return engineKey, false
+}
+
+// goodCharKey maps an engine character key to its GOOD key (see goodCharAliases and Travelers).
+func goodCharKey(engineKey string, idx goodKeyIndex) (string, bool) {
+	n := normalizeGoodKey(engineKey)
+	for goodKey, alias := range goodCharAliases {
+		if alias == n {
+			n = goodKey
+			break
+		}
+	}
+	for _, prefix := range []string{"lumine", "aether"} {
+		if elem, ok := strings.CutPrefix(n, prefix); ok && elem != "" {
+			n = "traveler" + elem
+		}
+	}
+	return idx.resolve(n)
+}
+
+// goodAscension derives the ascension phase from the level cap.
+func goodAscension(maxLvl int) int {
+	switch {
+	case maxLvl >= 90:
+		return 6
+	case maxLvl >= 80:
+		return 5
+	case maxLvl >= 70:
+		return 4
+	case maxLvl >= 60:
+		return 3
+	case maxLvl >= 50:
+		return 2
+	case maxLvl >= 40:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// BuildGOOD converts imported characters into a GOOD database for Genshin Optimizer.
+// A character can appear once in GOOD: the weapon and artifacts of further builds of the same
+// character are exported unequipped. Keys missing from keys are written as engine keys, with a warning.
+func BuildGOOD(chars []SimChar, keys good.Keys, source string) (good.Database, []error) {
+	charIdx := newGoodKeyIndex(keys.Characters)
+	weaponIdx := newGoodKeyIndex(keys.Weapons)
+	setIdx := newGoodKeyIndex(keys.Artifacts)
+
+	db := good.Database{
+		Format:     "GOOD",
+		Version:    2,
+		Source:     source,
+		Characters: []good.Character{},
+		Weapons:    []good.Weapon{},
+		Artifacts:  []good.Artifact{},
+	}
+	var warns []error
+	seen := make(map[string]struct{}, len(chars))
+	for _, c := range chars {
+		charKey, ok := goodCharKey(c.Name, charIdx)
+		if !ok {
+			warns = append(warns, fmt.Errorf("%s: no GOOD character key, writing %q", c.Name, charKey))
+		}
+		location := charKey
+		if strings.HasPrefix(charKey, "Traveler") {
+			location = "Traveler"
+		}
+		if _, dup := seen[charKey]; dup {
+			warns = append(warns, fmt.Errorf("%s: another build of the same character, exporting its weapon and artifacts unequipped", c.Name))
+			location = ""
+		} else {
+			seen[charKey] = struct{}{}
+			db.Characters = append(db.Characters, good.Character{
+				Key:           charKey,
+				Level:         c.Level,
+				Constellation: c.Cons,
+				Ascension:     goodAscension(c.MaxLevel),
+				Talent:        good.Talents{Auto: c.Talents.Attack, Skill: c.Talents.Skill, Burst: c.Talents.Burst},
+			})
+		}
+
+		weaponKey, ok := weaponIdx.resolve(c.Weapon.Name)
+		if !ok {
+			warns = append(warns, fmt.Errorf("%s: no GOOD weapon key for %s", c.Name, c.Weapon.Name))
+		}
+		db.Weapons = append(db.Weapons, good.Weapon{
+			Key:        weaponKey,
+			Level:      c.Weapon.Level,
+			Ascension:  goodAscension(c.Weapon.MaxLevel),
+			Refinement: c.Weapon.Refine,
+			Location:   location,
+		})
+
+		for _, a := range c.Artifacts {
+			if a.Set == "" || a.Slot == "" || a.MainStat == "" {
+				warns = append(warns, fmt.Errorf("%s: skipping artifact with unknown set, slot or main stat", c.Name))
+				continue
+			}
+			setKey, ok := setIdx.resolve(a.Set)
+			if !ok {
+				warns = append(warns, fmt.Errorf("%s: no GOOD artifact set key for %s", c.Name, a.Set))
+			}
+			subs := make([]good.Substat, 0, len(a.Substats))
+			for _, s := range a.Substats {
+				subs = append(subs, good.Substat{Key: s.Key, Value: s.Value})
+			}
+			db.Artifacts = append(db.Artifacts, good.Artifact{
+				SetKey:      setKey,
+				SlotKey:     a.Slot,
+				Level:       a.Level,
+				Rarity:      a.Rarity,
+				MainStatKey: a.MainStat,
+				Location:    location,
+				Substats:    subs,
+			})
+		}
+	}
+	return db, warns
+}
@@ -8,7 +8,11 @@ type Weapon struct {
 }
 
 type SimChar struct {
-	Name     string
+	Name string
+	// BuildName is the Enka build name; empty for showcase (and GOOD) characters.
+	BuildName string
+	// UID is the profile the character was imported from; empty for GOOD imports.
+	UID      string
 	Level    int
 	MaxLevel int
 	Cons     int
@@ -17,6 +21,26 @@ type SimChar struct {
 	Sets     map[string]int
 	Main     []float64 // 22-length, main stats only
 	Subs     []float64 // 22-length, substats only
+
+	// Artifacts are the equipped pieces behind Sets/Main/Subs, kept for GOOD export.
+	Artifacts []Artifact
+}
+
+// Artifact is one equipped artifact with GOOD stat keys and units (percent stats in percents).
+type Artifact struct {
+	Set      string // engine set key
+	Slot     string // GOOD slot key: flower, plume, sands, goblet, circlet
+	Level    int    // 0..20
+	Rarity   int
+	MainStat string
+	// MainValue is the main stat value in GOOD units (percent stats in percents).
+	MainValue float64
+	Substats  []Substat
+}
+
+type Substat struct {
+	Key   string
+	Value float64
 }
 
 type Talents struct {
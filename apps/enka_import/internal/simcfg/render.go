@@ -42,6 +42,54 @@ func RenderSimConfig(chars []SimChar) string {
 	return b.String()
 }
 
+// RenderRoster renders an import result: every character block is preceded by a label
+// "# <key>/<n>: <source>" numbering the builds of each character in order. Only the first block
+// of a character is active; the others are commented out so the file stays a valid config and
+// another build can be used by swapping which block is commented.
+func RenderRoster(chars []SimChar) string {
+	var b strings.Builder
+	seen := make(map[string]int, len(chars))
+	for i, c := range chars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		seen[c.Name]++
+		n := seen[c.Name]
+
+		b.WriteString("# ")
+		b.WriteString(c.Name)
+		b.WriteString("/")
+		b.WriteString(strconv.Itoa(n))
+		b.WriteString(": ")
+		b.WriteString(sourceLabel(c))
+		if n > 1 {
+			b.WriteString(" (duplicate, commented out)")
+		}
+		b.WriteString("\n")
+
+		block := renderChar(c)
+		if n > 1 {
+			block = "# " + strings.ReplaceAll(strings.TrimSuffix(block, "\n"), "\n", "\n# ") + "\n"
+		}
+		b.WriteString(block)
+	}
+	return b.String()
+}
+
+func sourceLabel(c SimChar) string {
+	switch {
+	case c.BuildName != "":
+		label := "build " + strconv.Quote(c.BuildName)
+		if c.UID != "" {
+			label += ", uid " + c.UID
+		}
+		return label
+	case c.UID != "":
+		return "showcase, uid " + c.UID
+	}
+	return "GOOD"
+}
+
 func renderChar(c SimChar) string {
 	var b strings.Builder
 
@@ -0,0 +1,108 @@
+package simcfg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/engine"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/enka"
+)
+
+// Proud skill groups of a character are <avatarId%1000>31 (attack), 32 (skill) and 39 (burst),
+// e.g. 5239 for Raiden's burst. Travelers use per-element groups and are not decoded.
+const (
+	proudGroupAttack = 31
+	proudGroupSkill  = 32
+	proudGroupBurst  = 39
+)
+
+// maxBaseTalent is the highest talent level without constellation bonuses.
+const maxBaseTalent = 10
+
+// extractTalents returns the base talent levels gcsim expects (it applies C3/C5 bonuses itself).
+// skillLevelMap already holds base levels; the bonus from proudSkillExtraLevelMap is only
+// subtracted from a level above 10, which must have it included. A bonus that does not match
+// the constellation count is reported.
+func extractTalents(a enka.AvatarInfo, sd engine.SkillDetails) (Talents, []error) {
+	tal := Talents{
+		Attack: a.SkillLevelMap[strconv.Itoa(sd.Attack)],
+		Skill:  a.SkillLevelMap[strconv.Itoa(sd.Skill)],
+		Burst:  a.SkillLevelMap[strconv.Itoa(sd.Burst)],
+	}
+
+	var warns []error
+	bonus, total, unknown := decodeTalentBonus(a.AvatarID, a.ProudSkillExtraLevelMap)
+	if len(unknown) > 0 {
+		warns = append(warns, fmt.Errorf("proudSkillExtraLevelMap: unknown skill group(s) %v", unknown))
+	}
+	cons := len(a.TalentIDList)
+	if want := consTalentBonus(cons); total != want {
+		warns = append(warns, fmt.Errorf("constellation talent bonus +%d does not match C%d (expected +%d)", total, cons, want))
+	}
+
+	fix := func(name string, lvl *int, extra int) {
+		if *lvl <= maxBaseTalent {
+			return
+		}
+		base := *lvl - extra
+		if extra == 0 || base < 1 || base > maxBaseTalent {
+			warns = append(warns, fmt.Errorf("%s level %d > %d without a matching constellation bonus, clamping", name, *lvl, maxBaseTalent))
+			*lvl = maxBaseTalent
+			return
+		}
+		warns = append(warns, fmt.Errorf("%s level %d includes +%d from constellations, using %d", name, *lvl, extra, base))
+		*lvl = base
+	}
+	fix("attack", &tal.Attack, bonus.Attack)
+	fix("skill", &tal.Skill, bonus.Skill)
+	fix("burst", &tal.Burst, bonus.Burst)
+	return tal, warns
+}
+
+// decodeTalentBonus splits proudSkillExtraLevelMap into per-talent bonuses. It also returns
+// the total bonus and the groups that are not attack/skill/burst of this avatar.
+func decodeTalentBonus(avatarID int, extra map[string]int) (Talents, int, []string) {
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var bonus Talents
+	var total int
+	var unknown []string
+	base := (avatarID % 1000) * 100
+	for _, k := range keys {
+		v := extra[k]
+		total += v
+		group, err := strconv.Atoi(k)
+		if err != nil {
+			unknown = append(unknown, k)
+			continue
+		}
+		switch group - base {
+		case proudGroupAttack:
+			bonus.Attack += v
+		case proudGroupSkill:
+			bonus.Skill += v
+		case proudGroupBurst:
+			bonus.Burst += v
+		default:
+			unknown = append(unknown, k)
+		}
+	}
+	return bonus, total, unknown
+}
+
+// consTalentBonus is the total talent bonus of a constellation level: +3 at C3 and at C5.
+func consTalentBonus(cons int) int {
+	bonus := 0
+	if cons >= 3 {
+		bonus += 3
+	}
+	if cons >= 5 {
+		bonus += 3
+	}
+	return bonus
+}
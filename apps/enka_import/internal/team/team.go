@@ -0,0 +1,93 @@
+package team
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+)
+
+// MaxMembers is the size of a gcsim team.
+const MaxMembers = 4
+
+// ResolveMembers picks the imported characters of a team. A member is matched by Enka build name
+// first (case-insensitive), then by character key, preferring the showcase character over builds.
+func ResolveMembers(chars []simcfg.SimChar, members []string) ([]simcfg.SimChar, error) {
+	if len(members) == 0 || len(members) > MaxMembers {
+		return nil, fmt.Errorf("team: expected 1..%d members, got %d", MaxMembers, len(members))
+	}
+	out := make([]simcfg.SimChar, 0, len(members))
+	seen := make(map[string]string, len(members))
+	for _, m := range members {
+		m = strings.TrimSpace(m)
+		c, ok := findMember(chars, m)
+		if !ok {
+			return nil, fmt.Errorf("team: member %q not found among imported characters (use a character key or build name)", m)
+		}
+		if prev, dup := seen[c.Name]; dup {
+			return nil, fmt.Errorf("team: members %q and %q are both %s", prev, m, c.Name)
+		}
+		seen[c.Name] = m
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func findMember(chars []simcfg.SimChar, member string) (simcfg.SimChar, bool) {
+	for _, c := range chars {
+		if c.BuildName != "" && strings.EqualFold(c.BuildName, member) {
+			return c, true
+		}
+	}
+	key := strings.ToLower(member)
+	var fallback *simcfg.SimChar
+	for i, c := range chars {
+		if c.Name != key {
+			continue
+		}
+		if c.BuildName == "" {
+			return c, true
+		}
+		if fallback == nil {
+			fallback = &chars[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return simcfg.SimChar{}, false
+}
+
+var rePlaceholder = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// Render fills a rotation template: {{team}} becomes the character blocks and {{char1}}..{{char4}}
+// the character keys in member order. Without {{team}} the blocks are put before the template.
+func Render(template string, members []simcfg.SimChar) (string, error) {
+	values := map[string]string{"team": simcfg.RenderSimConfig(members)}
+	for i, c := range members {
+		values[fmt.Sprintf("char%d", i+1)] = c.Name
+	}
+
+	var unknown []string
+	hasTeam := false
+	out := rePlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+		name := strings.ToLower(rePlaceholder.FindStringSubmatch(m)[1])
+		if name == "team" {
+			hasTeam = true
+		}
+		v, ok := values[name]
+		if !ok {
+			unknown = append(unknown, m)
+			return m
+		}
+		return v
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("team template: unknown placeholder(s) %s (team has %d member(s))", strings.Join(unknown, ", "), len(members))
+	}
+	if !hasTeam {
+		out = values["team"] + "\n" + out
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/artifacts"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/output"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestAnalyzePiece(t *testing.T) {
+	// 5★ +20: 9 rolls in total (4 initial + 5 upgrades).
+	p := artifacts.AnalyzePiece(simcfg.Artifact{
+		Slot: "sands", Level: 20, Rarity: 5, MainStat: "atk_", MainValue: 46.6,
+		Substats: []simcfg.Substat{
+			{Key: "critRate_", Value: 10.5}, // 3 rolls
+			{Key: "critDMG_", Value: 21.8},  // 3 rolls
+			{Key: "eleMas", Value: 23},      // 1 max roll, rounded down
+			{Key: "def", Value: 37},         // 2 rolls
+		},
+	})
+	if math.Abs(p.CritValue-42.8) > 1e-9 {
+		t.Errorf("CritValue = %v, want 42.8", p.CritValue)
+	}
+	if p.Rolls != 9 {
+		t.Errorf("Rolls = %d, want 9", p.Rolls)
+	}
+	if p.Quality < 70 || p.Quality > 100 {
+		t.Errorf("Quality %v out of 70..100", p.Quality)
+	}
+
+	// Below 4★ there is no roll table.
+	if p := artifacts.AnalyzePiece(simcfg.Artifact{Rarity: 3, Substats: []simcfg.Substat{{Key: "atk_", Value: 4}}}); p.Rolls != 0 {
+		t.Errorf("3★ piece should not be analysed, got %d rolls", p.Rolls)
+	}
+}
+
+func TestAnalyze_WeakestPieceAndXLSX(t *testing.T) {
+	good := []simcfg.Substat{{Key: "critRate_", Value: 7.8}, {Key: "critDMG_", Value: 14}, {Key: "atk_", Value: 5.8}}
+	weak := []simcfg.Substat{{Key: "def", Value: 19}, {Key: "hp", Value: 209}, {Key: "critRate_", Value: 2.7}}
+	chars := []simcfg.SimChar{{
+		Name: "arlecchino", UID: "700000001",
+		Artifacts: []simcfg.Artifact{
+			{Slot: "circlet", Level: 20, Rarity: 5, MainStat: "critDMG_", MainValue: 62.2, Substats: good},
+			{Slot: "flower", Level: 20, Rarity: 5, MainStat: "hp", MainValue: 4780, Substats: weak},
+			{Slot: "plume", Level: 20, Rarity: 5, MainStat: "atk", MainValue: 311, Substats: good},
+		},
+	}}
+	reports := artifacts.Analyze(chars)
+	if len(reports) != 1 || len(reports[0].Pieces) != 3 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	pieces := reports[0].Pieces
+	if pieces[0].Slot != "flower" || pieces[2].Slot != "circlet" {
+		t.Errorf("pieces not in slot order: %s, %s, %s", pieces[0].Slot, pieces[1].Slot, pieces[2].Slot)
+	}
+	if !pieces[0].Weakest || pieces[1].Weakest || pieces[2].Weakest {
+		t.Errorf("expected only the flower to be the weakest piece")
+	}
+
+	path := filepath.Join(t.TempDir(), "report.artifacts.xlsx")
+	if err := output.WriteArtifactReportXLSX(path, reports); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header + 3 pieces + total row
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	if rows[1][3] != "flower" || len(rows[1]) < 12 || rows[1][11] != "да" {
+		t.Errorf("weakest flower row not flagged: %v", rows[1])
+	}
+	if rows[1][5] != "HP 4780" {
+		t.Errorf("main stat cell = %q", rows[1][5])
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/config"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+)
+
+func TestFilter(t *testing.T) {
+	chars := []simcfg.SimChar{
+		{Name: "arlecchino"},
+		{Name: "bennett"},
+		{Name: "arlecchino", BuildName: "C0 deathmatch"},
+		{Name: "arlecchino", BuildName: "C1 crimson"},
+		{Name: "bennett", BuildName: "build 7"},
+	}
+	label := func(cs []simcfg.SimChar) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.Name+"|"+c.BuildName)
+		}
+		return out
+	}
+	cases := []struct {
+		name   string
+		filter simcfg.Filter
+		want   []string
+	}{
+		{"all", simcfg.Filter{}, label(chars)},
+		{"showcase", simcfg.Filter{Source: simcfg.SourceShowcase}, []string{"arlecchino|", "bennett|"}},
+		{"builds", simcfg.Filter{Source: simcfg.SourceBuilds}, []string{"arlecchino|C0 deathmatch", "arlecchino|C1 crimson", "bennett|build 7"}},
+		{"chars", simcfg.Filter{Chars: []string{"Bennett"}}, []string{"bennett|", "bennett|build 7"}},
+		{"build names", simcfg.Filter{Builds: []string{"c1 crimson"}}, []string{"arlecchino|", "bennett|", "arlecchino|C1 crimson"}},
+	}
+	for _, tc := range cases {
+		if got := label(tc.filter.Apply(chars)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if err := (simcfg.Filter{Source: "live"}).Validate(); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestRenderRoster_CommentsOutDuplicates(t *testing.T) {
+	chars := []simcfg.SimChar{
+		{Name: "arlecchino", UID: "700000001", Level: 90, MaxLevel: 90, Cons: 1, Talents: simcfg.Talents{Attack: 10, Skill: 9, Burst: 9}},
+		{Name: "arlecchino", UID: "700000001", BuildName: "C0 deathmatch", Level: 90, MaxLevel: 90, Talents: simcfg.Talents{Attack: 10, Skill: 9, Burst: 9}},
+	}
+	got := simcfg.RenderRoster(chars)
+	active := 0
+	for _, line := range strings.Split(got, "\n") {
+		if strings.HasPrefix(line, "arlecchino char") {
+			active++
+		}
+	}
+	if active != 1 {
+		t.Errorf("expected exactly one active arlecchino char line, got %d:\n%s", active, got)
+	}
+	if !strings.Contains(got, `# arlecchino/2: build "C0 deathmatch", uid 700000001 (duplicate, commented out)`) {
+		t.Errorf("missing duplicate label:\n%s", got)
+	}
+}
+
+func TestLoadConfig_UIDList(t *testing.T) {
+	dir := t.TempDir()
+	roster := filepath.Join(dir, "roster.txt")
+	if err := os.WriteFile(roster, []byte("# main accounts\n700000002  # alt\n\n700000001\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(dir, []string{"-uid", "700000001", "-uids", "700000003, 700000002", "-uids-file", roster, "-merge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"700000001", "700000003", "700000002"}
+	if !reflect.DeepEqual(cfg.UIDs, want) {
+		t.Errorf("UIDs = %v, want %v", cfg.UIDs, want)
+	}
+
+	bad := [][]string{
+		{"-uids", "700000001,12"},
+		{"-uids", "700000001,700000002", "-out", "x.txt"},
+		{"-uids", "700000001,700000002", "-team", "bennett", "-team-template", "t.txt"},
+		{"-uids", "700000001,700000002", "-good-file", "go.json"},
+		{"-uid", "700000001", "-source", "live"},
+		// -merge takes no value; "false" would be left over as an argument
+		{"-uid", "700000001", "-merge", "false"},
+	}
+	for _, args := range bad {
+		if _, err := config.Load(dir, args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestLoadConfig_BoolValues(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := config.Load(dir, []string{"-uid", "700000001", "-cache", "false", "-save-raw", "false", "-include-builds=false", "-merge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cache || cfg.SaveRaw || cfg.IncludeBuilds || !cfg.Merge {
+		t.Errorf("cache=%v saveRaw=%v includeBuilds=%v merge=%v", cfg.Cache, cfg.SaveRaw, cfg.IncludeBuilds, cfg.Merge)
+	}
+}
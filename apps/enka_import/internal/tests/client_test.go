@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/enka"
+)
+
+// enkaStandIn serves the saved showcase responses; fail answers the first requests to /api/uid/.
+type enkaStandIn struct {
+	uidCalls    atomic.Int32
+	buildsCalls atomic.Int32
+	fail        func(call int32, w http.ResponseWriter) bool
+	uidBody     []byte
+	buildsBody  []byte
+	buildsCode  int
+}
+
+func newStandIn(t *testing.T, uidBody []byte) (*enkaStandIn, *enka.Client, *[]time.Duration) {
+	t.Helper()
+	builds, err := os.ReadFile(filepath.Join("testdata", "enka", "showcase_builds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &enkaStandIn{uidBody: uidBody, buildsBody: builds, buildsCode: http.StatusOK}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/uid/", func(w http.ResponseWriter, r *http.Request) {
+		call := s.uidCalls.Add(1)
+		if s.fail != nil && s.fail(call, w) {
+			return
+		}
+		_, _ = w.Write(s.uidBody)
+	})
+	mux.HandleFunc("/api/profile/", func(w http.ResponseWriter, r *http.Request) {
+		s.buildsCalls.Add(1)
+		w.WriteHeader(s.buildsCode)
+		_, _ = w.Write(s.buildsBody)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	var waits []time.Duration
+	c := enka.NewClient("test")
+	c.BaseURL = srv.URL
+	c.Backoff = time.Second
+	c.MaxBackoff = 30 * time.Second
+	c.Sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return s, c, &waits
+}
+
+const ownedUID = `{"uid":"700000001","ttl":60,"playerInfo":{"nickname":"Golden Test"},
+"owner":{"username":"golden","hash":"abc"},"avatarInfoList":[{"avatarId":10000096}]}`
+
+func TestFetchRaw_RetriesWithBackoffAndRetryAfter(t *testing.T) {
+	s, c, waits := newStandIn(t, []byte(ownedUID))
+	s.fail = func(call int32, w http.ResponseWriter) bool {
+		switch call {
+		case 1:
+			w.WriteHeader(http.StatusFailedDependency) // maintenance
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			return false
+		}
+		return true
+	}
+
+	raw, warns, err := c.FetchRaw(context.Background(), "700000001", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warns) != 0 {
+		t.Errorf("unexpected warnings: %v", warns)
+	}
+	if raw.Builds == nil {
+		t.Error("expected builds to be fetched")
+	}
+	want := []time.Duration{time.Second, 7 * time.Second, 4 * time.Second}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", *waits, want)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Errorf("wait %d = %s, want %s", i, (*waits)[i], want[i])
+		}
+	}
+}
+
+func TestFetchRaw_GivesUpAfterMaxRetries(t *testing.T) {
+	s, c, waits := newStandIn(t, []byte(ownedUID))
+	s.fail = func(call int32, w http.ResponseWriter) bool {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return true
+	}
+	c.MaxRetries = 2
+	_, _, err := c.FetchRaw(context.Background(), "700000001", false)
+	if !errors.Is(err, enka.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if got := s.uidCalls.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+	if len(*waits) != 2 {
+		t.Errorf("expected 2 waits, got %v", *waits)
+	}
+}
+
+func TestFetchRaw_ClearErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"bad uid", http.StatusBadRequest, "", enka.ErrInvalidUID},
+		{"not found", http.StatusNotFound, "", enka.ErrUIDNotFound},
+		{"private", http.StatusOK, `{"uid":"700000001","ttl":60,"playerInfo":{"nickname":"Hidden"}}`, enka.ErrProfilePrivate},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, c, waits := newStandIn(t, []byte(tc.body))
+			if tc.status != http.StatusOK {
+				s.fail = func(call int32, w http.ResponseWriter) bool {
+					w.WriteHeader(tc.status)
+					return true
+				}
+			}
+			_, _, err := c.FetchRaw(context.Background(), "700000001", true)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if len(*waits) != 0 {
+				t.Errorf("client errors must not be retried, waited %v", *waits)
+			}
+		})
+	}
+}
+
+func TestFetchRaw_BuildsFailureIsAWarning(t *testing.T) {
+	for _, code := range []int{http.StatusForbidden, http.StatusNotFound} {
+		s, c, _ := newStandIn(t, []byte(ownedUID))
+		s.buildsCode = code
+		raw, warns, err := c.FetchRaw(context.Background(), "700000001", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw.Builds != nil {
+			t.Errorf("%d: builds must stay nil when the request failed", code)
+		}
+		if len(warns) != 1 {
+			t.Fatalf("%d: expected one warning, got %v", code, warns)
+		}
+		// Only the UID endpoint answers for the UID.
+		if errors.Is(warns[0], enka.ErrUIDNotFound) {
+			t.Errorf("%d: builds failure reported as %v", code, warns[0])
+		}
+	}
+}
+
+func TestFetchRaw_RetryWarnings(t *testing.T) {
+	s, c, _ := newStandIn(t, []byte(ownedUID))
+	s.fail = func(call int32, w http.ResponseWriter) bool {
+		if call > 2 {
+			return false
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	}
+	var warned []error
+	c.Warn = func(err error) { warned = append(warned, err) }
+	if _, _, err := c.FetchRaw(context.Background(), "700000001", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(warned) != 2 {
+		t.Errorf("expected a warning per retry, got %v", warned)
+	}
+}
+
+func TestFetchRaw_CacheSkipsFailedBuilds(t *testing.T) {
+	s, c, _ := newStandIn(t, []byte(ownedUID))
+	c.CacheDir = t.TempDir()
+	s.buildsCode = http.StatusServiceUnavailable
+	c.MaxRetries = 0
+	if _, warns, err := c.FetchRaw(context.Background(), "700000001", true); err != nil || len(warns) != 1 {
+		t.Fatalf("err=%v warns=%v", err, warns)
+	}
+
+	// The entry serves requests without builds, but a request with builds fetches again.
+	raw, _, err := c.FetchRaw(context.Background(), "700000001", false)
+	if err != nil || !raw.Cached {
+		t.Fatalf("without builds: cached=%v err=%v", raw.Cached, err)
+	}
+	s.buildsCode = http.StatusOK
+	raw, _, err = c.FetchRaw(context.Background(), "700000001", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Cached || raw.Builds == nil {
+		t.Errorf("with builds: cached=%v builds=%v", raw.Cached, raw.Builds != nil)
+	}
+	if s.uidCalls.Load() != 2 || s.buildsCalls.Load() != 2 {
+		t.Errorf("got %d uid / %d builds calls", s.uidCalls.Load(), s.buildsCalls.Load())
+	}
+}
+
+func TestFetchRaw_CacheHonoursTTL(t *testing.T) {
+	s, c, _ := newStandIn(t, []byte(ownedUID))
+	c.CacheDir = t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		raw, _, err := c.FetchRaw(context.Background(), "700000001", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw.Cached != (i == 1) || raw.Builds == nil {
+			t.Errorf("fetch %d: cached=%v builds=%v", i, raw.Cached, raw.Builds != nil)
+		}
+	}
+	if s.uidCalls.Load() != 1 || s.buildsCalls.Load() != 1 {
+		t.Errorf("second fetch must come from the cache, got %d uid / %d builds calls", s.uidCalls.Load(), s.buildsCalls.Load())
+	}
+
+	// A response with ttl 0 is never served from the cache.
+	s.uidBody = []byte(`{"uid":"700000002","ttl":0,"avatarInfoList":[{"avatarId":10000096}]}`)
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.FetchRaw(context.Background(), "700000002", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.uidCalls.Load() != 3 {
+		t.Errorf("responses with ttl 0 must not be cached, got %d uid calls", s.uidCalls.Load())
+	}
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/engine"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/enka"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/good"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// TestGolden converts every saved response in testdata/enka (<case>_uid.json with an optional
+// <case>_builds.json, as written by -save-raw) using the fixture engine data in testdata/engine
+// and compares the rendered config with testdata/golden/<case>.txt.
+// Run `go test ./internal/tests -run Golden -update` to accept new output.
+func TestGolden(t *testing.T) {
+	data, err := engine.LoadData(filepath.Join("testdata", "engine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := loadGoodKeys(t)
+	uidFiles, err := filepath.Glob(filepath.Join("testdata", "enka", "*_uid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uidFiles) == 0 {
+		t.Fatal("no golden cases in testdata/enka")
+	}
+
+	for _, uidPath := range uidFiles {
+		name := strings.TrimSuffix(filepath.Base(uidPath), "_uid.json")
+		t.Run(name, func(t *testing.T) {
+			buildsPath := strings.TrimSuffix(uidPath, "_uid.json") + "_builds.json"
+			if _, err := os.Stat(buildsPath); err != nil {
+				buildsPath = ""
+			}
+			raw, err := enka.LoadRaw(uidPath, buildsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			avatars, _, err := enka.ParseAvatars(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chars, warnings, skipped := simcfg.ConvertAvatarsToSimChars(avatars, data)
+			var head enka.UIDResponse
+			if err := json.Unmarshal(raw.UID, &head); err != nil {
+				t.Fatal(err)
+			}
+			for i := range chars {
+				chars[i].UID = head.UID
+			}
+			checkGolden(t, name+".txt", renderWithDiagnostics(chars, warnings, skipped))
+
+			db, _ := simcfg.BuildGOOD(chars, keys, "golden")
+			b, err := json.MarshalIndent(db, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".good.json", string(b)+"\n")
+		})
+	}
+}
+
+// TestGoldenGOOD converts every GOOD export in testdata/good and compares the rendered config
+// with testdata/golden/good_<case>.txt.
+func TestGoldenGOOD(t *testing.T) {
+	data, err := engine.LoadData(filepath.Join("testdata", "engine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "good", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden cases in testdata/good")
+	}
+
+	for _, path := range files {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			db, err := good.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chars, warnings, skipped := simcfg.ConvertGOODToSimChars(db, data)
+			checkGolden(t, "good_"+name+".txt", renderWithDiagnostics(chars, warnings, skipped))
+		})
+	}
+}
+
+func renderWithDiagnostics(chars []simcfg.SimChar, warnings, skipped []error) string {
+	var b strings.Builder
+	b.WriteString(simcfg.RenderRoster(chars))
+	for _, w := range warnings {
+		b.WriteString("# warning: " + w.Error() + "\n")
+	}
+	for _, s := range skipped {
+		b.WriteString("# skipped: " + s.Error() + "\n")
+	}
+	return b.String()
+}
+
+// loadGoodKeys reads the repository's data/good_keys.yaml.
+func loadGoodKeys(t *testing.T) good.Keys {
+	t.Helper()
+	keys, err := good.LoadKeys(filepath.Join("..", "..", "..", "..", "data", "good_keys.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func checkGolden(t *testing.T, file string, got string) {
+	t.Helper()
+	goldenPath := filepath.Join("testdata", "golden", file)
+	if *update {
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n--- got ---\n%s\n--- want ---\n%s", goldenPath, got, want)
+	}
+}
+
+// TestBuildGOOD_RoundTrip exports the GOOD fixture back to GOOD and checks that equipped items keep
+// their owner and per-piece stats.
+func TestBuildGOOD_RoundTrip(t *testing.T) {
+	data, err := engine.LoadData(filepath.Join("testdata", "engine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := good.Load(filepath.Join("testdata", "good", "inventory.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chars, _, _ := simcfg.ConvertGOODToSimChars(in, data)
+	out, warnings := simcfg.BuildGOOD(chars, loadGoodKeys(t), "test")
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	wantChars := []string{"Arlecchino", "KaedeharaKazuha", "TravelerAnemo"}
+	if len(out.Characters) != len(wantChars) {
+		t.Fatalf("expected %d characters, got %d", len(wantChars), len(out.Characters))
+	}
+	for i, c := range out.Characters {
+		if c.Key != wantChars[i] {
+			t.Errorf("character %d: key %s, want %s", i, c.Key, wantChars[i])
+		}
+	}
+
+	equipped := func(db good.Database) map[string]good.Artifact {
+		m := map[string]good.Artifact{}
+		for _, a := range db.Artifacts {
+			if a.Location != "" {
+				m[a.Location+"/"+a.SlotKey] = a
+			}
+		}
+		return m
+	}
+	want, got := equipped(in), equipped(out)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d equipped artifacts, got %d", len(want), len(got))
+	}
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			t.Errorf("missing artifact %s", k)
+			continue
+		}
+		if g.SetKey != w.SetKey || g.MainStatKey != w.MainStatKey || g.Level != w.Level || len(g.Substats) != len(w.Substats) {
+			t.Errorf("artifact %s: got %+v, want %+v", k, g, w)
+			continue
+		}
+		for i := range w.Substats {
+			if g.Substats[i] != w.Substats[i] {
+				t.Errorf("artifact %s substat %d: got %+v, want %+v", k, i, g.Substats[i], w.Substats[i])
+			}
+		}
+	}
+}
+
+func TestParseAvatars_BuildsFollowShowcase(t *testing.T) {
+	raw, err := enka.LoadRaw(filepath.Join("testdata", "enka", "showcase_uid.json"), filepath.Join("testdata", "enka", "showcase_builds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	avatars, profile, err := enka.ParseAvatars(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile != "Golden Test" {
+		t.Errorf("profile = %q, want Golden Test", profile)
+	}
+	// 2 showcase avatars + 1 non-live build; the live build duplicates the showcase and is skipped.
+	if len(avatars) != 3 {
+		t.Fatalf("expected 3 avatars, got %d", len(avatars))
+	}
+	if avatars[2].Name == nil || *avatars[2].Name != "C0 deathmatch" {
+		t.Errorf("expected the build name to be kept, got %v", avatars[2].Name)
+	}
+}
+
+func TestSaveRaw_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	raw := enka.RawResponses{UID: []byte(`{"uid":"700000001"}`), Builds: []byte(`{}`)}
+	paths, err := enka.SaveRaw(dir, "700000001", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || !strings.HasSuffix(paths[0], "_700000001_uid.json") || !strings.HasSuffix(paths[1], "_700000001_builds.json") {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	loaded, err := enka.LoadRaw(paths[0], paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(loaded.UID) != string(raw.UID) || string(loaded.Builds) != string(raw.Builds) {
+		t.Errorf("round trip changed the responses: %+v", loaded)
+	}
+}
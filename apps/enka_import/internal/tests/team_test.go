@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/engine"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/enka"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/simcfg"
+	"github.com/genshinsim/gcsim/apps/enka_import/internal/team"
+)
+
+func loadShowcaseChars(t *testing.T) []simcfg.SimChar {
+	t.Helper()
+	data, err := engine.LoadData(filepath.Join("testdata", "engine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := enka.LoadRaw(filepath.Join("testdata", "enka", "showcase_uid.json"), filepath.Join("testdata", "enka", "showcase_builds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	avatars, _, err := enka.ParseAvatars(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chars, _, _ := simcfg.ConvertAvatarsToSimChars(avatars, data)
+	return chars
+}
+
+func TestResolveMembers_KeyAndBuildName(t *testing.T) {
+	chars := loadShowcaseChars(t)
+
+	got, err := team.ResolveMembers(chars, []string{"arlecchino"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].BuildName != "" || got[0].Cons != 1 {
+		t.Errorf("key should pick the showcase character, got build %q C%d", got[0].BuildName, got[0].Cons)
+	}
+
+	got, err = team.ResolveMembers(chars, []string{" c0 DEATHMATCH "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].BuildName != "C0 deathmatch" || got[0].Cons != 0 {
+		t.Errorf("build name should pick the build, got %q C%d", got[0].BuildName, got[0].Cons)
+	}
+}
+
+func TestResolveMembers_Errors(t *testing.T) {
+	chars := loadShowcaseChars(t)
+	cases := map[string][]string{
+		"unknown":   {"furina"},
+		"duplicate": {"arlecchino", "C0 deathmatch"},
+		"too many":  {"a", "b", "c", "d", "e"},
+		"empty":     nil,
+	}
+	for name, members := range cases {
+		if _, err := team.ResolveMembers(chars, members); err == nil {
+			t.Errorf("%s: expected an error for %v", name, members)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	members := []simcfg.SimChar{
+		{Name: "arlecchino", Level: 90, MaxLevel: 90, Cons: 1},
+		{Name: "bennett", Level: 90, MaxLevel: 90, Cons: 6},
+	}
+	blocks := simcfg.RenderSimConfig(members)
+
+	got, err := team.Render("{{team}}\noptions iteration=100;\nactive {{char1}};\n{{ char2 }} burst;\n", members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := blocks + "\noptions iteration=100;\nactive arlecchino;\nbennett burst;\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without {{team}} the character blocks go first.
+	got, err = team.Render("active {{char1}};\n", members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, blocks) || !strings.HasSuffix(got, "active arlecchino;\n") {
+		t.Errorf("expected blocks before the template, got:\n%s", got)
+	}
+
+	if _, err := team.Render("{{team}}\n{{char3}} skill;\n", members); err == nil {
+		t.Error("expected an error for {{char3}} in a two-member team")
+	}
+}
+
+func TestDryRunConfig(t *testing.T) {
+	got := engine.DryRunConfig("options iteration=1000 duration=90 swap_delay=12;\n")
+	if got != "options iteration=1 duration=90 swap_delay=12;\n" {
+		t.Errorf("iteration not forced: %q", got)
+	}
+	// Only the options statement is touched.
+	got = engine.DryRunConfig("bennett add stats iteration=5;\noptions duration=90 swap_delay=12;\n")
+	if got != "bennett add stats iteration=5;\noptions iteration=1 duration=90 swap_delay=12;\n" {
+		t.Errorf("iteration not added to options: %q", got)
+	}
+	got = engine.DryRunConfig("# iteration=1000\noptions\n  duration=90\n  iteration=500;\n")
+	if got != "# iteration=1000\noptions\n  duration=90\n  iteration=1;\n" {
+		t.Errorf("multi-line options: %q", got)
+	}
+	got = engine.DryRunConfig("active bennett;\n")
+	if !strings.HasPrefix(got, "options iteration=1;\n") {
+		t.Errorf("iteration option not added: %q", got)
+	}
+}
@@ -2,9 +2,11 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
@@ -49,6 +51,9 @@ func RunWithOptions(opts Options) int {
 func run(appRoot string, opts Options) error {
 	totalStart := time.Now()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	configPath := filepath.Join(appRoot, "input", "talent_comparator", "config.txt")
 	yamlPath := filepath.Join(appRoot, "input", "talent_comparator", "talent_config.yaml")
 	if opts.UseExamples {
@@ -71,9 +76,9 @@ func run(appRoot string, opts Options) error {
 		return fmt.Errorf("parse talent_config.yaml: %w", err)
 	}
 
-	character := strings.TrimSpace(cfg.Char)
-	if character == "" {
-		return fmt.Errorf("talent_config.yaml: char is required")
+	chars, err := resolveChars(cfg)
+	if err != nil {
+		return err
 	}
 	name := strings.TrimSpace(cfg.Name)
 	if name == "" {
@@ -85,6 +90,74 @@ func run(appRoot string, opts Options) error {
 		return err
 	}
 
+	// All swept characters share one team baseline so their results are comparable.
+	teamBaseConfig := configStr
+	for _, ch := range chars {
+		teamBaseConfig, err = config.SetTalents(teamBaseConfig, ch, baseline.NA, baseline.E, baseline.Q)
+		if err != nil {
+			return err
+		}
+	}
+
+	// ---- Resume: import same-day results -----------------------------------
+
+	previous := previousResults{}
+	basePath := ""
+	if !cfg.IgnoreExistingResults {
+		if cfg.ImportPath != "" {
+			basePath = cfg.ImportPath
+			fmt.Printf("Importing results from: %s\n", basePath)
+		} else {
+			todayPath, err := output.ResultPath(appRoot, strings.Join(chars, "-"), name)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(todayPath); err == nil {
+				basePath = todayPath
+				fmt.Printf("Found existing results: %s\n", filepath.Base(basePath))
+			}
+		}
+		if basePath != "" {
+			imported, err := output.ImportResultsXLSX(basePath, chars)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: could not import existing results (%v); starting fresh\n", err)
+				basePath = ""
+			} else {
+				previous = imported
+			}
+		}
+	}
+
+	patch := func(character string, t domain.TalentLevels) (string, error) {
+		return config.SetTalents(teamBaseConfig, character, t.NA, t.E, t.Q)
+	}
+	baselineHash := configHash(teamBaseConfig)
+
+	// Count runs that cannot be reused so progress and ETA reflect real work.
+	totalRuns := 0
+	if _, ok := previous.lookupBaseline(chars, baselineHash); !ok {
+		totalRuns++
+	}
+	for _, character := range chars {
+		for _, sw := range sweeps {
+			for _, t := range sw.Levels {
+				if t == baseline {
+					continue
+				}
+				patched, err := patch(character, t)
+				if err != nil {
+					return err
+				}
+				if _, ok := previous.lookup(character, t, configHash(patched)); !ok {
+					totalRuns++
+				}
+			}
+		}
+	}
+	if reused := 1 + len(chars)*countSweepRuns() - totalRuns; reused > 0 {
+		fmt.Printf("Already computed: %d, remaining: %d\n", reused, totalRuns)
+	}
+
 	workDir, err := ensureWorkDir(appRoot)
 	if err != nil {
 		return err
@@ -96,107 +169,136 @@ func run(appRoot string, opts Options) error {
 		OptimizeSubstats: cfg.OptimizeSubstats == nil || *cfg.OptimizeSubstats,
 	}
 
-	baseline := domain.TalentLevels{NA: 6, E: 6, Q: 6}
-	startProgress := time.Now()
-	baselineRes, simElapsed, err := runOnce(context.Background(), runner, configStr, tempConfig, character, baseline)
-	if err != nil {
-		return err
-	}
+	// ---- Run simulations ---------------------------------------------------
 
-	totalRuns := 5 + 4 + 4 + 4
-	completed := 1
+	var simElapsed time.Duration
+	var engineFailures []string
+	canceled := false
+	completed := 0
+	startProgress := time.Now()
 	lastProgressPrint := time.Time{}
-	maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
-
-	buildRow := func(t domain.TalentLevels, teamDps int, charDps int, simConfig string) output.Row {
-		teamPct := pctLabel(teamDps, baselineRes.TeamDps, t == baseline)
-		charPct := pctLabel(charDps, baselineRes.CharDps, t == baseline)
-		return output.Row{
-			Label:        t.String(),
-			TeamDps:      teamDps,
-			TeamPctLabel: teamPct,
-			CharDps:      charDps,
-			CharPctLabel: charPct,
-			SimConfig:    simConfig,
-		}
-	}
-
-	mainTalents := []domain.TalentLevels{{NA: 1, E: 1, Q: 1}, baseline, {NA: 8, E: 8, Q: 8}, {NA: 9, E: 9, Q: 9}, {NA: 10, E: 10, Q: 10}}
-	autoTalents := []domain.TalentLevels{{NA: 7, E: 6, Q: 6}, {NA: 8, E: 6, Q: 6}, {NA: 9, E: 6, Q: 6}, {NA: 10, E: 6, Q: 6}}
-	eTalents := []domain.TalentLevels{{NA: 6, E: 7, Q: 6}, {NA: 6, E: 8, Q: 6}, {NA: 6, E: 9, Q: 6}, {NA: 6, E: 10, Q: 6}}
-	qTalents := []domain.TalentLevels{{NA: 6, E: 6, Q: 7}, {NA: 6, E: 6, Q: 8}, {NA: 6, E: 6, Q: 9}, {NA: 6, E: 6, Q: 10}}
 
-	sections := make([]output.Section, 0, 4)
-
-	// Main block
-	{
-		rows := make([]output.Row, 0, len(mainTalents))
-		for _, t := range mainTalents {
-			if t == baseline {
-				rows = append(rows, buildRow(t, baselineRes.TeamDps, baselineRes.CharDps, baselineRes.Config))
-				continue
+	// step reuses an imported result or runs the engine. After cancellation only imported
+	// results are returned (ok=false otherwise), so the partial export keeps them.
+	step := func(character string, t domain.TalentLevels, patched string, reuse func(string) (runDps, bool)) (runDps, bool) {
+		hash := configHash(patched)
+		if res, ok := reuse(hash); ok {
+			return res, true
+		}
+		if canceled {
+			return runDps{}, false
+		}
+		res, elapsed, err := runOnce(ctx, runner, patched, tempConfig, chars)
+		simElapsed += elapsed
+		if err != nil {
+			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+				canceled = true
+				return runDps{}, false
 			}
-			res, elapsed, err := runOnce(context.Background(), runner, configStr, tempConfig, character, t)
-			simElapsed += elapsed
-			if err != nil {
-				return err
+			errSummary := lastNonEmptyLine(err.Error())
+			fmt.Fprintf(os.Stderr, "WARN: engine error for %s %s: %s\n", character, t, errSummary)
+			engineFailures = append(engineFailures, fmt.Sprintf("%s %s: %s", character, t, errSummary))
+			res = runDps{Err: errSummary}
+		}
+		res.Hash = hash
+		completed++
+		maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
+		return res, true
+	}
+
+	baselineRes, baselineOk := step(chars[0], baseline, teamBaseConfig, func(hash string) (runDps, bool) {
+		return previous.lookupBaseline(chars, hash)
+	})
+
+	charResults := make([]output.CharResults, 0, len(chars))
+	var steps []domain.StepResult
+	for _, character := range chars {
+		buildRow := func(t domain.TalentLevels, res runDps) output.Row {
+			if res.Err != "" {
+				return output.Row{Label: t.String(), ConfigHash: res.Hash, Error: res.Err}
+			}
+			return output.Row{
+				Label:        t.String(),
+				TeamDps:      res.TeamDps,
+				TeamPctLabel: pctLabel(res.TeamDps, baselineRes.TeamDps, t == baseline),
+				CharDps:      res.CharDps[character],
+				CharPctLabel: pctLabel(res.CharDps[character], baselineRes.CharDps[character], t == baseline),
+				SimConfig:    res.Config,
+				ConfigHash:   res.Hash,
 			}
-			completed++
-			maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
-			rows = append(rows, buildRow(t, res.TeamDps, res.CharDps, res.Config))
 		}
-		sections = append(sections, output.Section{Rows: rows})
-	}
 
-	// Auto leveling
-	{
-		rows := make([]output.Row, 0, len(autoTalents))
-		for _, t := range autoTalents {
-			res, elapsed, err := runOnce(context.Background(), runner, configStr, tempConfig, character, t)
-			simElapsed += elapsed
-			if err != nil {
-				return err
+		sections := make([]output.Section, 0, len(sweeps))
+		for _, sw := range sweeps {
+			rows := make([]output.Row, 0, len(sw.Levels))
+			for _, t := range sw.Levels {
+				if t == baseline {
+					if baselineOk {
+						rows = append(rows, buildRow(t, baselineRes))
+					}
+					continue
+				}
+				patched, err := patch(character, t)
+				if err != nil {
+					return err
+				}
+				res, ok := step(character, t, patched, func(hash string) (runDps, bool) {
+					return previous.lookup(character, t, hash)
+				})
+				if !ok {
+					continue
+				}
+				rows = append(rows, buildRow(t, res))
+				if sw.Talent != "" && res.Err == "" {
+					steps = append(steps, domain.StepResult{
+						Char:    character,
+						Talent:  sw.Talent,
+						Levels:  t,
+						TeamDps: res.TeamDps,
+						CharDps: res.CharDps[character],
+					})
+				}
+			}
+			if len(rows) > 0 {
+				sections = append(sections, output.Section{Title: sw.Title, Rows: rows})
 			}
-			completed++
-			maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
-			rows = append(rows, buildRow(t, res.TeamDps, res.CharDps, res.Config))
 		}
-		sections = append(sections, output.Section{Title: "Прокачка автух", Rows: rows})
+		charResults = append(charResults, output.CharResults{Char: character, Sections: sections})
 	}
 
-	// E leveling
-	{
-		rows := make([]output.Row, 0, len(eTalents))
-		for _, t := range eTalents {
-			res, elapsed, err := runOnce(context.Background(), runner, configStr, tempConfig, character, t)
-			simElapsed += elapsed
-			if err != nil {
-				return err
-			}
-			completed++
-			maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
-			rows = append(rows, buildRow(t, res.TeamDps, res.CharDps, res.Config))
+	if canceled {
+		fmt.Fprintln(os.Stderr, "Interrupted: exporting computed results...")
+	}
+	if len(engineFailures) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d engine error(s) (shown as errors in the table):\n", len(engineFailures))
+		for _, f := range engineFailures {
+			fmt.Fprintf(os.Stderr, "  - %s\n", f)
 		}
-		sections = append(sections, output.Section{Title: "Прокачка е", Rows: rows})
+		fmt.Fprintln(os.Stderr)
 	}
 
-	// Q leveling
-	{
-		rows := make([]output.Row, 0, len(qTalents))
-		for _, t := range qTalents {
-			res, elapsed, err := runOnce(context.Background(), runner, configStr, tempConfig, character, t)
-			simElapsed += elapsed
-			if err != nil {
-				return err
-			}
-			completed++
-			maybePrintProgress(completed, totalRuns, startProgress, &lastProgressPrint)
-			rows = append(rows, buildRow(t, res.TeamDps, res.CharDps, res.Config))
+	hasRows := false
+	for _, c := range charResults {
+		if len(c.Sections) > 0 {
+			hasRows = true
+			break
 		}
-		sections = append(sections, output.Section{Title: "Прокачка q", Rows: rows})
+	}
+	if !hasRows {
+		fmt.Fprintln(os.Stderr, "No results to export (interrupted before any simulation completed).")
+		return nil
 	}
 
-	xlsxPath, err := output.ExportXLSX(appRoot, character, name, sections)
+	var xlsxPath string
+	if len(chars) == 1 {
+		xlsxPath, err = output.ExportXLSXToPath(appRoot, chars[0], name, charResults[0].Sections, basePath)
+	} else {
+		var ranking []output.RankRow
+		if baselineOk && baselineRes.Err == "" {
+			ranking = buildRankRows(baselineRes, steps)
+		}
+		xlsxPath, err = output.ExportTeamXLSXToPath(appRoot, name, charResults, ranking, basePath)
+	}
 	if err != nil {
 		return err
 	}
@@ -215,18 +317,62 @@ func run(appRoot string, opts Options) error {
 	return nil
 }
 
+// resolveChars merges char and chars from talent_config.yaml, keeping order and rejecting duplicates.
+func resolveChars(cfg domain.Config) ([]string, error) {
+	entries := make([]string, 0, 1+len(cfg.Chars))
+	if ch := strings.TrimSpace(cfg.Char); ch != "" {
+		entries = append(entries, ch)
+	}
+	for _, ch := range cfg.Chars {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			entries = append(entries, ch)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("talent_config.yaml: char or chars is required")
+	}
+	seen := make(map[string]struct{}, len(entries))
+	for _, ch := range entries {
+		if _, ok := seen[ch]; ok {
+			return nil, fmt.Errorf("talent_config.yaml: duplicate char %q", ch)
+		}
+		seen[ch] = struct{}{}
+	}
+	return entries, nil
+}
+
+func buildRankRows(baselineRes runDps, steps []domain.StepResult) []output.RankRow {
+	ranked := domain.RankTalentSteps(baselineRes.TeamDps, steps)
+	rows := make([]output.RankRow, 0, len(ranked))
+	for _, s := range ranked {
+		rows = append(rows, output.RankRow{
+			Char:         s.Char,
+			Talent:       string(s.Talent),
+			Label:        s.Levels.String(),
+			TeamDps:      s.TeamDps,
+			TeamGain:     s.TeamGain,
+			TeamPctLabel: pctLabel(s.TeamDps, baselineRes.TeamDps, false),
+			StepGain:     s.StepGain,
+			CharDps:      s.CharDps,
+			CharPctLabel: pctLabel(s.CharDps, baselineRes.CharDps[s.Char], false),
+		})
+	}
+	return rows
+}
+
 type runDps struct {
 	TeamDps int
-	CharDps int
+	CharDps map[string]int
 	Config  string
+	// Hash is the configHash of the input config.
+	Hash string
+	// Err is the summarized engine error of a failed run.
+	Err string
 }
 
-func runOnce(ctx context.Context, runner sim.SimulationRunner, baseConfig string, tempConfigPath string, character string, talents domain.TalentLevels) (runDps, time.Duration, error) {
-	newConfig, err := config.SetTalents(baseConfig, character, talents.NA, talents.E, talents.Q)
-	if err != nil {
-		return runDps{}, 0, err
-	}
-	if err := writeTempConfig(tempConfigPath, newConfig); err != nil {
+// runOnce runs the engine on config and reports team DPS plus character DPS of every tracked character.
+func runOnce(ctx context.Context, runner sim.SimulationRunner, config string, tempConfigPath string, tracked []string) (runDps, time.Duration, error) {
+	if err := writeTempConfig(tempConfigPath, config); err != nil {
 		return runDps{}, 0, err
 	}
 
@@ -238,9 +384,13 @@ func runOnce(ctx context.Context, runner sim.SimulationRunner, baseConfig string
 	}
 
 	teamDps := int(math.Round(*res.Statistics.DPS.Mean))
-	charDps, err := extractCharacterDps(res, character)
-	if err != nil {
-		return runDps{}, elapsed, err
+	charDps := make(map[string]int, len(tracked))
+	for _, ch := range tracked {
+		dps, err := extractCharacterDps(res, ch)
+		if err != nil {
+			return runDps{}, elapsed, err
+		}
+		charDps[ch] = dps
 	}
 	return runDps{TeamDps: teamDps, CharDps: charDps, Config: res.ConfigFile}, elapsed, nil
 }
@@ -285,6 +435,16 @@ func maybePrintProgress(completed int, total int, start time.Time, lastPrint *ti
 	fmt.Printf("Progress: %d/%d (%.1f%%), ETA %s\n", completed, total, percent, etaStr)
 }
 
+func lastNonEmptyLine(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return s
+}
+
 func pctLabel(value int, baseline int, isBaseline bool) string {
 	if isBaseline {
 		return "100%"
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/genshinsim/gcsim/apps/talent_comparator/internal/domain"
+	"github.com/genshinsim/gcsim/apps/talent_comparator/internal/output"
+)
+
+// configHash fingerprints the exact config passed to the engine.
+func configHash(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:8])
+}
+
+// previousResults holds rows imported from an earlier export, keyed by character and talent label.
+type previousResults map[string]map[string]output.Row
+
+// lookup returns the imported result of character at talents if it was computed from the same config.
+func (p previousResults) lookup(character string, talents domain.TalentLevels, hash string) (runDps, bool) {
+	row, ok := p[character][talents.String()]
+	if !ok || row.ConfigHash != hash {
+		return runDps{}, false
+	}
+	return runDps{
+		TeamDps: row.TeamDps,
+		CharDps: map[string]int{character: row.CharDps},
+		Config:  row.SimConfig,
+		Hash:    hash,
+	}, true
+}
+
+// lookupBaseline returns the shared baseline if every tracked character has a matching imported row.
+func (p previousResults) lookupBaseline(chars []string, hash string) (runDps, bool) {
+	var res runDps
+	for i, ch := range chars {
+		r, ok := p.lookup(ch, baseline, hash)
+		if !ok {
+			return runDps{}, false
+		}
+		if i == 0 {
+			res = r
+			continue
+		}
+		res.CharDps[ch] = r.CharDps[ch]
+	}
+	return res, true
+}
@@ -0,0 +1,48 @@
+package app
+
+import "github.com/genshinsim/gcsim/apps/talent_comparator/internal/domain"
+
+// baseline is the shared team baseline: every swept character is set to these levels.
+var baseline = domain.TalentLevels{NA: 6, E: 6, Q: 6}
+
+// sweep is one block of talent variations simulated per character.
+type sweep struct {
+	Title string
+	// Talent is the single talent leveled in this block; empty for the uniform main block.
+	Talent domain.Talent
+	Levels []domain.TalentLevels
+}
+
+var sweeps = []sweep{
+	{
+		Levels: []domain.TalentLevels{{NA: 1, E: 1, Q: 1}, baseline, {NA: 8, E: 8, Q: 8}, {NA: 9, E: 9, Q: 9}, {NA: 10, E: 10, Q: 10}},
+	},
+	{
+		Title:  "Прокачка автух",
+		Talent: domain.TalentNA,
+		Levels: []domain.TalentLevels{{NA: 7, E: 6, Q: 6}, {NA: 8, E: 6, Q: 6}, {NA: 9, E: 6, Q: 6}, {NA: 10, E: 6, Q: 6}},
+	},
+	{
+		Title:  "Прокачка е",
+		Talent: domain.TalentE,
+		Levels: []domain.TalentLevels{{NA: 6, E: 7, Q: 6}, {NA: 6, E: 8, Q: 6}, {NA: 6, E: 9, Q: 6}, {NA: 6, E: 10, Q: 6}},
+	},
+	{
+		Title:  "Прокачка q",
+		Talent: domain.TalentQ,
+		Levels: []domain.TalentLevels{{NA: 6, E: 6, Q: 7}, {NA: 6, E: 6, Q: 8}, {NA: 6, E: 6, Q: 9}, {NA: 6, E: 6, Q: 10}},
+	},
+}
+
+// countSweepRuns returns the number of engine runs per character (the baseline is shared).
+func countSweepRuns() int {
+	n := 0
+	for _, sw := range sweeps {
+		for _, t := range sw.Levels {
+			if t != baseline {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "sort"
+
+// StepResult is one simulated talent step of a single character:
+// only one talent differs from the shared team baseline.
+type StepResult struct {
+	Char    string
+	Talent  Talent
+	Levels  TalentLevels
+	TeamDps int
+	CharDps int
+}
+
+// RankedStep is a StepResult with its gains over the shared baseline.
+type RankedStep struct {
+	StepResult
+	// TeamGain is the team DPS gain over the shared team baseline.
+	TeamGain int
+	// StepGain is the team DPS gain over the previous level of the same talent
+	// (the baseline for the first step).
+	StepGain int
+}
+
+// RankTalentSteps orders talent steps of all characters by team DPS gain over the shared baseline.
+// Ties are broken by character, talent and level so the order is deterministic.
+func RankTalentSteps(baselineTeamDps int, steps []StepResult) []RankedStep {
+	ranked := make([]RankedStep, len(steps))
+	for i, s := range steps {
+		ranked[i] = RankedStep{StepResult: s, TeamGain: s.TeamDps - baselineTeamDps}
+	}
+
+	type group struct {
+		char   string
+		talent Talent
+	}
+	byGroup := make(map[group][]int)
+	for i, s := range steps {
+		g := group{char: s.Char, talent: s.Talent}
+		byGroup[g] = append(byGroup[g], i)
+	}
+	for _, idxs := range byGroup {
+		sort.SliceStable(idxs, func(a, b int) bool {
+			return steps[idxs[a]].Levels.Level(steps[idxs[a]].Talent) < steps[idxs[b]].Levels.Level(steps[idxs[b]].Talent)
+		})
+		prev := baselineTeamDps
+		for _, i := range idxs {
+			ranked[i].StepGain = steps[i].TeamDps - prev
+			prev = steps[i].TeamDps
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.TeamGain != b.TeamGain {
+			return a.TeamGain > b.TeamGain
+		}
+		if a.Char != b.Char {
+			return a.Char < b.Char
+		}
+		if a.Talent != b.Talent {
+			return a.Talent < b.Talent
+		}
+		return a.Levels.Level(a.Talent) < b.Levels.Level(b.Talent)
+	})
+	return ranked
+}
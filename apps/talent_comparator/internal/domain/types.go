@@ -11,11 +11,17 @@ type Config struct {
 	EnginePath string `yaml:"engine_path"`
 
 	Char string `yaml:"char"`
-	Name string `yaml:"name"`
+	// Chars lists several team members whose talents are swept against one shared team baseline.
+	// May be combined with Char (Char goes first); duplicates are rejected.
+	Chars []string `yaml:"chars"`
+	Name  string   `yaml:"name"`
 
 	// OptimizeSubstats controls whether -substatOptimFull is passed to the engine.
 	// Default (nil or true): optimization enabled.
 	OptimizeSubstats *bool `yaml:"optimize_substats"`
+
+	IgnoreExistingResults bool   `yaml:"ignore_existing_results"`
+	ImportPath            string `yaml:"import_path"`
 }
 
 func (c *Config) UnmarshalYAML(value *yaml.Node) error {
@@ -28,6 +34,15 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+// Talent identifies one of the three leveled talents.
+type Talent string
+
+const (
+	TalentNA Talent = "NA"
+	TalentE  Talent = "E"
+	TalentQ  Talent = "Q"
+)
+
 type TalentLevels struct {
 	NA int
 	E  int
@@ -37,3 +52,17 @@ type TalentLevels struct {
 func (t TalentLevels) String() string {
 	return fmt.Sprintf("%d-%d-%d", t.NA, t.E, t.Q)
 }
+
+// Level returns the level of the given talent.
+func (t TalentLevels) Level(talent Talent) int {
+	switch talent {
+	case TalentNA:
+		return t.NA
+	case TalentE:
+		return t.E
+	case TalentQ:
+		return t.Q
+	default:
+		return 0
+	}
+}
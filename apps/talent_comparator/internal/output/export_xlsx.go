@@ -17,21 +17,52 @@ type Row struct {
 	CharDps      int
 	CharPctLabel string
 	SimConfig    string
+	// ConfigHash fingerprints the input config of the run; a same-day re-run reuses the row
+	// only while the hash still matches.
+	ConfigHash string
+	// Error is set when the engine run failed; DPS columns are left empty.
+	Error string
 }
 
+// errorLabel marks failed rows in the percent columns; ImportResultsXLSX skips such rows.
+const errorLabel = "error"
+
 type Section struct {
 	Title string
 	Rows  []Row
 }
 
+// CharResults holds the detail sections of one character in a multi-character run.
+type CharResults struct {
+	Char     string
+	Sections []Section
+}
+
+// RankRow is one talent step in the combined cross-character ranking.
+type RankRow struct {
+	Char         string
+	Talent       string
+	Label        string
+	TeamDps      int
+	TeamGain     int
+	TeamPctLabel string
+	StepGain     int
+	CharDps      int
+	CharPctLabel string
+}
+
 func ExportXLSX(appRoot string, character string, name string, sections []Section) (string, error) {
-	outDir := filepath.Join(appRoot, "output", "talent_comparator")
-	if err := os.MkdirAll(outDir, 0o755); err != nil {
-		return "", err
-	}
+	return ExportXLSXToPath(appRoot, character, name, sections, "")
+}
 
-	fileBase := fmt.Sprintf("%s_%s_%s.xlsx", time.Now().Format("20060102"), sanitizeFilenamePart(character), sanitizeFilenamePart(name))
-	outPath := filepath.Join(outDir, fileBase)
+// ExportXLSXToPath is ExportXLSX writing to outPath (empty = default ResultPath).
+func ExportXLSXToPath(appRoot string, character string, name string, sections []Section, outPath string) (string, error) {
+	if outPath == "" {
+		var err error
+		if outPath, err = ResultPath(appRoot, character, name); err != nil {
+			return "", err
+		}
+	}
 
 	f := excelize.NewFile()
 	sheet := "Results"
@@ -39,6 +70,106 @@ func ExportXLSX(appRoot string, character string, name string, sections []Sectio
 	sheetWithConfig := "Results+Config"
 	_, _ = f.NewSheet(sheetWithConfig)
 
+	if err := writeResultSheets(f, sheet, sheetWithConfig, sections); err != nil {
+		return "", err
+	}
+
+	if err := f.SaveAs(outPath); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+// ExportTeamXLSX writes a multi-character run: a "Ranking" sheet with every talent step
+// across characters ordered by team DPS gain, followed by per-character detail sheets
+// in the same layout as the single-character export.
+func ExportTeamXLSX(appRoot string, name string, chars []CharResults, ranking []RankRow) (string, error) {
+	return ExportTeamXLSXToPath(appRoot, name, chars, ranking, "")
+}
+
+// ExportTeamXLSXToPath is ExportTeamXLSX writing to outPath (empty = default ResultPath).
+func ExportTeamXLSXToPath(appRoot string, name string, chars []CharResults, ranking []RankRow, outPath string) (string, error) {
+	if outPath == "" {
+		names := make([]string, 0, len(chars))
+		for _, c := range chars {
+			names = append(names, c.Char)
+		}
+		var err error
+		if outPath, err = ResultPath(appRoot, strings.Join(names, "-"), name); err != nil {
+			return "", err
+		}
+	}
+
+	f := excelize.NewFile()
+	const rankSheet = "Ranking"
+	_ = f.SetSheetName("Sheet1", rankSheet)
+	if err := writeRankingSheet(f, rankSheet, ranking); err != nil {
+		return "", err
+	}
+
+	for _, c := range chars {
+		sheet, sheetWithConfig := CharSheetNames(c.Char)
+		if _, err := f.NewSheet(sheet); err != nil {
+			return "", err
+		}
+		if _, err := f.NewSheet(sheetWithConfig); err != nil {
+			return "", err
+		}
+		if err := writeResultSheets(f, sheet, sheetWithConfig, c.Sections); err != nil {
+			return "", err
+		}
+	}
+
+	if err := f.SaveAs(outPath); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+// ResultPath returns today's output path for the given character label and run name,
+// creating output/talent_comparator if needed.
+func ResultPath(appRoot string, character string, name string) (string, error) {
+	outDir := filepath.Join(appRoot, "output", "talent_comparator")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return "", err
+	}
+	fileBase := fmt.Sprintf("%s_%s_%s.xlsx", time.Now().Format("20060102"), sanitizeFilenamePart(character), sanitizeFilenamePart(name))
+	return filepath.Join(outDir, fileBase), nil
+}
+
+func writeRankingSheet(f *excelize.File, sheet string, ranking []RankRow) error {
+	headers := []string{"#", "Перс", "Талант", "Таланты", "Team DPS", "Прирост", "Team %", "Прирост шага", "Char DPS", "Char %"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"}})
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", "J1", headerStyle); err != nil {
+		return err
+	}
+
+	for i, r := range ranking {
+		row := i + 2
+		values := []any{i + 1, r.Char, r.Talent, r.Label, r.TeamDps, r.TeamGain, r.TeamPctLabel, r.StepGain, r.CharDps, r.CharPctLabel}
+		for col, v := range values {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			f.SetCellValue(sheet, cell, v)
+		}
+	}
+
+	if err := f.SetColWidth(sheet, "A", "A", 6); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "B", "B", 16); err != nil {
+		return err
+	}
+	return f.SetColWidth(sheet, "C", "J", 14)
+}
+
+func writeResultSheets(f *excelize.File, sheet string, sheetWithConfig string, sections []Section) error {
 	// Header
 	for _, sh := range []string{sheet, sheetWithConfig} {
 		f.SetCellValue(sh, "A1", "Таланты")
@@ -48,27 +179,28 @@ func ExportXLSX(appRoot string, character string, name string, sections []Sectio
 		f.SetCellValue(sh, "E1", "Char %")
 	}
 	f.SetCellValue(sheetWithConfig, "F1", "Sim Config")
+	f.SetCellValue(sheetWithConfig, "G1", "Config Hash")
 
 	// Styles
 	headerStyle, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"}})
 	if err != nil {
-		return "", err
+		return err
 	}
 	if err := f.SetCellStyle(sheet, "A1", "E1", headerStyle); err != nil {
-		return "", err
+		return err
 	}
-	if err := f.SetCellStyle(sheetWithConfig, "A1", "F1", headerStyle); err != nil {
-		return "", err
+	if err := f.SetCellStyle(sheetWithConfig, "A1", "G1", headerStyle); err != nil {
+		return err
 	}
 
 	sectionStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	configStyle, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Vertical: "top", WrapText: true}})
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	row := 2
@@ -89,6 +221,16 @@ func ExportXLSX(appRoot string, character string, name string, sections []Sectio
 		}
 
 		for _, r := range sec.Rows {
+			if r.Error != "" {
+				for _, sh := range []string{sheet, sheetWithConfig} {
+					f.SetCellValue(sh, fmt.Sprintf("A%d", row), r.Label)
+					f.SetCellValue(sh, fmt.Sprintf("C%d", row), errorLabel)
+					f.SetCellValue(sh, fmt.Sprintf("E%d", row), errorLabel)
+				}
+				f.SetCellValue(sheetWithConfig, fmt.Sprintf("F%d", row), r.Error)
+				row++
+				continue
+			}
 			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), r.Label)
 			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), r.TeamDps)
 			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), r.TeamPctLabel)
@@ -101,24 +243,28 @@ func ExportXLSX(appRoot string, character string, name string, sections []Sectio
 			f.SetCellValue(sheetWithConfig, fmt.Sprintf("D%d", row), r.CharDps)
 			f.SetCellValue(sheetWithConfig, fmt.Sprintf("E%d", row), r.CharPctLabel)
 			f.SetCellValue(sheetWithConfig, fmt.Sprintf("F%d", row), r.SimConfig)
+			f.SetCellValue(sheetWithConfig, fmt.Sprintf("G%d", row), r.ConfigHash)
 			row++
 		}
 	}
 
 	if err := f.SetColWidth(sheet, "A", "A", 14); err != nil {
-		return "", err
+		return err
 	}
 	if err := f.SetColWidth(sheet, "B", "E", 14); err != nil {
-		return "", err
+		return err
 	}
 	if err := f.SetColWidth(sheetWithConfig, "A", "A", 14); err != nil {
-		return "", err
+		return err
 	}
 	if err := f.SetColWidth(sheetWithConfig, "B", "E", 14); err != nil {
-		return "", err
+		return err
 	}
 	if err := f.SetColWidth(sheetWithConfig, "F", "F", 90); err != nil {
-		return "", err
+		return err
+	}
+	if err := f.SetColWidth(sheetWithConfig, "G", "G", 18); err != nil {
+		return err
 	}
 
 	// Make config cells readable.
@@ -126,11 +272,21 @@ func ExportXLSX(appRoot string, character string, name string, sections []Sectio
 	if lastRow >= 2 {
 		_ = f.SetCellStyle(sheetWithConfig, "F2", fmt.Sprintf("F%d", lastRow), configStyle)
 	}
+	return nil
+}
 
-	if err := f.SaveAs(outPath); err != nil {
-		return "", err
+// CharSheetNames returns the detail sheet names of a character in a multi-character export.
+func CharSheetNames(char string) (string, string) {
+	return sheetName(char), sheetName(char + "+Config")
+}
+
+// sheetName trims s to Excel's 31-character sheet name limit.
+func sheetName(s string) string {
+	const maxLen = 31
+	if len(s) > maxLen {
+		return s[:maxLen]
 	}
-	return outPath, nil
+	return s
 }
 
 func sanitizeFilenamePart(s string) string {
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var talentLabelRe = regexp.MustCompile(`^\d+-\d+-\d+$`)
+
+// ImportResultsXLSX reads previously exported rows for resume support.
+// It returns rows keyed by character and talent label ("6-7-6"). Failed rows and rows
+// without a config hash (older exports) are skipped so they get recomputed.
+// Single-character exports are read from "Results+Config", multi-character ones
+// from each "<char>+Config" sheet.
+func ImportResultsXLSX(path string, chars []string) (map[string]map[string]Row, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx %q: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	out := make(map[string]map[string]Row, len(chars))
+	for _, ch := range chars {
+		sheet := "Results+Config"
+		if len(chars) > 1 {
+			_, sheet = CharSheetNames(ch)
+		}
+		if idx, _ := f.GetSheetIndex(sheet); idx == -1 {
+			return nil, fmt.Errorf("xlsx %q: missing sheet %q", path, sheet)
+		}
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, fmt.Errorf("read rows %s: %w", sheet, err)
+		}
+
+		byLabel := make(map[string]Row)
+		for i, row := range rows {
+			if i == 0 || len(row) < 7 {
+				continue // header, section title or row without config hash
+			}
+			label := strings.TrimSpace(row[0])
+			if !talentLabelRe.MatchString(label) || strings.TrimSpace(row[2]) == errorLabel {
+				continue
+			}
+			teamDps, err := strconv.Atoi(strings.TrimSpace(row[1]))
+			if err != nil {
+				continue
+			}
+			charDps, err := strconv.Atoi(strings.TrimSpace(row[3]))
+			if err != nil {
+				continue
+			}
+			hash := strings.TrimSpace(row[6])
+			if hash == "" {
+				continue
+			}
+			byLabel[label] = Row{
+				Label:      label,
+				TeamDps:    teamDps,
+				CharDps:    charDps,
+				SimConfig:  row[5],
+				ConfigHash: hash,
+			}
+		}
+		out[ch] = byLabel
+	}
+	return out, nil
+}
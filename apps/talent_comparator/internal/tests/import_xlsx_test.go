@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/talent_comparator/internal/output"
+)
+
+func TestImportResultsXLSX_RoundTripSkipsErrors(t *testing.T) {
+	dir := t.TempDir()
+	sections := []output.Section{
+		{Rows: []output.Row{
+			{Label: "6-6-6", TeamDps: 1000, TeamPctLabel: "100%", CharDps: 400, CharPctLabel: "100%", SimConfig: "cfg-base", ConfigHash: "aaaa"},
+		}},
+		{Title: "Прокачка е", Rows: []output.Row{
+			{Label: "6-7-6", TeamDps: 1040, TeamPctLabel: "104.0%", CharDps: 430, CharPctLabel: "107.5%", SimConfig: "cfg-e7", ConfigHash: "bbbb"},
+			{Label: "6-8-6", ConfigHash: "cccc", Error: "engine CLI failed"},
+		}},
+	}
+	path, err := output.ExportXLSXToPath(dir, "furina", "demo", sections, filepath.Join(dir, "out.xlsx"))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	imported, err := output.ImportResultsXLSX(path, []string{"furina"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	rows := imported["furina"]
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 imported rows (error row skipped), got %d: %v", len(rows), rows)
+	}
+	if r := rows["6-7-6"]; r.TeamDps != 1040 || r.CharDps != 430 || r.SimConfig != "cfg-e7" || r.ConfigHash != "bbbb" {
+		t.Errorf("unexpected 6-7-6 row: %+v", r)
+	}
+	if _, ok := rows["6-8-6"]; ok {
+		t.Errorf("failed row 6-8-6 must not be imported")
+	}
+}
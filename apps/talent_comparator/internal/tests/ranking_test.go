@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/talent_comparator/internal/domain"
+)
+
+func TestRankTalentSteps_OrdersByTeamGainAcrossChars(t *testing.T) {
+	steps := []domain.StepResult{
+		{Char: "furina", Talent: domain.TalentE, Levels: domain.TalentLevels{NA: 6, E: 7, Q: 6}, TeamDps: 1030},
+		{Char: "furina", Talent: domain.TalentE, Levels: domain.TalentLevels{NA: 6, E: 8, Q: 6}, TeamDps: 1070},
+		{Char: "neuvillette", Talent: domain.TalentNA, Levels: domain.TalentLevels{NA: 7, E: 6, Q: 6}, TeamDps: 1050},
+	}
+	ranked := domain.RankTalentSteps(1000, steps)
+	if len(ranked) != 3 {
+		t.Fatalf("expected 3 ranked steps, got %d", len(ranked))
+	}
+
+	wantOrder := []string{"6-8-6", "7-6-6", "6-7-6"}
+	for i, want := range wantOrder {
+		if got := ranked[i].Levels.String(); got != want {
+			t.Errorf("rank %d: want %s, got %s", i+1, want, got)
+		}
+	}
+
+	// Furina E8: +70 over baseline, +40 over E7.
+	if ranked[0].TeamGain != 70 || ranked[0].StepGain != 40 {
+		t.Errorf("furina E8: want gain 70/step 40, got %d/%d", ranked[0].TeamGain, ranked[0].StepGain)
+	}
+	// Neuvillette NA7 is the first step of its talent: step gain equals team gain.
+	if ranked[1].TeamGain != 50 || ranked[1].StepGain != 50 {
+		t.Errorf("neuvillette NA7: want gain 50/step 50, got %d/%d", ranked[1].TeamGain, ranked[1].StepGain)
+	}
+}
@@ -30,6 +30,9 @@ function doPost(e) {
     var headerRow = sh.getRange(1, 1, 1, sh.getLastColumn()).getValues()[0];
     var colIndex = buildColIndex_(headerRow);
     var existingKeys = loadExistingShareKeys_(sh, colIndex);
+    var update = !!req.update;
+    // Layout version of record rows (see mapIncomingRow_); clients before versioning send none.
+    var rowVersion = Number(req.rowVersion) || 1;
 
     var records = [];
     if (Array.isArray(req.records)) {
@@ -41,6 +44,7 @@ function doPost(e) {
     }
 
     var appended = 0;
+    var updated = 0;
     for (var i = 0; i < records.length; i++) {
       var r = records[i];
       // Expected: row is array in correct column order
@@ -48,7 +52,7 @@ function doPost(e) {
         continue;
       }
 
-      var mapped = mapIncomingRow_(r.row);
+      var mapped = mapIncomingRow_(r.row, rowVersion);
       if (!mapped) {
         continue;
       }
@@ -59,23 +63,53 @@ function doPost(e) {
         continue;
       }
       if (existingKeys[shareKey]) {
+        if (update) {
+          updateCells_(sh, colIndex, existingKeys[shareKey], mapped, r.row, rowVersion);
+          updated++;
+        }
         continue;
       }
 
       sh.appendRow(mapped);
-      existingKeys[shareKey] = true;
+      existingKeys[shareKey] = sh.getLastRow();
       appended++;
     }
 
     // Apply table rules: custom sort + merge UI blocks.
     sortAndMerge_(sh);
 
-    return jsonResponse_(200, { ok: true, appended: appended });
+    return jsonResponse_(200, { ok: true, appended: appended, updated: updated });
   } catch (err) {
     return jsonResponse_(500, { ok: false, error: String(err && err.stack ? err.stack : err) });
   }
 }
 
+// Columns rewritten by update requests (re-simulation against the local engine,
+// duplicate regrouping): header name, index in the incoming row and the first rowVersion
+// that has it (see mapIncomingRow_).
+var UPDATE_COLUMNS = [
+  { name: "ResimDpsMean", src: 19, since: 1 },
+  { name: "ResimDeltaPct", src: 20, since: 1 },
+  { name: "ResimStatus", src: 21, since: 1 },
+  { name: "ResimSimVersion", src: 22, since: 1 },
+  { name: "ResimAt", src: 23, since: 1 },
+  { name: "ConfigFingerprint", src: 27, since: 3 },
+  { name: "DuplicateOf", src: 28, since: 3 }
+];
+
+// Rewrites only the columns the client sent: a client of an older rowVersion (or a shorter
+// row) must not blank columns it does not know about.
+function updateCells_(sh, colIndex, rowNum, mapped, row, version) {
+  for (var i = 0; i < UPDATE_COLUMNS.length; i++) {
+    var u = UPDATE_COLUMNS[i];
+    if (version < u.since || row.length <= u.src) continue;
+    var c = colIndex[u.name];
+    if (c == null) continue;
+    sh.getRange(rowNum, c + 1).setValue(mapped[c]);
+  }
+}
+
+// Returns ShareKey -> sheet row number (1-based).
 function loadExistingShareKeys_(sh, colIndex) {
   var out = {};
   if (!colIndex) return out;
@@ -87,7 +121,7 @@ function loadExistingShareKeys_(sh, colIndex) {
     var values = sh.getRange(2, keyCol + 1, lastRow - 1, 1).getValues();
     for (var i = 0; i < values.length; i++) {
       var k = safeStr_(values[i][0]);
-      if (k) out[k] = true;
+      if (k) out[k] = i + 2;
     }
   } catch (e) {
     // ignore
@@ -95,6 +129,16 @@ function loadExistingShareKeys_(sh, colIndex) {
   return out;
 }
 
+// Columns up to SchemaMinor; later columns were added over time.
+var BASE_HEADER_LEN = 19;
+
+function trailingBlank_(existing, from) {
+  for (var i = from; i < existing.length; i++) {
+    if (safeStr_(existing[i]) !== "") return false;
+  }
+  return true;
+}
+
 function ensureHeader_(sh) {
   var header = [
     // Interesting columns
@@ -120,7 +164,18 @@ function ensureHeader_(sh) {
     "TeamDpsQ2",
     "SimVersion",
     "SchemaMajor",
-    "SchemaMinor"
+    "SchemaMinor",
+    "Provider",
+    "ResimDpsMean",
+    "ResimDeltaPct",
+    "ResimStatus",
+    "ResimSimVersion",
+    "ResimAt",
+    "CharDpsShare",
+    "ArtifactSets",
+    "TalentLevels",
+    "ConfigFingerprint",
+    "DuplicateOf"
   ];
 
   var firstRow = sh.getRange(1, 1, 1, header.length).getValues();
@@ -139,6 +194,14 @@ function ensureHeader_(sh) {
   // If header already exists, validate it matches expected layout.
   // This avoids silently corrupting existing columns.
   var existing = sh.getRange(1, 1, 1, sh.getLastColumn()).getValues()[0];
+  // Sheets created before the trailing columns (Provider and later) existed: append the
+  // missing headers (old rows keep them blank; blank Provider = wfpsim).
+  var n = 0;
+  while (n < header.length && safeStr_(existing[n]) === header[n]) n++;
+  if (n >= BASE_HEADER_LEN && n < header.length && trailingBlank_(existing, n)) {
+    sh.getRange(1, n + 1, 1, header.length - n).setValues([header.slice(n)]);
+    return;
+  }
   if (!headerMatches_(existing, header)) {
     throw new Error(
       "Header mismatch: sheet has different columns/order. " +
@@ -245,8 +308,9 @@ function applyFixedRowHeights_(sh, lastRow) {
   }
 }
 
-function mapIncomingRow_(row) {
-  // Incoming row layout produced by Go buildRow (kept stable):
+function mapIncomingRow_(row, version) {
+  // Incoming row layout produced by Go BuildRow (kept stable; columns are only appended,
+  // and the request's rowVersion tells which ones the client knows about):
   // 0 FetchedAt
   // 1 DiscordGuildID
   // 2 DiscordChannelID
@@ -265,7 +329,22 @@ function mapIncomingRow_(row) {
   // 15 SchemaMajor
   // 16 SchemaMinor
   // 17 TeamConstellations
+  // 18 Provider (optional; missing = wfpsim)
+  // 19 ResimDpsMean (optional, 19-23 are written by re-simulation)
+  // 20 ResimDeltaPct
+  // 21 ResimStatus
+  // 22 ResimSimVersion
+  // 23 ResimAt
+  // rowVersion 2:
+  // 24 CharDpsShare (per character, % of team DPS)
+  // 25 ArtifactSets (per character)
+  // 26 TalentLevels (per character, attack/skill/burst)
+  // rowVersion 3:
+  // 27 ConfigFingerprint
+  // 28 DuplicateOf (ShareKey of the canonical row of the duplicate group)
   if (!row || row.length < 18) return null;
+  var v2 = version >= 2;
+  var v3 = version >= 3;
 
   var teamChars = safeStr_(row[9]);
   var teamCons = safeStr_(row[17]);
@@ -292,10 +371,25 @@ function mapIncomingRow_(row) {
     row[12],            // TeamDpsQ2
     row[14],            // SimVersion
     row[15],            // SchemaMajor
-    row[16]             // SchemaMinor
+    row[16],            // SchemaMinor
+    row.length > 18 ? row[18] : "wfpsim", // Provider
+    optCell_(row, 19),  // ResimDpsMean
+    optCell_(row, 20),  // ResimDeltaPct
+    optCell_(row, 21),  // ResimStatus
+    optCell_(row, 22),  // ResimSimVersion
+    optCell_(row, 23),  // ResimAt
+    v2 ? optCell_(row, 24) : "", // CharDpsShare
+    v2 ? optCell_(row, 25) : "", // ArtifactSets
+    v2 ? optCell_(row, 26) : "", // TalentLevels
+    v3 ? optCell_(row, 27) : "", // ConfigFingerprint
+    v3 ? optCell_(row, 28) : ""  // DuplicateOf
   ];
 }
 
+function optCell_(row, i) {
+  return (row.length > i && row[i] != null) ? row[i] : "";
+}
+
 function buildTeamCharsUI_(teamChars, teamCons) {
   teamChars = safeStr_(teamChars);
   teamCons = safeStr_(teamCons);
@@ -2,21 +2,67 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/app"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
 )
 
 func main() {
+	// "query" reads the local archive only; the config is optional (engine for aliases).
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		qc, err := config.LoadQuery(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if err := app.Query(context.Background(), qc, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	exportXLSX := flag.Bool("export-xlsx", false, "regenerate output/wfpsim_discord_archiver/archive.xlsx from the store and exit")
+	importXLSX := flag.String("import-xlsx", "", "import records from an archive .xlsx into the store and exit")
+	resim := flag.Bool("resim", false, "re-simulate archived configs that were not re-simulated yet against the local engine and exit")
+	resimAll := flag.Bool("resim-all", false, "like -resim, but re-simulate every archived config")
+	dedupAll := flag.Bool("dedup", false, "recompute config fingerprints of all archived shares, regroup duplicates and exit")
+	daemonMode := flag.Bool("daemon", false, "keep running: archive every daemon.intervalMinutes and serve health/status on daemon.listen")
+	failedReport := flag.Bool("failed-report", false, "list share keys that failed to fetch (given up and pending retry) and exit")
+	flag.Parse()
+
 	cfg, err := config.Load("input/wfpsim_discord_archiver/config.yaml")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
 
-	if err := app.Run(context.Background(), cfg); err != nil {
+	// Ctrl+C / SIGTERM stop the run gracefully (progress is saved).
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	switch {
+	case strings.TrimSpace(*importXLSX) != "":
+		err = app.ImportXLSX(ctx, cfg, *importXLSX)
+	case *resim || *resimAll:
+		err = app.Resim(ctx, cfg, *resimAll)
+	case *dedupAll:
+		err = app.Dedup(ctx, cfg)
+	case *failedReport:
+		err = app.FailedReport(ctx, cfg, os.Stdout)
+	case *exportXLSX:
+		err = app.ExportXLSX(ctx, cfg)
+	case *daemonMode:
+		err = app.Daemon(ctx, cfg)
+	default:
+		err = app.Run(ctx, cfg)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
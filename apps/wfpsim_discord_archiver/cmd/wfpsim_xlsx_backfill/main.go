@@ -12,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/pool"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/ratelimit"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
 	"github.com/xuri/excelize/v2"
@@ -33,11 +35,15 @@ func main() {
 	var outPath string
 	var cachePath string
 	var dryRun bool
+	var workers int
+	var rate float64
 
 	flag.StringVar(&inPath, "in", "", "input .xlsx path")
 	flag.StringVar(&outPath, "out", "", "output .xlsx path (default: <in>_with_configs.xlsx)")
 	flag.StringVar(&cachePath, "cache", filepath.Clean("work/wfpsim_discord_archiver/xlsx_backfill_cache.json"), "cache json path")
 	flag.BoolVar(&dryRun, "dryRun", false, "scan/fetch but do not write XLSX")
+	flag.IntVar(&workers, "workers", 4, "concurrent share fetches")
+	flag.Float64Var(&rate, "rate", 2, "max share fetches per second (0 = unlimited)")
 	flag.Parse()
 
 	if strings.TrimSpace(inPath) == "" {
@@ -58,8 +64,14 @@ func main() {
 		os.Exit(2)
 	}
 
+	if workers < 1 || rate < 0 {
+		fmt.Fprintln(os.Stderr, "-workers must be >= 1 and -rate >= 0")
+		os.Exit(2)
+	}
+
 	ctx := context.Background()
 	wc := wfpsim.New()
+	wc.Limiter = ratelimit.New(rate, workers)
 
 	f, err := excelize.OpenFile(inPath)
 	if err != nil {
@@ -107,11 +119,15 @@ func main() {
 		}
 	}
 
-	// 2) Fill configs next to each wfpsim hyperlink.
+	// 2) Fetch missing configs concurrently (cache is filled in hyperlink order).
+	fetched, failed, err := prefetchShares(ctx, f, sheets, cf, wc, workers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fetch:", err)
+	}
+
+	// 3) Fill configs next to each wfpsim hyperlink.
 	filled := 0
-	fetched := 0
 	skipped := 0
-	failed := 0
 
 	for _, sh := range sheets {
 		cells, err := findWfpsimHyperlinkCells(f, sh)
@@ -132,18 +148,10 @@ func main() {
 			}
 
 			ent, ok := cf.Shares[key]
-			if !ok || (strings.TrimSpace(ent.Config) == "" && strings.TrimSpace(ent.Error) == "") {
-				share, err := wc.FetchShare(ctx, key)
-				fetched++
-				if err != nil {
-					failed++
-					ent = cacheEntry{Error: err.Error(), FetchedAt: time.Now()}
-				} else {
-					ent = cacheEntry{Config: share.ConfigFile, FetchedAt: time.Now()}
-				}
-				cf.Shares[key] = ent
-				// be nice to the API
-				time.Sleep(150 * time.Millisecond)
+			if !ok {
+				// fetch was interrupted before this key
+				skipped++
+				continue
 			}
 
 			if dryRun {
@@ -206,6 +214,48 @@ func main() {
 	fmt.Printf("done. out=%s fetched=%d filled=%d skipped=%d failed=%d cache=%s\n", outPath, fetched, filled, skipped, failed, cachePath)
 }
 
+// prefetchShares fetches every linked share missing from the cache on up to workers goroutines.
+func prefetchShares(ctx context.Context, f *excelize.File, sheets []string, cf cacheFile, wc *wfpsim.Client, workers int) (int, int, error) {
+	var keys []string
+	seen := map[string]struct{}{}
+	for _, sh := range sheets {
+		cells, err := findWfpsimHyperlinkCells(f, sh)
+		if err != nil {
+			continue
+		}
+		for _, cell := range cells {
+			key, ok := shareurl.ExtractKeyFromURL(cell.Target)
+			if !ok {
+				continue
+			}
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			ent, ok := cf.Shares[key]
+			if !ok || (strings.TrimSpace(ent.Config) == "" && strings.TrimSpace(ent.Error) == "") {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) > 0 {
+		fmt.Printf("Fetching %d shares (workers=%d)\n", len(keys), workers)
+	}
+
+	fetched, failed := 0, 0
+	err := pool.Ordered(ctx, keys, workers, wc.FetchShare, func(key string, share wfpsim.Share, err error) error {
+		fetched++
+		if err != nil {
+			failed++
+			cf.Shares[key] = cacheEntry{Error: err.Error(), FetchedAt: time.Now()}
+			return nil
+		}
+		cf.Shares[key] = cacheEntry{Config: share.ConfigFile, FetchedAt: time.Now()}
+		return nil
+	})
+	return fetched, failed, err
+}
+
 type hyperlinkCell struct {
 	Axis   string
 	Target string
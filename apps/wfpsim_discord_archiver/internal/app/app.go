@@ -14,9 +14,10 @@ import (
 
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/dedup"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/engine"
-	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/localxlsx"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/ratelimit"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/sheetsapi"
 	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/state"
@@ -29,6 +30,14 @@ import (
 var cfgConsRe = regexp.MustCompile(`(?mi)^\s*([a-z0-9_\-]+)\s+char\b[^\r\n]*?\bcons\s*=\s*(\d+)`)
 
 func Run(ctx context.Context, cfg config.Config) error {
+	_, err := runOnce(ctx, cfg)
+	return err
+}
+
+// runOnce archives the new shares once and returns the number of new keys. State is saved
+// after every channel/guild/export file; if ctx is cancelled, the progress made so far is
+// saved (and archive.xlsx exported) before returning.
+func runOnce(ctx context.Context, cfg config.Config) (int, error) {
 	fmt.Printf("Starting wfpsim_discord_archiver...\n")
 	if cfg.Run.DryRun {
 		fmt.Printf("Dry-run mode: no writes to Google Sheets (local XLSX still written)\n")
@@ -36,64 +45,115 @@ func Run(ctx context.Context, cfg config.Config) error {
 
 	loaded, err := state.Load(cfg.Run.StateFile)
 	if err != nil {
-		return fmt.Errorf("load state: %w", err)
+		return 0, fmt.Errorf("load state: %w", err)
 	}
 	st := loaded
 	pruneProcessedKeys(&st, 120*24*time.Hour)
 	if cfg.Run.IgnoreStateCheckpoint {
-		fmt.Printf("ignoreStateCheckpoint=true: ignoring channel/search checkpoints (ProcessedKeys still used and saved)\n")
+		fmt.Printf("ignoreStateCheckpoint=true: ignoring channel/search/export checkpoints (ProcessedKeys still used and saved)\n")
 		st.Channels = map[string]state.ChannelState{}
 		st.LastSearchIDs = map[string]string{}
+		st.ProcessedMessageIDs = map[string]time.Time{}
 	}
 	st.LastRunStarted = time.Now()
 
-	dc, err := discord.New(cfg.Discord.Token)
-	if err != nil {
-		return fmt.Errorf("discord client: %w", err)
+	// Export mode reads DiscordChatExporter files and never connects to Discord.
+	var dc *discord.Client
+	if cfg.Run.Mode != "export" {
+		dc, err = discord.New(cfg.Discord.Token)
+		if err != nil {
+			return 0, fmt.Errorf("discord client: %w", err)
+		}
+		defer dc.Close()
 	}
-	defer dc.Close()
 
-	localPath := filepath.Clean(filepath.Join("output", "wfpsim_discord_archiver", "archive.xlsx"))
-	localWriter := localxlsx.New(localPath, cfg.Sheet.Name)
+	archive, err := openStore(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
 
+	var sheets *sheetsapi.Client
 	writers := make([]rowWriter, 0, 3)
-	// Local XLSX is always written.
-	writers = append(writers, localWriter)
+	// The local store is always written; archive.xlsx is exported from it at the end of the run.
+	writers = append(writers, storeWriter{st: archive})
 	// In dry-run, also print what would be appended.
 	if cfg.Run.DryRun {
 		writers = append(writers, dryRunWriter{})
 	} else {
 		if strings.TrimSpace(cfg.AppsScript.WebAppURL) == "" {
-			return fmt.Errorf("appsScript.webAppUrl is required when dryRun=false")
+			return 0, fmt.Errorf("appsScript.webAppUrl is required when dryRun=false")
 		}
-		writers = append(writers, sheetsapi.New(cfg.AppsScript.WebAppURL, cfg.AppsScript.APIKey, cfg.Sheet.ID, cfg.Sheet.Name))
+		sheets = sheetsapi.New(cfg.AppsScript.WebAppURL, cfg.AppsScript.APIKey, cfg.Sheet.ID, cfg.Sheet.Name)
+		writers = append(writers, sheets)
 	}
 	writer := multiWriter{writers: writers}
 
 	wc := wfpsim.New()
+	wc.Limiter = ratelimit.New(*cfg.Run.FetchRate, cfg.Run.FetchWorkers)
 
 	var aliasResolver *charalias.Resolver
 	if strings.TrimSpace(cfg.Engine) != "" || strings.TrimSpace(cfg.EnginePath) != "" {
-		repoRoot, err := engine.FindRepoRoot()
+		engineRoot, err := resolveEngineRoot(cfg)
 		if err != nil {
-			return err
-		}
-		engineRoot, err := engine.ResolveRoot(repoRoot, cfg.Engine, cfg.EnginePath)
-		if err != nil {
-			return err
+			return 0, err
 		}
 		aliasResolver, err = charalias.LoadFromEngineRoot(engineRoot)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		fmt.Printf("Character alias resolver enabled (engine root=%s)\n", engineRoot)
 	}
 
+	arch := &archiver{
+		writer:        writer,
+		wc:            wc,
+		aliasResolver: aliasResolver,
+		st:            &st,
+		archive:       archive,
+		seenKeys:      map[string]struct{}{},
+		workers:       cfg.Run.FetchWorkers,
+		retry:         retryPolicy(cfg),
+	}
+	arch.checkpoint = func() error { return saveState(cfg, &st) }
+
 	totalNewKeys := 0
+	// stop ends the run on err; on cancellation the progress so far is kept.
+	stop := func(err error) (int, error) {
+		if ctx.Err() != nil {
+			fmt.Printf("Interrupted; saving progress\n")
+			if saveErr := arch.checkpoint(); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", saveErr)
+			}
+			if totalNewKeys > 0 {
+				if exportErr := exportXLSX(archive, cfg.Sheet.Name); exportErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", exportErr)
+				}
+			}
+		}
+		return totalNewKeys, err
+	}
+
+	totalNewKeys, err = arch.retryFailed(ctx)
+	if err != nil {
+		return stop(err)
+	}
+	if err := arch.checkpoint(); err != nil {
+		return stop(err)
+	}
 	cutoff := time.Now().Add(-time.Duration(cfg.Run.SinceDays) * 24 * time.Hour)
-	seenKeys := map[string]struct{}{}
 	channelGuildID := map[string]string{}
 
+	if cfg.Run.Mode == "export" {
+		fmt.Printf("Using run.mode=export\n")
+		n, err := arch.ingestExports(ctx, cfg.Run.ExportFiles)
+		totalNewKeys += n
+		if err != nil {
+			return stop(err)
+		}
+		goto finalize
+	}
+
 	if cfg.Run.Mode == "guildSearch" {
 		fmt.Printf("Using run.mode=guildSearch\n")
 		guildIDs := cfg.Discord.ServerIDs
@@ -109,50 +169,25 @@ func Run(ctx context.Context, cfg config.Config) error {
 				msgStopAfter = st.LastSearchIDs[guildID]
 			}
 
-			msgs, newestSeen, err := dc.SearchGuildMessages(ctx, guildID, "wfpsim.com/sh/", msgStopAfter, cutoff, cfg.Discord.ChannelIDs)
+			msgs, newestSeen, err := searchShareMessages(ctx, dc, guildID, msgStopAfter, cutoff, cfg.Discord.ChannelIDs)
 			if err != nil {
-				return err
+				return stop(err)
 			}
 			fmt.Printf("Fetched %d messages from guild search (guild=%s)\n", len(msgs), guildID)
 
+			var jobs []shareJob
 			for _, m := range msgs {
 				// We still iterate over everything search returned, but only *process* messages
 				// that fall within the cutoff window.
 				if !cutoff.IsZero() && (m.CreatedAt.IsZero() || m.CreatedAt.Before(cutoff)) {
 					continue
 				}
-
-				keys := extractKeys(m.Content)
-				if len(keys) == 0 {
-					continue
-				}
-				for _, key := range keys {
-					if _, ok := seenKeys[key]; ok {
-						continue
-					}
-					seenKeys[key] = struct{}{}
-					if _, ok := st.ProcessedKeys[key]; ok {
-						continue
-					}
-
-					fmt.Printf("Fetching share for key %s...\n", key)
-					share, err := wc.FetchShare(ctx, key)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "wfpsim fetch failed key=%s msg=%s err=%v\n", key, m.ID, err)
-						continue
-					}
-
-					row, err := buildRow(guildID, m, key, share, aliasResolver)
-					if err != nil {
-						return err
-					}
-					if err := writer.AppendRow(ctx, row, key, m.ID); err != nil {
-						return err
-					}
-
-					totalNewKeys++
-					st.ProcessedKeys[key] = time.Now()
-				}
+				jobs = append(jobs, arch.jobs(guildID, m, false)...)
+			}
+			n, err := arch.archiveJobs(ctx, jobs)
+			totalNewKeys += n
+			if err != nil {
+				return stop(err)
 			}
 
 			if !cfg.Run.IgnoreStateCheckpoint {
@@ -160,6 +195,9 @@ func Run(ctx context.Context, cfg config.Config) error {
 					st.LastSearchIDs[guildID] = newestSeen
 				}
 			}
+			if err := arch.checkpoint(); err != nil {
+				return stop(err)
+			}
 		}
 		goto finalize
 	}
@@ -186,46 +224,18 @@ func Run(ctx context.Context, cfg config.Config) error {
 
 		msgs, newestSeen, err := dc.FetchRecentMessages(ctx, chID, stopAfter, cutoff)
 		if err != nil {
-			return err
+			return stop(err)
 		}
 		fmt.Printf("Fetched %d messages from channel %s\n", len(msgs), chID)
 
-		channelNewKeys := 0
+		var jobs []shareJob
 		for _, m := range msgs {
-			keys := extractKeys(m.Content)
-			if len(keys) == 0 {
-				continue
-			}
-
-			for _, key := range keys {
-				if _, ok := seenKeys[key]; ok {
-					continue
-				}
-				seenKeys[key] = struct{}{}
-				if _, ok := st.ProcessedKeys[key]; ok {
-					continue
-				}
-
-				fmt.Printf("Fetching share for key %s...\n", key)
-				share, err := wc.FetchShare(ctx, key)
-				if err != nil {
-					// не прерываем весь прогон: ссылка могла умереть или API недоступно
-					fmt.Fprintf(os.Stderr, "wfpsim fetch failed key=%s msg=%s err=%v\n", key, m.ID, err)
-					continue
-				}
-
-				row, err := buildRow(guildID, m, key, share, aliasResolver)
-				if err != nil {
-					return err
-				}
-				if err := writer.AppendRow(ctx, row, key, m.ID); err != nil {
-					return err
-				}
-
-				totalNewKeys++
-				channelNewKeys++
-				st.ProcessedKeys[key] = time.Now()
-			}
+			jobs = append(jobs, arch.jobs(guildID, m, false)...)
+		}
+		channelNewKeys, err := arch.archiveJobs(ctx, jobs)
+		totalNewKeys += channelNewKeys
+		if err != nil {
+			return stop(err)
 		}
 		fmt.Printf("Processed %d new keys from channel %s\n", channelNewKeys, chID)
 
@@ -235,37 +245,69 @@ func Run(ctx context.Context, cfg config.Config) error {
 				st.Channels[chID] = state.ChannelState{LastSeenMessageID: newestSeen, LastSeenAt: time.Now()}
 			}
 		}
+		if err := arch.checkpoint(); err != nil {
+			return stop(err)
+		}
 	}
 
 finalize:
 	st.LastRunEnded = time.Now()
-	if !cfg.Run.DryRun {
-		if cfg.Run.IgnoreStateCheckpoint {
-			if err := saveProcessedKeysOnly(cfg.Run.StateFile, st.ProcessedKeys); err != nil {
-				return fmt.Errorf("save state (processed keys only): %w", err)
-			}
-			fmt.Printf("done. new keys: %d. state (processed keys only): %s\n", totalNewKeys, cfg.Run.StateFile)
-		} else {
-			if err := state.Save(cfg.Run.StateFile, st); err != nil {
-				return fmt.Errorf("save state: %w", err)
-			}
-			fmt.Printf("done. new keys: %d. state: %s\n", totalNewKeys, cfg.Run.StateFile)
+	if n := len(arch.gaveUp); n > 0 {
+		fmt.Fprintf(os.Stderr, "gave up %d key(s) in this run; see -failed-report\n", n)
+	}
+	regrouped, err := regroupDuplicates(ctx, archive, cfg.Dedup.Keep, sheets)
+	if err != nil {
+		return totalNewKeys, err
+	}
+	if _, err := os.Stat(localXLSXPath); totalNewKeys > 0 || regrouped > 0 || os.IsNotExist(err) {
+		if err := exportXLSX(archive, cfg.Sheet.Name); err != nil {
+			return totalNewKeys, err
 		}
-	} else {
+	}
+	if err := saveState(cfg, &st); err != nil {
+		return totalNewKeys, err
+	}
+	switch {
+	case cfg.Run.DryRun:
 		fmt.Printf("done. new keys: %d. state not written (dry-run)\n", totalNewKeys)
+	case cfg.Run.IgnoreStateCheckpoint:
+		fmt.Printf("done. new keys: %d. state (processed keys only): %s\n", totalNewKeys, cfg.Run.StateFile)
+	default:
+		fmt.Printf("done. new keys: %d. state: %s\n", totalNewKeys, cfg.Run.StateFile)
+	}
+	return totalNewKeys, nil
+}
+
+// saveState writes st (nothing in dry-run; only the processed and failed keys with
+// ignoreStateCheckpoint).
+func saveState(cfg config.Config, st *state.State) error {
+	if cfg.Run.DryRun {
+		return nil
+	}
+	if cfg.Run.IgnoreStateCheckpoint {
+		if err := saveProcessedKeysOnly(cfg.Run.StateFile, st.ProcessedKeys, st.FailedKeys); err != nil {
+			return fmt.Errorf("save state (processed keys only): %w", err)
+		}
+		return nil
+	}
+	if err := state.Save(cfg.Run.StateFile, *st); err != nil {
+		return fmt.Errorf("save state: %w", err)
 	}
 	return nil
 }
 
 type processedKeysOnlyState struct {
-	ProcessedKeys map[string]time.Time `json:"processedKeys"`
+	ProcessedKeys map[string]time.Time       `json:"processedKeys"`
+	FailedKeys    map[string]state.FailedKey `json:"failedKeys,omitempty"`
 }
 
-func saveProcessedKeysOnly(path string, keys map[string]time.Time) error {
+// saveProcessedKeysOnly writes a state without checkpoints; failed keys are kept so their
+// retry schedule survives runs with ignoreStateCheckpoint.
+func saveProcessedKeysOnly(path string, keys map[string]time.Time, failed map[string]state.FailedKey) error {
 	if keys == nil {
 		keys = map[string]time.Time{}
 	}
-	b, err := json.MarshalIndent(processedKeysOnlyState{ProcessedKeys: keys}, "", "  ")
+	b, err := json.MarshalIndent(processedKeysOnlyState{ProcessedKeys: keys, FailedKeys: failed}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -292,19 +334,63 @@ func pruneProcessedKeys(st *state.State, maxAge time.Duration) {
 			delete(st.ProcessedKeys, k)
 		}
 	}
+	for id, t := range st.ProcessedMessageIDs {
+		if !t.IsZero() && t.Before(cutoff) {
+			delete(st.ProcessedMessageIDs, id)
+		}
+	}
+}
+
+func resolveEngineRoot(cfg config.Config) (string, error) {
+	repoRoot, err := engine.FindRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	return engine.ResolveRoot(repoRoot, cfg.Engine, cfg.EnginePath)
+}
+
+// searchShareMessages runs one guild search per share host and merges the results
+// (oldest -> newest, deduplicated by message ID).
+func searchShareMessages(ctx context.Context, dc *discord.Client, guildID string, minMessageID string, cutoff time.Time, channelIDs []string) ([]discord.Message, string, error) {
+	var out []discord.Message
+	newestSeen := ""
+	seen := map[string]struct{}{}
+	for _, q := range shareurl.SearchQueries() {
+		msgs, newest, err := dc.SearchGuildMessages(ctx, guildID, q, minMessageID, cutoff, channelIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, m := range msgs {
+			if _, ok := seen[m.ID]; ok {
+				continue
+			}
+			seen[m.ID] = struct{}{}
+			out = append(out, m)
+		}
+		if discord.SnowflakeLess(newestSeen, newest) {
+			newestSeen = newest
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return discord.SnowflakeLess(out[i].ID, out[j].ID) })
+	return out, newestSeen, nil
 }
 
 func extractKeys(content string) []string {
 	return shareurl.ExtractKeysFromText(content)
 }
 
-func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share, aliasResolver *charalias.Resolver) ([]interface{}, error) {
+// BuildRow lays out a fetched share as an archive row (store.RowVersion; read back by
+// store.RecordFromRow and mapIncomingRow_ in Code.gs). Characters are sorted by name and the
+// per-character columns follow that order.
+func BuildRow(guildID string, m discord.Message, key string, share wfpsim.Share, aliasResolver *charalias.Resolver) ([]interface{}, error) {
 	type pair struct {
 		char   string
 		weapon string
+		// idx is the character's index in share.CharacterDetails (and the per-character statistics).
+		idx int
 	}
 	pairs := make([]pair, 0, len(share.CharacterDetails))
-	for _, c := range share.CharacterDetails {
+	for i, c := range share.CharacterDetails {
 		w := ""
 		if c.Weapon.Name != "" {
 			if c.Weapon.Refine > 0 {
@@ -313,7 +399,7 @@ func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share,
 				w = c.Weapon.Name
 			}
 		}
-		pairs = append(pairs, pair{char: c.Name, weapon: w})
+		pairs = append(pairs, pair{char: c.Name, weapon: w, idx: i})
 	}
 	sort.Slice(pairs, func(i, j int) bool { return pairs[i].char < pairs[j].char })
 
@@ -324,9 +410,16 @@ func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share,
 		return nil, err
 	}
 	cons := make([]string, 0, len(pairs))
+	dpsShares := make([]string, 0, len(pairs))
+	sets := make([]string, 0, len(pairs))
+	talents := make([]string, 0, len(pairs))
 	for _, p := range pairs {
+		c := share.CharacterDetails[p.idx]
 		chars = append(chars, p.char)
 		weps = append(weps, p.weapon)
+		dpsShares = append(dpsShares, charDpsShare(share.Statistics, p.idx))
+		sets = append(sets, formatSets(c.Sets))
+		talents = append(talents, formatTalents(c.Talents))
 		lookup := strings.ToLower(p.char)
 		if aliasResolver != nil {
 			canon, ok := aliasResolver.Canonicalize(lookup)
@@ -345,7 +438,10 @@ func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share,
 		}
 	}
 
-	shareURL := fmt.Sprintf("https://wfpsim.com/sh/%s", key)
+	link, ok := shareurl.ParseKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid share key %q", key)
+	}
 
 	return []interface{}{
 		time.Now().Format(time.RFC3339),
@@ -356,7 +452,7 @@ func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share,
 		m.Author,
 		m.CreatedAt.Format(time.RFC3339),
 		key,
-		shareURL,
+		link.URL(),
 		strings.Join(chars, ","),
 		strings.Join(weps, ","),
 		share.Statistics.DPS.Mean,
@@ -366,9 +462,66 @@ func buildRow(guildID string, m discord.Message, key string, share wfpsim.Share,
 		share.SchemaVersion.Major,
 		share.SchemaVersion.Minor,
 		strings.Join(cons, ","),
+		link.Provider,
+		// re-simulation columns (written by -resim)
+		"", "", "", "", "",
+		joinNonEmpty(dpsShares),
+		joinNonEmpty(sets),
+		joinNonEmpty(talents),
+		dedup.Fingerprint(share.ConfigFile, aliasResolver),
+		"", // DuplicateOf (set when the archive is regrouped)
 	}, nil
 }
 
+// charDpsShare is the DPS of character idx as a percentage of the team DPS ("" if unknown).
+func charDpsShare(stats wfpsim.Statistics, idx int) string {
+	if idx >= len(stats.CharacterDPS) || stats.DPS.Mean <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(100*stats.CharacterDPS[idx].Mean/stats.DPS.Mean, 'f', 1, 64)
+}
+
+// formatSets lists the artifact sets with a set bonus (2+ pieces), most pieces first:
+// "emblemofseveredfate(4)", "gildeddreams(2)+shimenawasreminiscence(2)".
+func formatSets(sets map[string]int) string {
+	names := make([]string, 0, len(sets))
+	for name, n := range sets {
+		if n >= 2 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if sets[names[i]] != sets[names[j]] {
+			return sets[names[i]] > sets[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s(%d)", name, sets[name])
+	}
+	return strings.Join(parts, "+")
+}
+
+// formatTalents is "attack/skill/burst" ("" if the share has no talents).
+func formatTalents(t wfpsim.Talents) string {
+	if t.Attack == 0 && t.Skill == 0 && t.Burst == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d/%d", t.Attack, t.Skill, t.Burst)
+}
+
+// joinNonEmpty joins per-character values with ",", or returns "" if every value is empty
+// (e.g. shares without per-character statistics).
+func joinNonEmpty(vals []string) string {
+	for _, v := range vals {
+		if v != "" {
+			return strings.Join(vals, ",")
+		}
+	}
+	return ""
+}
+
 func parseConsByChar(configFile string, aliasResolver *charalias.Resolver) (map[string]int, error) {
 	out := map[string]int{}
 	if strings.TrimSpace(configFile) == "" {
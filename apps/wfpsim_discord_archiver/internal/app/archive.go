@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/localxlsx"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+var localXLSXPath = filepath.Clean(filepath.Join("output", "wfpsim_discord_archiver", "archive.xlsx"))
+
+// openStore opens the archive store. An empty store is seeded from the local archive.xlsx
+// (the pre-store source of truth) if one exists.
+func openStore(cfg config.Config) (*store.Store, error) {
+	st, err := store.Open(cfg.Run.StoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+	if st.Len() == 0 {
+		if _, err := os.Stat(localXLSXPath); err == nil {
+			n, err := importRecords(st, localXLSXPath, cfg.Sheet.Name)
+			if err != nil {
+				_ = st.Close()
+				return nil, fmt.Errorf("migrate %s: %w", localXLSXPath, err)
+			}
+			fmt.Printf("migrated %d records from %s into %s\n", n, localXLSXPath, st.Path())
+		}
+	}
+	return st, nil
+}
+
+func importRecords(st *store.Store, path string, sheetName string) (int, error) {
+	recs, err := localxlsx.ReadRecords(path, sheetName)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, rec := range recs {
+		ok, err := st.Append(rec)
+		if err != nil {
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+	return added, nil
+}
+
+func exportXLSX(st *store.Store, sheetName string) error {
+	recs, err := st.All()
+	if err != nil {
+		return fmt.Errorf("read store: %w", err)
+	}
+	if err := localxlsx.New(localXLSXPath, sheetName).Export(recs); err != nil {
+		return fmt.Errorf("export xlsx: %w", err)
+	}
+	fmt.Printf("exported %d records to %s\n", len(recs), localXLSXPath)
+	return nil
+}
+
+// ExportXLSX regenerates the local archive.xlsx from the store.
+func ExportXLSX(ctx context.Context, cfg config.Config) error {
+	_ = ctx
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	return exportXLSX(st, cfg.Sheet.Name)
+}
+
+// ImportXLSX adds the records of an archive workbook to the store (existing keys are kept).
+func ImportXLSX(ctx context.Context, cfg config.Config, path string) error {
+	_ = ctx
+	st, err := store.Open(cfg.Run.StoreFile)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer st.Close()
+	n, err := importRecords(st, path, cfg.Sheet.Name)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", path, err)
+	}
+	fmt.Printf("imported %d new records from %s into %s (total %d)\n", n, path, st.Path(), st.Len())
+	return nil
+}
+
+// storeWriter persists every archived row into the store.
+type storeWriter struct {
+	st *store.Store
+}
+
+func (w storeWriter) AppendRow(ctx context.Context, row []interface{}, key string, messageID string) error {
+	_ = ctx
+	_ = messageID
+	rec := store.RecordFromRow(row)
+	if rec.Key == "" {
+		rec.Key = key
+	}
+	if _, err := w.st.Append(rec); err != nil {
+		return fmt.Errorf("store append %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/daemon"
+)
+
+// Daemon runs the archiver every daemon.intervalMinutes until ctx is cancelled, serving its
+// health/status on daemon.listen. A failed run is reported and retried at the next interval.
+func Daemon(ctx context.Context, cfg config.Config) error {
+	status := daemon.NewStatus(time.Now())
+	if cfg.Daemon.Listen != "off" {
+		wait, err := daemon.StartServer(ctx, cfg.Daemon.Listen, status)
+		if err != nil {
+			return err
+		}
+		defer wait()
+		fmt.Printf("Status endpoint: http://%s/status (health: /health)\n", cfg.Daemon.Listen)
+	}
+
+	interval := time.Duration(cfg.Daemon.IntervalMinutes) * time.Minute
+	fmt.Printf("Daemon mode: a run every %s after the previous one ends (Ctrl+C to stop)\n", interval)
+	err := daemon.Loop(ctx, interval, status, func(ctx context.Context) (int, error) {
+		return runOnce(ctx, cfg)
+	})
+	s := status.Snapshot()
+	fmt.Printf("Daemon stopped after %d run(s), %d new key(s)\n", s.Runs, s.TotalNewKeys)
+	return err
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/dedup"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/sheetsapi"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// regroupDuplicates recomputes the duplicate groups of the archive and writes the records whose
+// DuplicateOf changed to the store and (if sheets is set) Google Sheets. It returns their number.
+func regroupDuplicates(ctx context.Context, archive *store.Store, keep string, sheets *sheetsapi.Client) (int, error) {
+	recs, err := archive.All()
+	if err != nil {
+		return 0, fmt.Errorf("read store: %w", err)
+	}
+	changed := dedup.Group(recs, keep)
+	if err := putRecords(ctx, archive, changed, sheets); err != nil {
+		return 0, err
+	}
+	if len(changed) > 0 {
+		fmt.Printf("Regrouped duplicates: %d record(s) changed\n", len(changed))
+	}
+	return len(changed), nil
+}
+
+// Dedup recomputes the config fingerprints of every archived record (e.g. for records archived
+// before fingerprints existed, or after alias changes in the engine), regroups duplicates and
+// writes the changes to the store, archive.xlsx and (unless dryRun) Google Sheets.
+func Dedup(ctx context.Context, cfg config.Config) error {
+	var resolver *charalias.Resolver
+	if strings.TrimSpace(cfg.Engine) != "" || strings.TrimSpace(cfg.EnginePath) != "" {
+		engineRoot, err := resolveEngineRoot(cfg)
+		if err != nil {
+			return err
+		}
+		if resolver, err = charalias.LoadFromEngineRoot(engineRoot); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("warn: no engine/enginePath; character aliases are not resolved in fingerprints\n")
+	}
+
+	var sheets *sheetsapi.Client
+	if !cfg.Run.DryRun {
+		sheets = sheetsapi.New(cfg.AppsScript.WebAppURL, cfg.AppsScript.APIKey, cfg.Sheet.ID, cfg.Sheet.Name)
+	}
+
+	archive, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	recs, err := archive.All()
+	if err != nil {
+		return fmt.Errorf("read store: %w", err)
+	}
+	changed := map[string]store.Record{}
+	for i := range recs {
+		fp := dedup.Fingerprint(recs[i].ConfigFile, resolver)
+		if fp != recs[i].ConfigFingerprint {
+			recs[i].ConfigFingerprint = fp
+			changed[recs[i].Key] = recs[i]
+		}
+	}
+	for _, rec := range dedup.Group(recs, cfg.Dedup.Keep) {
+		changed[rec.Key] = rec
+	}
+
+	out := make([]store.Record, 0, len(changed))
+	groups := map[string]struct{}{}
+	dups := 0
+	for _, rec := range recs {
+		if c, ok := changed[rec.Key]; ok {
+			out = append(out, c)
+		}
+		if rec.DuplicateOf != "" {
+			dups++
+			groups[rec.ConfigFingerprint] = struct{}{}
+		}
+	}
+	fmt.Printf("Updating %d of %d records\n", len(out), len(recs))
+	if err := putRecords(ctx, archive, out, sheets); err != nil {
+		return err
+	}
+	if err := archive.Compact(); err != nil {
+		return err
+	}
+	if len(out) > 0 {
+		if err := exportXLSX(archive, cfg.Sheet.Name); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("done. %d duplicate record(s) in %d group(s)\n", dups, len(groups))
+	return nil
+}
+
+// putRecords upserts recs into the store and, if sheets is set, rewrites their updatable
+// columns in Google Sheets.
+func putRecords(ctx context.Context, archive *store.Store, recs []store.Record, sheets *sheetsapi.Client) error {
+	for _, rec := range recs {
+		if err := archive.Put(rec); err != nil {
+			return err
+		}
+		if sheets != nil {
+			if err := sheets.UpdateRow(ctx, store.RowFromRecord(rec)); err != nil {
+				return fmt.Errorf("sheet update %s: %w", rec.Key, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discordexport"
+)
+
+// ingestExports archives shares from DiscordChatExporter files (run.mode=export).
+// Exports are historical snapshots, so run.sinceDays is not applied; messages are deduplicated
+// by ID via st.ProcessedMessageIDs (only messages with share keys are recorded; the entries
+// age out with ProcessedKeys), and keys already in the store are not fetched again.
+func (a *archiver) ingestExports(ctx context.Context, paths []string) (int, error) {
+	files, err := discordexport.ExpandPaths(paths)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Printf("Export files: %d\n", len(files))
+
+	totalNewKeys := 0
+	for fi, path := range files {
+		ex, err := discordexport.Load(path)
+		if err != nil {
+			return totalNewKeys, err
+		}
+		fmt.Printf("Processing export %d/%d: %s (%d messages, guild=%s channel=%s)\n", fi+1, len(files), path, len(ex.Messages), ex.GuildID, ex.ChannelID)
+		if ex.ChannelID == "" {
+			fmt.Fprintf(os.Stderr, "warn: channel id unknown for export %s; message URLs will be incomplete\n", path)
+		}
+
+		var jobs []shareJob
+		pending := make([]discord.Message, 0, len(ex.Messages))
+		for _, m := range ex.Messages {
+			if _, ok := a.st.ProcessedMessageIDs[m.ID]; ok {
+				continue
+			}
+			if len(extractKeys(m.Content)) == 0 {
+				continue
+			}
+			pending = append(pending, m)
+			jobs = append(jobs, a.jobs(ex.GuildID, m, true)...)
+		}
+
+		// failed fetches are retried from st.FailedKeys, so every message counts as processed
+		fileNewKeys, err := a.archiveJobs(ctx, jobs)
+		totalNewKeys += fileNewKeys
+		if err != nil {
+			return totalNewKeys, err
+		}
+		for _, m := range pending {
+			a.st.ProcessedMessageIDs[m.ID] = time.Now()
+		}
+		if err := a.checkpoint(); err != nil {
+			return totalNewKeys, err
+		}
+		fmt.Printf("Processed %d new keys from export %s\n", fileNewKeys, path)
+	}
+	return totalNewKeys, nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/state"
+)
+
+// FailedReport prints the failed share keys from the state file: the permanently failed ones
+// first, then the ones still scheduled for a retry.
+func FailedReport(ctx context.Context, cfg config.Config, w io.Writer) error {
+	_ = ctx
+	st, err := state.Load(cfg.Run.StateFile)
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+
+	var gaveUp, pending []string
+	for k, f := range st.FailedKeys {
+		if f.Permanent {
+			gaveUp = append(gaveUp, k)
+		} else {
+			pending = append(pending, k)
+		}
+	}
+	sort.Slice(gaveUp, func(i, j int) bool {
+		return st.FailedKeys[gaveUp[i]].LastFailed.After(st.FailedKeys[gaveUp[j]].LastFailed)
+	})
+	sort.Slice(pending, func(i, j int) bool {
+		return st.FailedKeys[pending[i]].NextRetry.Before(st.FailedKeys[pending[j]].NextRetry)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Permanently failed: %d\n", len(gaveUp))
+	if len(gaveUp) > 0 {
+		fmt.Fprintln(tw, "Key\tClass\tAttempts\tLastFailed\tError\tMessageURL")
+		for _, k := range gaveUp {
+			f := st.FailedKeys[k]
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", k, f.ErrorClass, f.Attempts, f.LastFailed.Format(time.RFC3339), f.Error, discord.MessageURL(f.GuildID, f.ChannelID, f.MessageID))
+		}
+	}
+	fmt.Fprintf(tw, "\nPending retry: %d\n", len(pending))
+	if len(pending) > 0 {
+		fmt.Fprintln(tw, "Key\tClass\tAttempts\tNextRetry\tError")
+		for _, k := range pending {
+			f := st.FailedKeys[k]
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", k, f.ErrorClass, f.Attempts, f.NextRetry.Format(time.RFC3339), f.Error)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/pool"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/state"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
+)
+
+// shareJob is one share key to archive, with the message it was found in.
+type shareJob struct {
+	guildID string
+	msg     discord.Message
+	key     string
+}
+
+// archiver turns share keys found in messages into archived rows.
+type archiver struct {
+	writer        rowWriter
+	wc            *wfpsim.Client
+	aliasResolver *charalias.Resolver
+	st            *state.State
+	archive       *store.Store
+	// seenKeys are the keys already handled in this run.
+	seenKeys map[string]struct{}
+	// workers is the number of concurrent share fetches (run.fetchWorkers).
+	workers int
+	// retry schedules failed keys (run.failedMaxAttempts / run.failedRetryHours).
+	retry state.RetryPolicy
+	// gaveUp are the keys given up in this run.
+	gaveUp []string
+	// checkpoint saves the state; called after each channel, guild or export file.
+	checkpoint func() error
+}
+
+// jobs returns the jobs for m's keys that were not seen in this run and not processed before.
+// Failed keys are skipped: they are retried on their own schedule (see retryFailed).
+// With skipArchived, keys already in the store are skipped too.
+func (a *archiver) jobs(guildID string, m discord.Message, skipArchived bool) []shareJob {
+	var out []shareJob
+	for _, key := range extractKeys(m.Content) {
+		if _, ok := a.seenKeys[key]; ok {
+			continue
+		}
+		a.seenKeys[key] = struct{}{}
+		if _, ok := a.st.ProcessedKeys[key]; ok {
+			continue
+		}
+		if _, ok := a.st.FailedKeys[key]; ok {
+			continue
+		}
+		if skipArchived && a.archive.Has(key) {
+			continue
+		}
+		out = append(out, shareJob{guildID: guildID, msg: m, key: key})
+	}
+	return out
+}
+
+// archiveJobs fetches the shares of jobs concurrently (a.workers at a time, throttled by the
+// client's limiter) and writes the rows in job order, so rows and state are the same as in a
+// sequential run. Failed fetches are recorded in st.FailedKeys for a later retry.
+func (a *archiver) archiveJobs(ctx context.Context, jobs []shareJob) (int, error) {
+	newKeys := 0
+	fetch := func(ctx context.Context, j shareJob) (wfpsim.Share, error) {
+		fmt.Printf("Fetching share for key %s...\n", j.key)
+		return a.wc.FetchShare(ctx, j.key)
+	}
+	err := pool.Ordered(ctx, jobs, a.workers, fetch, func(j shareJob, share wfpsim.Share, err error) error {
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// не прерываем весь прогон: ссылка могла умереть или API недоступно
+			fmt.Fprintf(os.Stderr, "wfpsim fetch failed key=%s msg=%s err=%v\n", j.key, j.msg.ID, err)
+			a.recordFailure(j, err)
+			return nil
+		}
+
+		row, err := BuildRow(j.guildID, j.msg, j.key, share, a.aliasResolver)
+		if err != nil {
+			return err
+		}
+		if err := a.writer.AppendRow(ctx, row, j.key, j.msg.ID); err != nil {
+			return err
+		}
+		newKeys++
+		a.st.ProcessedKeys[j.key] = time.Now()
+		delete(a.st.FailedKeys, j.key)
+		return nil
+	})
+	return newKeys, err
+}
+
+// recordFailure schedules a retry of j's key, or gives it up after too many attempts or on 404.
+func (a *archiver) recordFailure(j shareJob, err error) {
+	class := wfpsim.ErrorClass(err)
+	f := a.st.RecordFailure(j.key, state.FailedKey{
+		GuildID:    j.guildID,
+		ChannelID:  j.msg.ChannelID,
+		MessageID:  j.msg.ID,
+		Author:     j.msg.Author,
+		CreatedAt:  j.msg.CreatedAt,
+		ErrorClass: class,
+		Error:      err.Error(),
+	}, class == wfpsim.FailNotFound, a.retry, time.Now())
+	if f.Permanent {
+		fmt.Fprintf(os.Stderr, "giving up key=%s after %d attempt(s) (%s)\n", j.key, f.Attempts, class)
+		a.gaveUp = append(a.gaveUp, j.key)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "will retry key=%s after %s (attempt %d, %s)\n", j.key, f.NextRetry.Format(time.RFC3339), f.Attempts, class)
+}
+
+// retryFailed re-fetches the failed keys that are due, using the message they were found in.
+// It runs before the regular scan, whatever the run mode and checkpoints.
+func (a *archiver) retryFailed(ctx context.Context) (int, error) {
+	keys := a.st.DueFailedKeys(time.Now())
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	fmt.Printf("Retrying %d failed key(s)\n", len(keys))
+	jobs := make([]shareJob, 0, len(keys))
+	for _, key := range keys {
+		a.seenKeys[key] = struct{}{}
+		f := a.st.FailedKeys[key]
+		jobs = append(jobs, shareJob{
+			guildID: f.GuildID,
+			msg:     discord.Message{ID: f.MessageID, ChannelID: f.ChannelID, Author: f.Author, CreatedAt: f.CreatedAt},
+			key:     key,
+		})
+	}
+	return a.archiveJobs(ctx, jobs)
+}
+
+// retryPolicy returns the failed-key retry schedule of cfg.
+func retryPolicy(cfg config.Config) state.RetryPolicy {
+	return state.RetryPolicy{
+		MaxAttempts: cfg.Run.FailedMaxAttempts,
+		Backoff:     time.Duration(cfg.Run.FailedRetryHours) * time.Hour,
+		MaxBackoff:  7 * 24 * time.Hour,
+	}
+}
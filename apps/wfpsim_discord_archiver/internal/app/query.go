@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/localxlsx"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/query"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// Query filters the archive and writes the best teams as a table (stdout), CSV or an XLSX sheet.
+func Query(ctx context.Context, qc config.QueryConfig, stdout io.Writer) error {
+	_ = ctx
+	recs, source, err := loadQueryRecords(qc)
+	if err != nil {
+		return err
+	}
+	if qc.Engine != "" || qc.EnginePath != "" {
+		// Aliases are a convenience here: without the engine, names are matched as written.
+		if resolver, err := loadResolver(config.Config{Engine: qc.Engine, EnginePath: qc.EnginePath}); err != nil {
+			fmt.Fprintf(os.Stderr, "warn: %v; character aliases are not resolved\n", err)
+		} else {
+			qc.Filter.Resolver = resolver
+		}
+	}
+	res := query.Apply(recs, qc.Filter)
+
+	switch qc.Format {
+	case "csv":
+		if strings.TrimSpace(qc.OutPath) == "" {
+			if err := query.WriteCSV(stdout, res); err != nil {
+				return err
+			}
+			break
+		}
+		if err := os.MkdirAll(filepath.Dir(qc.OutPath), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(qc.OutPath)
+		if err != nil {
+			return err
+		}
+		if err := query.WriteCSV(f, res); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	case "xlsx":
+		if err := query.WriteXLSX(qc.OutPath, qc.SheetName, res); err != nil {
+			return err
+		}
+	default:
+		if err := query.WriteTable(stdout, res); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d records matched (%s)", len(res), len(recs), source)
+	if qc.Format != "table" && qc.OutPath != "" {
+		fmt.Fprintf(os.Stderr, ", written to %s", qc.OutPath)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+func loadQueryRecords(qc config.QueryConfig) ([]store.Record, string, error) {
+	if strings.TrimSpace(qc.XLSXIn) != "" {
+		if _, err := os.Stat(qc.XLSXIn); err != nil {
+			return nil, "", fmt.Errorf("archive xlsx: %w", err)
+		}
+		recs, err := localxlsx.ReadRecords(qc.XLSXIn, qc.SheetIn)
+		return recs, qc.XLSXIn, err
+	}
+	if _, err := os.Stat(qc.StoreFile); err != nil {
+		return nil, "", fmt.Errorf("archive store %s not found (run the archiver first or pass -xlsx): %w", qc.StoreFile, err)
+	}
+	st, err := store.Open(qc.StoreFile)
+	if err != nil {
+		return nil, "", err
+	}
+	defer st.Close()
+	recs, err := st.All()
+	return recs, qc.StoreFile, err
+}
+
+func loadResolver(cfg config.Config) (*charalias.Resolver, error) {
+	engineRoot, err := resolveEngineRoot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return charalias.LoadFromEngineRoot(engineRoot)
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/sheetsapi"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/sim"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// Resim re-simulates archived configs with the local engine and writes current-engine DPS,
+// the delta versus the posted DPS and the status back into the store, archive.xlsx and
+// (unless dryRun) Google Sheets. Only records without a re-simulation are run unless all is set.
+func Resim(ctx context.Context, cfg config.Config, all bool) error {
+	if strings.TrimSpace(cfg.Engine) == "" && strings.TrimSpace(cfg.EnginePath) == "" {
+		return errors.New("resim requires engine or enginePath in the config")
+	}
+	engineRoot, err := resolveEngineRoot(cfg)
+	if err != nil {
+		return err
+	}
+	runner := sim.CLIRunner{EngineRoot: engineRoot, Iterations: cfg.Resim.Iterations}
+	if err := runner.Check(); err != nil {
+		return err
+	}
+
+	var sheets *sheetsapi.Client
+	if !cfg.Run.DryRun {
+		sheets = sheetsapi.New(cfg.AppsScript.WebAppURL, cfg.AppsScript.APIKey, cfg.Sheet.ID, cfg.Sheet.Name)
+	}
+
+	archive, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	recs, err := archive.All()
+	if err != nil {
+		return fmt.Errorf("read store: %w", err)
+	}
+	todo := make([]store.Record, 0, len(recs))
+	for _, rec := range recs {
+		if all || rec.ResimStatus == "" {
+			todo = append(todo, rec)
+		}
+	}
+	fmt.Printf("Re-simulating %d of %d archived configs (engine root=%s)\n", len(todo), len(recs), engineRoot)
+
+	workDir := filepath.Join("work", "wfpsim_discord_archiver", "resim")
+	counts := map[string]int{}
+	for i, rec := range todo {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fmt.Printf("[%d/%d] %s\n", i+1, len(todo), rec.Key)
+		rec, err = ResimRecord(ctx, runner, workDir, rec)
+		if err != nil {
+			return fmt.Errorf("resim %s: %w", rec.Key, err)
+		}
+		if rec.ResimStatus == store.ResimInvalid {
+			fmt.Fprintf(os.Stderr, "warn: config of %s no longer runs: %s\n", rec.Key, rec.ResimError)
+		}
+		counts[rec.ResimStatus]++
+		if err := archive.Put(rec); err != nil {
+			return err
+		}
+		if sheets != nil {
+			if err := sheets.UpdateRow(ctx, store.RowFromRecord(rec)); err != nil {
+				return fmt.Errorf("sheet update %s: %w", rec.Key, err)
+			}
+		}
+	}
+
+	if err := archive.Compact(); err != nil {
+		return err
+	}
+	if len(todo) > 0 {
+		if err := exportXLSX(archive, cfg.Sheet.Name); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("done. ok: %d, invalid: %d, no config: %d\n", counts[store.ResimOK], counts[store.ResimInvalid], counts[store.ResimNoConfig])
+	return nil
+}
+
+// ResimRecord runs rec's config; a config the engine rejects is recorded as ResimInvalid,
+// other failures (engine missing, unreadable output) are returned.
+func ResimRecord(ctx context.Context, runner sim.Runner, workDir string, rec store.Record) (store.Record, error) {
+	rec.ResimAt = time.Now().Format(time.RFC3339)
+	rec.ResimDpsMean = 0
+	rec.ResimDeltaPct = 0
+	rec.ResimSimVersion = ""
+	rec.ResimError = ""
+
+	if strings.TrimSpace(rec.ConfigFile) == "" {
+		rec.ResimStatus = store.ResimNoConfig
+		return rec, nil
+	}
+
+	res, err := runner.Run(ctx, workDir, rec.ConfigFile)
+	if err != nil {
+		var cerr *sim.ConfigError
+		if errors.As(err, &cerr) {
+			rec.ResimStatus = store.ResimInvalid
+			rec.ResimError = cerr.Summary()
+			return rec, nil
+		}
+		return rec, err
+	}
+
+	rec.ResimStatus = store.ResimOK
+	rec.ResimDpsMean = *res.Statistics.DPS.Mean
+	rec.ResimSimVersion = res.SimVersion
+	if rec.TeamDpsMean > 0 {
+		rec.ResimDeltaPct = math.Round((rec.ResimDpsMean/rec.TeamDpsMean-1)*1000) / 10
+	}
+	return rec, nil
+}
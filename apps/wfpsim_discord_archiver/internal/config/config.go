@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/dedup"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,6 +19,9 @@ type Config struct {
 	AppsScript AppsScriptConfig
 	Sheet      SheetConfig
 	Run        RunConfig
+	Resim      ResimConfig
+	Daemon     DaemonConfig
+	Dedup      DedupConfig
 }
 
 type DiscordConfig struct {
@@ -38,11 +42,43 @@ type SheetConfig struct {
 
 type RunConfig struct {
 	StateFile string `yaml:"stateFile"`
+	// JSONL store of archived shares (source of truth for output/wfpsim_discord_archiver/archive.xlsx).
+	StoreFile string `yaml:"storeFile"`
 	SinceDays int    `yaml:"sinceDays"`
 	Mode      string `yaml:"mode"`
 	// If true, ignores state checkpoints (lastSearchMessageIds for guildSearch and per-channel lastSeenMessageId for channelHistory).
 	IgnoreStateCheckpoint bool `yaml:"ignoreStateCheckpoint"`
 	DryRun                bool `yaml:"dryRun"`
+	// DiscordChatExporter JSON/HTML files (glob patterns allowed) read in run.mode=export.
+	ExportFiles []string `yaml:"exportFiles"`
+	// Share fetching: FetchWorkers concurrent requests, at most FetchRate requests per second
+	// (bursts of up to FetchWorkers; 0 = unlimited, unset = 2).
+	FetchWorkers int      `yaml:"fetchWorkers"`
+	FetchRate    *float64 `yaml:"fetchRate"`
+	// Failed share fetches are retried on later runs, waiting FailedRetryHours (doubled per
+	// failure, up to a week), and given up after FailedMaxAttempts failures or on 404.
+	FailedMaxAttempts int `yaml:"failedMaxAttempts"`
+	FailedRetryHours  int `yaml:"failedRetryHours"`
+}
+
+// ResimConfig configures re-simulation of archived configs (-resim).
+type ResimConfig struct {
+	// Iterations overrides the iteration count of archived configs (0 keeps the config's own).
+	Iterations int `yaml:"iterations"`
+}
+
+// DaemonConfig configures the long-running mode (-daemon).
+type DaemonConfig struct {
+	// IntervalMinutes is the pause between the end of one run and the start of the next.
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	// Listen is the address of the health/status endpoint ("off" disables it).
+	Listen string `yaml:"listen"`
+}
+
+// DedupConfig configures grouping of archived shares with the same normalised config.
+type DedupConfig struct {
+	// Keep selects the canonical row of a group: "dps" (highest TeamDpsMean) or "newest".
+	Keep string `yaml:"keep"`
 }
 
 type FileConfig struct {
@@ -52,6 +88,9 @@ type FileConfig struct {
 	AppsScript AppsScriptConfig `yaml:"appsScript"`
 	Sheet      SheetConfig      `yaml:"sheet"`
 	Run        RunConfig        `yaml:"run"`
+	Resim      ResimConfig      `yaml:"resim"`
+	Daemon     DaemonConfig     `yaml:"daemon"`
+	Dedup      DedupConfig      `yaml:"dedup"`
 }
 
 func Load(configPath string) (Config, error) {
@@ -70,11 +109,41 @@ func Load(configPath string) (Config, error) {
 	cfg.AppsScript = fileCfg.AppsScript
 	cfg.Sheet = fileCfg.Sheet
 	cfg.Run = fileCfg.Run
+	cfg.Resim = fileCfg.Resim
+	cfg.Daemon = fileCfg.Daemon
+	cfg.Dedup = fileCfg.Dedup
 
 	// Defaults
 	if strings.TrimSpace(cfg.Run.StateFile) == "" {
 		cfg.Run.StateFile = filepath.Clean("work/wfpsim_discord_archiver/state.json")
 	}
+	if strings.TrimSpace(cfg.Run.StoreFile) == "" {
+		cfg.Run.StoreFile = filepath.Clean("work/wfpsim_discord_archiver/archive.jsonl")
+	}
+	if cfg.Run.FetchWorkers == 0 {
+		cfg.Run.FetchWorkers = 4
+	}
+	if cfg.Run.FetchRate == nil {
+		rate := 2.0
+		cfg.Run.FetchRate = &rate
+	}
+	if cfg.Run.FailedMaxAttempts == 0 {
+		cfg.Run.FailedMaxAttempts = 5
+	}
+	if cfg.Run.FailedRetryHours == 0 {
+		cfg.Run.FailedRetryHours = 6
+	}
+	if cfg.Daemon.IntervalMinutes == 0 {
+		cfg.Daemon.IntervalMinutes = 60
+	}
+	cfg.Daemon.Listen = strings.TrimSpace(cfg.Daemon.Listen)
+	if cfg.Daemon.Listen == "" {
+		cfg.Daemon.Listen = "127.0.0.1:8787"
+	}
+	cfg.Dedup.Keep = strings.ToLower(strings.TrimSpace(cfg.Dedup.Keep))
+	if cfg.Dedup.Keep == "" {
+		cfg.Dedup.Keep = dedup.KeepDPS
+	}
 	if cfg.Run.SinceDays == 0 {
 		cfg.Run.SinceDays = 30
 	}
@@ -82,7 +151,8 @@ func Load(configPath string) (Config, error) {
 		cfg.Run.Mode = "channelHistory"
 	}
 
-	if strings.TrimSpace(cfg.Discord.Token) == "" {
+	// Export mode reads files only and does not need a bot token.
+	if strings.TrimSpace(cfg.Discord.Token) == "" && cfg.Run.Mode != "export" {
 		return Config{}, errors.New("missing discord.token")
 	}
 	cfg.Discord.ServerIDs = normalizeIDs(cfg.Discord.ServerIDs...)
@@ -98,8 +168,34 @@ func Load(configPath string) (Config, error) {
 			return Config{}, errors.New("missing discord.serverIds")
 		}
 		// channelIds optional (can be used to narrow search later)
+	case "export":
+		if len(cfg.Run.ExportFiles) == 0 {
+			return Config{}, errors.New("missing run.exportFiles")
+		}
 	default:
-		return Config{}, fmt.Errorf("invalid run.mode: %s (expected channelHistory|guildSearch)", cfg.Run.Mode)
+		return Config{}, fmt.Errorf("invalid run.mode: %s (expected channelHistory|guildSearch|export)", cfg.Run.Mode)
+	}
+
+	if cfg.Run.FetchWorkers < 0 {
+		return Config{}, fmt.Errorf("invalid run.fetchWorkers: %d", cfg.Run.FetchWorkers)
+	}
+	if *cfg.Run.FetchRate < 0 {
+		return Config{}, fmt.Errorf("invalid run.fetchRate: %v", *cfg.Run.FetchRate)
+	}
+	if cfg.Run.FailedMaxAttempts < 0 {
+		return Config{}, fmt.Errorf("invalid run.failedMaxAttempts: %d", cfg.Run.FailedMaxAttempts)
+	}
+	if cfg.Run.FailedRetryHours < 0 {
+		return Config{}, fmt.Errorf("invalid run.failedRetryHours: %d", cfg.Run.FailedRetryHours)
+	}
+	if cfg.Daemon.IntervalMinutes < 0 {
+		return Config{}, fmt.Errorf("invalid daemon.intervalMinutes: %d", cfg.Daemon.IntervalMinutes)
+	}
+	if cfg.Dedup.Keep != dedup.KeepDPS && cfg.Dedup.Keep != dedup.KeepNewest {
+		return Config{}, fmt.Errorf("invalid dedup.keep: %q (expected dps or newest)", cfg.Dedup.Keep)
+	}
+	if cfg.Resim.Iterations < 0 {
+		return Config{}, fmt.Errorf("invalid resim.iterations: %d", cfg.Resim.Iterations)
 	}
 
 	if cfg.Run.SinceDays <= 0 {
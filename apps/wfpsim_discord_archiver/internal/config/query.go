@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/query"
+)
+
+// QueryConfig is the "query" subcommand: filter the archive and print or save the best teams.
+type QueryConfig struct {
+	// StoreFile is the archive store; XLSXIn reads an archive workbook instead.
+	StoreFile string
+	XLSXIn    string
+	SheetIn   string
+
+	Filter query.Filter
+	// Engine/EnginePath (-engine/-engine-path, else engine/enginePath of the archiver config)
+	// let -chars and -cons match character aliases.
+	Engine     string
+	EnginePath string
+
+	// Format is table, csv or xlsx.
+	Format    string
+	OutPath   string
+	SheetName string
+}
+
+// LoadQuery parses the arguments of the "query" subcommand.
+func LoadQuery(args []string) (QueryConfig, error) {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cfg := QueryConfig{}
+	var chars, cons, weapons, since, until string
+	var dups bool
+	var configPath string
+	fs.StringVar(&cfg.StoreFile, "store", filepath.Clean("work/wfpsim_discord_archiver/archive.jsonl"), "archive store (run.storeFile)")
+	fs.StringVar(&cfg.XLSXIn, "xlsx", "", "read an archive .xlsx instead of the store")
+	fs.StringVar(&cfg.SheetIn, "xlsx-sheet", "wfpsim", "sheet of -xlsx")
+	fs.StringVar(&chars, "chars", "", "characters that must all be in the team (comma-separated)")
+	fs.StringVar(&cons, "cons", "", "constellations, e.g. furina:0,raiden:2-6")
+	fs.StringVar(&weapons, "weapons", "", "weapons that must all be in the team (comma-separated)")
+	fs.Float64Var(&cfg.Filter.MinDPS, "min-dps", 0, "minimum TeamDpsMean")
+	fs.Float64Var(&cfg.Filter.MaxDPS, "max-dps", 0, "maximum TeamDpsMean")
+	fs.StringVar(&since, "since", "", "first message date, YYYY-MM-DD")
+	fs.StringVar(&until, "until", "", "last message date, YYYY-MM-DD")
+	fs.StringVar(&cfg.Filter.Author, "author", "", "message author (substring, case-insensitive)")
+	fs.BoolVar(&dups, "dups", false, "also list shares marked as duplicates of another share (DuplicateOf)")
+	fs.IntVar(&cfg.Filter.Limit, "limit", 20, "maximum number of teams (0 = all)")
+	fs.StringVar(&configPath, "config", "input/wfpsim_discord_archiver/config.yaml", "archiver config to take engine/enginePath from (optional)")
+	fs.StringVar(&cfg.Engine, "engine", "", "engine name under ./engines, for character aliases (default: the config's engine)")
+	fs.StringVar(&cfg.EnginePath, "engine-path", "", "explicit path to engine root (overrides -engine)")
+	fs.StringVar(&cfg.Format, "format", "table", "output format: table|csv|xlsx")
+	fs.StringVar(&cfg.OutPath, "out", "", "output file (csv: default stdout; xlsx: default output/wfpsim_discord_archiver/query.xlsx)")
+	fs.StringVar(&cfg.SheetName, "sheet", "query", "sheet written by -format xlsx")
+	if err := fs.Parse(args); err != nil {
+		return QueryConfig{}, err
+	}
+	if fs.NArg() > 0 {
+		return QueryConfig{}, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if cfg.Engine == "" && cfg.EnginePath == "" {
+		fileCfg, err := loadFileConfig(configPath)
+		if err != nil {
+			return QueryConfig{}, err
+		}
+		cfg.Engine = strings.TrimSpace(fileCfg.Engine)
+		cfg.EnginePath = strings.TrimSpace(fileCfg.EnginePath)
+	}
+
+	var err error
+	cfg.Filter.NoDuplicates = !dups
+	cfg.Filter.Chars = query.SplitList(chars)
+	cfg.Filter.Weapons = query.SplitList(weapons)
+	if cfg.Filter.Cons, err = query.ParseCons(cons); err != nil {
+		return QueryConfig{}, err
+	}
+	if cfg.Filter.Since, err = query.ParseDate(since); err != nil {
+		return QueryConfig{}, err
+	}
+	if cfg.Filter.Until, err = query.ParseDate(until); err != nil {
+		return QueryConfig{}, err
+	}
+	if cfg.Filter.MaxDPS > 0 && cfg.Filter.MinDPS > cfg.Filter.MaxDPS {
+		return QueryConfig{}, errors.New("-min-dps is greater than -max-dps")
+	}
+	if cfg.Filter.Limit < 0 {
+		return QueryConfig{}, fmt.Errorf("invalid -limit: %d", cfg.Filter.Limit)
+	}
+
+	switch cfg.Format {
+	case "table", "csv":
+	case "xlsx":
+		if strings.TrimSpace(cfg.OutPath) == "" {
+			cfg.OutPath = filepath.Clean("output/wfpsim_discord_archiver/query.xlsx")
+		}
+		if strings.TrimSpace(cfg.SheetName) == "" {
+			return QueryConfig{}, errors.New("-sheet is empty")
+		}
+	default:
+		return QueryConfig{}, fmt.Errorf("invalid -format: %s (expected table|csv|xlsx)", cfg.Format)
+	}
+	return cfg, nil
+}
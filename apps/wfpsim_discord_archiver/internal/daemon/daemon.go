@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Snapshot is the state of the daemon at one point in time (the JSON served at /status).
+type Snapshot struct {
+	Started        time.Time `json:"started"`
+	Running        bool      `json:"running"`
+	Runs           int       `json:"runs"`
+	FailedRuns     int       `json:"failedRuns"`
+	LastRunStarted time.Time `json:"lastRunStarted,omitzero"`
+	LastRunEnded   time.Time `json:"lastRunEnded,omitzero"`
+	LastNewKeys    int       `json:"lastNewKeys"`
+	TotalNewKeys   int       `json:"totalNewKeys"`
+	// LastError is the error of the last run ("" if it succeeded).
+	LastError   string    `json:"lastError,omitempty"`
+	LastErrorAt time.Time `json:"lastErrorAt,omitzero"`
+	NextRun     time.Time `json:"nextRun,omitzero"`
+}
+
+// Status tracks the daemon's runs. It is safe for concurrent use.
+type Status struct {
+	mu sync.Mutex
+	s  Snapshot
+}
+
+func NewStatus(started time.Time) *Status {
+	return &Status{s: Snapshot{Started: started}}
+}
+
+// Snapshot returns a copy of the current status.
+func (st *Status) Snapshot() Snapshot {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.s
+}
+
+func (st *Status) runStarted(now time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.s.Running = true
+	st.s.LastRunStarted = now
+	st.s.NextRun = time.Time{}
+}
+
+func (st *Status) runEnded(now time.Time, newKeys int, err error, next time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.s.Running = false
+	st.s.Runs++
+	st.s.LastRunEnded = now
+	st.s.LastNewKeys = newKeys
+	st.s.TotalNewKeys += newKeys
+	st.s.LastError = ""
+	if err != nil {
+		st.s.FailedRuns++
+		st.s.LastError = err.Error()
+		st.s.LastErrorAt = now
+	}
+	st.s.NextRun = next
+}
+
+// Handler serves /health (200 unless the last run failed, then 503) and /status (Snapshot as JSON).
+func (st *Status) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		s := st.Snapshot()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if s.LastError != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "last run failed: %s\n", s.LastError)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(st.Snapshot())
+	})
+	return mux
+}
+
+// StartServer serves st.Handler on addr in the background until ctx is cancelled.
+// The returned function waits for the server to shut down.
+func StartServer(ctx context.Context, addr string, st *Status) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("status endpoint: %w", err)
+	}
+	srv := &http.Server{Handler: st.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "status endpoint: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	return func() { <-done }, nil
+}
+
+// Loop calls run until ctx is cancelled, pausing interval after each run. run returns the number
+// of new keys; a failed run is reported in st and the loop goes on. Cancellation is not an error.
+func Loop(ctx context.Context, interval time.Duration, st *Status, run func(ctx context.Context) (int, error)) error {
+	for {
+		st.runStarted(time.Now())
+		n, err := run(ctx)
+		if ctx.Err() != nil {
+			st.runEnded(time.Now(), n, nil, time.Time{})
+			return nil
+		}
+		next := time.Now().Add(interval)
+		st.runEnded(time.Now(), n, err, next)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "run failed: %v\n", err)
+		}
+		fmt.Printf("Next run at %s\n", next.Format(time.RFC3339))
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+	}
+}
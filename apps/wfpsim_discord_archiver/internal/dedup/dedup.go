@@ -0,0 +1,138 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// Canonical row selection of a duplicate group (dedup.keep).
+const (
+	KeepDPS    = "dps"
+	KeepNewest = "newest"
+)
+
+var (
+	// "# ..." and "// ..." comments run to the end of the line.
+	commentRe = regexp.MustCompile(`(#|//)[^\r\n]*`)
+	spaceRe   = regexp.MustCompile(`\s+`)
+	// spaces around operators and separators carry no meaning
+	punctRe = regexp.MustCompile(`\s*([=,:;+\-*/<>()\[\]{}])\s*`)
+)
+
+// Normalize returns config in the canonical form used for fingerprints: lowercase, without
+// comments, without the options iteration count, one statement per line with single spaces,
+// and character aliases replaced by their canonical names (if resolver is set).
+func Normalize(config string, resolver *charalias.Resolver) string {
+	config = strings.ToLower(commentRe.ReplaceAllString(config, ""))
+
+	var out []string
+	for _, stmt := range strings.Split(config, ";") {
+		stmt = strings.TrimSpace(spaceRe.ReplaceAllString(stmt, " "))
+		if stmt == "" {
+			continue
+		}
+		stmt = punctRe.ReplaceAllString(stmt, "$1")
+		fields := strings.Split(stmt, " ")
+		switch {
+		case fields[0] == "options":
+			var opts []string
+			for _, o := range fields[1:] {
+				if !strings.HasPrefix(o, "iteration=") {
+					opts = append(opts, o)
+				}
+			}
+			if len(opts) == 0 {
+				// only the iteration count was set
+				continue
+			}
+			sort.Strings(opts)
+			fields = append(fields[:1], opts...)
+		case fields[0] == "active" && len(fields) > 1:
+			fields[1] = canonicalChar(fields[1], resolver)
+		default:
+			// "<char> char ...", "<char> add ...", "<char> skill,burst" etc.
+			fields[0] = canonicalChar(fields[0], resolver)
+		}
+		out = append(out, strings.Join(fields, " "))
+	}
+	return strings.Join(out, ";\n")
+}
+
+func canonicalChar(name string, resolver *charalias.Resolver) string {
+	if canon, ok := resolver.Canonicalize(name); ok {
+		return canon
+	}
+	return name
+}
+
+// Fingerprint identifies configs that are the same team setup and rotation ("" for an empty
+// config): the first 16 hex digits of the SHA-256 of Normalize(config).
+func Fingerprint(config string, resolver *charalias.Resolver) string {
+	n := Normalize(config, resolver)
+	if n == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(n))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Group links records with the same ConfigFingerprint: the canonical record of each group
+// (chosen by keep) gets an empty DuplicateOf, the others its key. Records without a fingerprint
+// are left alone. recs is updated in place; the records whose DuplicateOf changed are returned.
+func Group(recs []store.Record, keep string) []store.Record {
+	groups := map[string][]int{}
+	for i, r := range recs {
+		if r.ConfigFingerprint != "" {
+			groups[r.ConfigFingerprint] = append(groups[r.ConfigFingerprint], i)
+		}
+	}
+
+	var changed []store.Record
+	for _, idx := range groups {
+		best := idx[0]
+		for _, i := range idx[1:] {
+			if better(recs[i], recs[best], keep) {
+				best = i
+			}
+		}
+		for _, i := range idx {
+			dupOf := recs[best].Key
+			if i == best {
+				dupOf = ""
+			}
+			if recs[i].DuplicateOf != dupOf {
+				recs[i].DuplicateOf = dupOf
+				changed = append(changed, recs[i])
+			}
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Key < changed[j].Key })
+	return changed
+}
+
+// better reports whether a should be canonical instead of b.
+func better(a, b store.Record, keep string) bool {
+	ta, tb := messageTime(a), messageTime(b)
+	if keep == KeepNewest && !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	if a.TeamDpsMean != b.TeamDpsMean {
+		return a.TeamDpsMean > b.TeamDpsMean
+	}
+	if !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	return a.Key < b.Key
+}
+
+func messageTime(r store.Record) time.Time {
+	t, _ := time.Parse(time.RFC3339, strings.TrimSpace(r.MessageCreatedAt))
+	return t
+}
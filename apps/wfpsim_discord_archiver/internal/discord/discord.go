@@ -188,6 +188,22 @@ func parseSnowflake(id string) uint64 {
 	return n
 }
 
+// SnowflakeLess reports whether snowflake a is older than b (empty/invalid IDs sort first).
+func SnowflakeLess(a, b string) bool {
+	return parseSnowflake(a) < parseSnowflake(b)
+}
+
+// SnowflakeTime returns the creation time encoded in a Discord snowflake ID (zero if id is invalid).
+func SnowflakeTime(id string) time.Time {
+	n := parseSnowflake(id)
+	if n == 0 {
+		return time.Time{}
+	}
+	// Discord epoch: 2015-01-01T00:00:00.000Z
+	const discordEpochMs = int64(1420070400000)
+	return time.UnixMilli(int64(n>>22) + discordEpochMs).UTC()
+}
+
 // snowflakeFromTime returns a Discord snowflake ID that corresponds to the given time.
 // The ID is the smallest possible snowflake for that millisecond (lower bits = 0).
 func snowflakeFromTime(t time.Time) string {
@@ -0,0 +1,232 @@
+package discordexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
+)
+
+// Export is one channel exported by DiscordChatExporter (https://github.com/Tyrrrz/DiscordChatExporter).
+type Export struct {
+	Path      string
+	GuildID   string
+	ChannelID string
+	Messages  []discord.Message
+}
+
+// Load reads a DiscordChatExporter export; the format is chosen by extension (.json or .html/.htm).
+// Messages are returned in ascending ID order.
+func Load(path string) (Export, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Export{}, err
+	}
+
+	var ex Export
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		ex, err = parseJSON(b)
+	case ".html", ".htm":
+		ex, err = parseHTML(b, filepath.Base(path))
+	default:
+		return Export{}, fmt.Errorf("unsupported export format: %s (expected .json or .html)", path)
+	}
+	if err != nil {
+		return Export{}, fmt.Errorf("parse export %s: %w", path, err)
+	}
+	ex.Path = path
+	for i := range ex.Messages {
+		if ex.Messages[i].ChannelID == "" {
+			ex.Messages[i].ChannelID = ex.ChannelID
+		}
+		if ex.Messages[i].CreatedAt.IsZero() {
+			ex.Messages[i].CreatedAt = discord.SnowflakeTime(ex.Messages[i].ID)
+		}
+	}
+	sort.SliceStable(ex.Messages, func(i, j int) bool { return lessID(ex.Messages[i].ID, ex.Messages[j].ID) })
+	return ex, nil
+}
+
+// ExpandPaths resolves glob patterns into a sorted, deduplicated list of files.
+func ExpandPaths(patterns []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid export path pattern %q: %w", p, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no export files match %q", p)
+		}
+		for _, m := range matches {
+			m = filepath.Clean(m)
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			out = append(out, m)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+type jsonExport struct {
+	Guild struct {
+		ID string `json:"id"`
+	} `json:"guild"`
+	Channel struct {
+		ID      string `json:"id"`
+		GuildID string `json:"guildId"`
+	} `json:"channel"`
+	Messages []jsonMessage `json:"messages"`
+}
+
+type jsonMessage struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Content   string `json:"content"`
+	Author    struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Nickname string `json:"nickname"`
+	} `json:"author"`
+	Embeds []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+		Fields      []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+func parseJSON(b []byte) (Export, error) {
+	var raw jsonExport
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return Export{}, err
+	}
+	ex := Export{GuildID: raw.Guild.ID, ChannelID: raw.Channel.ID}
+	if ex.GuildID == "" {
+		ex.GuildID = raw.Channel.GuildID
+	}
+	// DMs are exported with a pseudo guild "0".
+	if ex.GuildID == "0" {
+		ex.GuildID = ""
+	}
+	for _, m := range raw.Messages {
+		// Share links posted by bots often only appear in embeds; keep them with the content.
+		parts := []string{m.Content}
+		for _, e := range m.Embeds {
+			parts = append(parts, e.URL, e.Title, e.Description)
+			for _, f := range e.Fields {
+				parts = append(parts, f.Name, f.Value)
+			}
+		}
+		created, _ := time.Parse(time.RFC3339, m.Timestamp)
+		author := m.Author.Nickname
+		if author == "" {
+			author = m.Author.Name
+		}
+		if author == "" {
+			author = m.Author.ID
+		}
+		ex.Messages = append(ex.Messages, discord.Message{
+			ID:        m.ID,
+			ChannelID: ex.ChannelID,
+			Author:    author,
+			Content:   strings.Join(parts, "\n"),
+			CreatedAt: created,
+		})
+	}
+	return ex, nil
+}
+
+var (
+	htmlMessageRe = regexp.MustCompile(`data-message-id="(\d+)"`)
+	htmlAuthorRe  = regexp.MustCompile(`class="chatlog__author"[^>]*>([^<]*)<`)
+	htmlTagRe     = regexp.MustCompile(`<[^>]*>`)
+	htmlHrefRe    = regexp.MustCompile(`(?i)href="([^"]*)"`)
+	// Default DiscordChatExporter file names end with the channel ID: "Guild - Channel [123].html".
+	fileChannelRe = regexp.MustCompile(`\[(\d{15,20})\]`)
+	// Channel links ("discord.com/channels/<guild>/<channel>") carry the guild ID.
+	channelLinkRe = regexp.MustCompile(`discord\.com/channels/(\d+)/(\d+)`)
+)
+
+// parseHTML extracts messages from an HTML export. HTML exports carry no guild/channel IDs in
+// the header, so the channel is taken from the default file name and the guild from a channel
+// link in the preamble (before the first message) or, failing that, from a link to the same
+// channel. Links to other channels inside messages are ignored.
+func parseHTML(b []byte, fileName string) (Export, error) {
+	doc := string(b)
+	locs := htmlMessageRe.FindAllStringSubmatchIndex(doc, -1)
+	if len(locs) == 0 {
+		return Export{}, fmt.Errorf("no messages found (not a DiscordChatExporter HTML export?)")
+	}
+
+	var ex Export
+	if m := fileChannelRe.FindStringSubmatch(fileName); m != nil {
+		ex.ChannelID = m[1]
+	}
+	if m := channelLinkRe.FindStringSubmatch(doc[:locs[0][0]]); m != nil && (ex.ChannelID == "" || ex.ChannelID == m[2]) {
+		ex.GuildID, ex.ChannelID = m[1], m[2]
+	} else if ex.ChannelID != "" {
+		for _, m := range channelLinkRe.FindAllStringSubmatch(doc, -1) {
+			if m[2] == ex.ChannelID {
+				ex.GuildID = m[1]
+				break
+			}
+		}
+	}
+
+	author := ""
+	for i, loc := range locs {
+		id := doc[loc[2]:loc[3]]
+		end := len(doc)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+			// stop before the opening tag of the next message container
+			if k := strings.LastIndex(doc[:end], "<"); k > loc[1] {
+				end = k
+			}
+		}
+		chunk := doc[loc[1]:end]
+
+		// Only the first message of a group shows the author; the rest inherit it.
+		if m := htmlAuthorRe.FindStringSubmatch(chunk); m != nil {
+			author = strings.TrimSpace(html.UnescapeString(m[1]))
+		}
+
+		parts := []string{html.UnescapeString(htmlTagRe.ReplaceAllString(chunk, " "))}
+		for _, h := range htmlHrefRe.FindAllStringSubmatch(chunk, -1) {
+			parts = append(parts, html.UnescapeString(h[1]))
+		}
+		ex.Messages = append(ex.Messages, discord.Message{
+			ID:      id,
+			Author:  author,
+			Content: strings.Join(parts, "\n"),
+		})
+	}
+	return ex, nil
+}
+
+func lessID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,94 @@
+package localxlsx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TeamCharsUI is the TeamCharactersUI cell for a team ("furina C0,neuvillette C1,...").
+func TeamCharsUI(teamChars string, teamCons string) string {
+	return buildTeamCharsUI(teamChars, teamCons)
+}
+
+// WriteSheet (re)creates sheetName in the workbook at path with a header row and rows;
+// other sheets of an existing workbook are kept.
+func WriteSheet(path string, sheetName string, header []string, rows [][]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	var f *excelize.File
+	if _, err := os.Stat(path); err == nil {
+		if f, err = excelize.OpenFile(path); err != nil {
+			return fmt.Errorf("xlsx open %s: %w", path, err)
+		}
+	} else {
+		f = excelize.NewFile()
+	}
+	defer func() { _ = f.Close() }()
+
+	placeholder := ""
+	if idx, err := f.GetSheetIndex(sheetName); err == nil && idx >= 0 {
+		if len(f.GetSheetList()) == 1 {
+			// A workbook needs at least one sheet: add a placeholder while replacing the only one.
+			placeholder = sheetName + "_tmp"
+			if _, err := f.NewSheet(placeholder); err != nil {
+				return err
+			}
+		}
+		if err := f.DeleteSheet(sheetName); err != nil {
+			return fmt.Errorf("xlsx delete sheet %q: %w", sheetName, err)
+		}
+	}
+	idx, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("xlsx new sheet: %w", err)
+	}
+	f.SetActiveSheet(idx)
+	if placeholder != "" {
+		_ = f.DeleteSheet(placeholder)
+		f.SetActiveSheet(mustIndex(f, sheetName))
+	}
+	// Drop the empty default sheet of a new workbook.
+	if sheetName != "Sheet1" {
+		if rows, err := f.GetRows("Sheet1"); err == nil && len(rows) == 0 {
+			_ = f.DeleteSheet("Sheet1")
+			f.SetActiveSheet(mustIndex(f, sheetName))
+		}
+	}
+
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return fmt.Errorf("xlsx stream writer: %w", err)
+	}
+	headerRow := make([]interface{}, len(header))
+	for c, v := range header {
+		headerRow[c] = v
+	}
+	if err := sw.SetRow("A1", headerRow); err != nil {
+		return err
+	}
+	for r, row := range rows {
+		if err := sw.SetRow(cellName(0, r+2), row); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("xlsx save %s: %w", path, err)
+	}
+	return nil
+}
+
+func mustIndex(f *excelize.File, sheetName string) int {
+	idx, err := f.GetSheetIndex(sheetName)
+	if err != nil || idx < 0 {
+		return 0
+	}
+	return idx
+}
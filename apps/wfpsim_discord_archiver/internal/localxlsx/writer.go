@@ -1,16 +1,16 @@
 package localxlsx
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
-	"time"
 
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -49,146 +49,48 @@ var header = []string{
 	"SimVersion",
 	"SchemaMajor",
 	"SchemaMinor",
+	"Provider",
+	"ResimDpsMean",
+	"ResimDeltaPct",
+	"ResimStatus",
+	"ResimSimVersion",
+	"ResimAt",
+	"CharDpsShare",
+	"ArtifactSets",
+	"TalentLevels",
+	"ConfigFingerprint",
+	"DuplicateOf",
 }
 
-// Indexes in the incoming row produced by buildRow (kept stable for Apps Script).
-const (
-	idxFetchedAt               = 0
-	idxDiscordGuildID          = 1
-	idxDiscordChannelID        = 2
-	idxDiscordMessageID        = 3
-	idxDiscordMessageURL       = 4
-	idxDiscordAuthor           = 5
-	idxDiscordMessageCreatedAt = 6
-	idxKey                     = 7
-	idxShareURL                = 8
-	idxTeamCharacters          = 9
-	idxTeamWeapons             = 10
-	idxTeamDpsMean             = 11
-	idxTeamDpsQ2               = 12
-	idxConfigFile              = 13
-	idxSimVersion              = 14
-	idxSchemaMajor             = 15
-	idxSchemaMinor             = 16
-	idxTeamConstellations      = 17
-)
-
 type record struct {
 	Key           string
 	TeamCharsUI   string
 	TeamCharsSort string
 	TeamConsSort  string
 	DpsMean       float64
-	FetchedAtTime time.Time
 	Row           []interface{}
 }
 
-func (w *Writer) AppendRow(ctx context.Context, row []interface{}, key string, messageID string) error {
-	_ = ctx
-	_ = messageID
-	if strings.TrimSpace(key) == "" {
-		return fmt.Errorf("xlsx writer: empty key")
-	}
-
+// Export rewrites the workbook from recs in one pass, following TABLE_RULES.md
+// (unique keys, sorted by team / constellation block / DPS, grouped first column).
+func (w *Writer) Export(recs []store.Record) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	rec, err := recordFromRow(row, key)
-	if err != nil {
-		return err
-	}
-
-	// Load existing file (if any)
-	recsByKey := map[string]record{}
-	if _, err := os.Stat(w.path); err == nil {
-		f, err := excelize.OpenFile(w.path)
-		if err != nil {
-			return fmt.Errorf("xlsx open %s: %w", w.path, err)
-		}
-		defer func() { _ = f.Close() }()
-
-		rows, err := f.GetRows(w.sheetName)
-		if err != nil {
-			// If the sheet doesn't exist, treat as empty
-			rows = nil
-		}
-
-		colIndex := map[string]int{}
-		if len(rows) > 0 {
-			for i, h := range rows[0] {
-				name := strings.TrimSpace(h)
-				if name == "" {
-					continue
-				}
-				colIndex[name] = i
-			}
-		}
-
-		for i, r := range rows {
-			// assume first row is header
-			if i == 0 {
-				continue
-			}
-			existing, ok := recordFromStrings(r, colIndex)
-			if !ok {
-				continue
-			}
-			if existing.Key == "" {
-				continue
-			}
-			recsByKey[existing.Key] = existing
-		}
-	}
-
-	// Do not overwrite existing keys.
-	if _, ok := recsByKey[rec.Key]; !ok {
-		recsByKey[rec.Key] = rec
-	}
-
-	recs := make([]record, 0, len(recsByKey))
-	for _, r := range recsByKey {
-		recs = append(recs, r)
-	}
-
-	// Precompute max DPS per (team characters + constellations) block.
-	blockMax := map[string]float64{}
+	seen := make(map[string]struct{}, len(recs))
+	rows := make([]record, 0, len(recs))
 	for _, r := range recs {
-		k := blockKey(r.TeamCharsSort, r.TeamConsSort)
-		if cur, ok := blockMax[k]; !ok || r.DpsMean > cur {
-			blockMax[k] = r.DpsMean
-		}
-	}
-
-	sort.Slice(recs, func(i, j int) bool {
-		ai := recs[i].TeamCharsSort
-		aj := recs[j].TeamCharsSort
-		// Empty TeamCharacters should sort last.
-		if ai == "" && aj != "" {
-			return false
-		}
-		if aj == "" && ai != "" {
-			return true
-		}
-		if ai != aj {
-			return ai < aj
-		}
-
-		// Within the same team: split into constellation blocks.
-		bi := blockMax[blockKey(recs[i].TeamCharsSort, recs[i].TeamConsSort)]
-		bj := blockMax[blockKey(recs[j].TeamCharsSort, recs[j].TeamConsSort)]
-		if bi != bj {
-			return bi > bj
-		}
-		// Tie-break constellation blocks deterministically.
-		if recs[i].TeamConsSort != recs[j].TeamConsSort {
-			return recs[i].TeamConsSort < recs[j].TeamConsSort
+		key := store.NormalizeKey(r.Key)
+		if key == "" {
+			continue
 		}
-		// Within a block: sort by DPS.
-		if recs[i].DpsMean != recs[j].DpsMean {
-			return recs[i].DpsMean > recs[j].DpsMean
+		if _, ok := seen[key]; ok {
+			continue
 		}
-		return recs[i].Key < recs[j].Key
-	})
+		seen[key] = struct{}{}
+		rows = append(rows, recordFromStore(r))
+	}
+	sortRecords(rows)
 
 	// Write a fresh workbook to temp and rename atomically.
 	dir := filepath.Dir(w.path)
@@ -209,18 +111,21 @@ func (w *Writer) AppendRow(ctx context.Context, row []interface{}, key string, m
 		_ = f.DeleteSheet("Sheet1")
 	}
 
-	// Header
+	sw, err := f.NewStreamWriter(w.sheetName)
+	if err != nil {
+		return fmt.Errorf("xlsx stream writer: %w", err)
+	}
+	headerRow := make([]interface{}, len(header))
 	for c, v := range header {
-		cell := cellName(c, 1)
-		if err := f.SetCellValue(w.sheetName, cell, v); err != nil {
-			return err
-		}
+		headerRow[c] = v
+	}
+	if err := sw.SetRow("A1", headerRow); err != nil {
+		return err
 	}
 
 	// Data
 	prevUI := ""
-	for rIdx, rec := range recs {
-		rowNum := rIdx + 2
+	for rIdx, rec := range rows {
 		norm := normalizeRow(rec.Row)
 		curUI := rec.TeamCharsUI
 		if curUI != "" && curUI == prevUI {
@@ -230,13 +135,13 @@ func (w *Writer) AppendRow(ctx context.Context, row []interface{}, key string, m
 			// Ensure the first row of a group is filled.
 			norm[0] = curUI
 		}
-		for c, v := range norm {
-			cell := cellName(c, rowNum)
-			if err := f.SetCellValue(w.sheetName, cell, v); err != nil {
-				return err
-			}
+		if err := sw.SetRow(cellName(0, rIdx+2), norm); err != nil {
+			return err
 		}
 	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
 
 	// excelize determines format by extension; keep .xlsx for temp files.
 	tmp := w.path + ".tmp.xlsx"
@@ -255,6 +160,96 @@ func (w *Writer) AppendRow(ctx context.Context, row []interface{}, key string, m
 	return nil
 }
 
+// ReadRecords reads the records of an existing archive workbook (columns are matched by header
+// name), e.g. to migrate it into the store. A missing file yields no records.
+func ReadRecords(path string, sheetName string) ([]store.Record, error) {
+	if strings.TrimSpace(sheetName) == "" {
+		sheetName = "wfpsim"
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx read sheet %q of %s: %w", sheetName, path, err)
+	}
+	colIndex := map[string]int{}
+	if len(rows) > 0 {
+		for i, h := range rows[0] {
+			name := strings.TrimSpace(h)
+			if name == "" {
+				continue
+			}
+			colIndex[name] = i
+		}
+	}
+
+	var out []store.Record
+	for i, r := range rows {
+		// assume first row is header
+		if i == 0 {
+			continue
+		}
+		rec, ok := storeRecordFromStrings(r, colIndex)
+		if !ok {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func sortRecords(recs []record) {
+	// Precompute max DPS per (team characters + constellations) block.
+	blockMax := map[string]float64{}
+	for _, r := range recs {
+		k := blockKey(r.TeamCharsSort, r.TeamConsSort)
+		if cur, ok := blockMax[k]; !ok || r.DpsMean > cur {
+			blockMax[k] = r.DpsMean
+		}
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		ai := recs[i].TeamCharsSort
+		aj := recs[j].TeamCharsSort
+		// Empty TeamCharacters should sort last.
+		if ai == "" && aj != "" {
+			return false
+		}
+		if aj == "" && ai != "" {
+			return true
+		}
+		if ai != aj {
+			return ai < aj
+		}
+
+		// Within the same team: split into constellation blocks.
+		bi := blockMax[blockKey(recs[i].TeamCharsSort, recs[i].TeamConsSort)]
+		bj := blockMax[blockKey(recs[j].TeamCharsSort, recs[j].TeamConsSort)]
+		if bi != bj {
+			return bi > bj
+		}
+		// Tie-break constellation blocks deterministically.
+		if recs[i].TeamConsSort != recs[j].TeamConsSort {
+			return recs[i].TeamConsSort < recs[j].TeamConsSort
+		}
+		// Within a block: sort by DPS.
+		if recs[i].DpsMean != recs[j].DpsMean {
+			return recs[i].DpsMean > recs[j].DpsMean
+		}
+		return recs[i].Key < recs[j].Key
+	})
+}
+
 func normalizeRow(row []interface{}) []interface{} {
 	out := make([]interface{}, len(header))
 	for i := 0; i < len(out); i++ {
@@ -267,137 +262,123 @@ func normalizeRow(row []interface{}) []interface{} {
 	return out
 }
 
-func recordFromRow(row []interface{}, key string) (record, error) {
-	key = strings.ToLower(strings.TrimSpace(key))
-	if key == "" {
-		return record{}, fmt.Errorf("xlsx writer: empty key")
+// recordFromStore lays a store record out in the local XLSX header order.
+func recordFromStore(r store.Record) record {
+	teamChars := strings.TrimSpace(r.TeamCharacters)
+	teamCons := strings.TrimSpace(r.TeamConstellations)
+	ordered := []interface{}{
+		buildTeamCharsUI(teamChars, teamCons),
+		r.TeamWeapons,
+		r.TeamDpsMean,
+		r.ShareURL,
+		r.ConfigFile,
+		r.MessageCreatedAt,
+		r.Author,
+		teamChars,
+		teamCons,
+
+		r.FetchedAt,
+		r.GuildID,
+		r.ChannelID,
+		r.MessageID,
+		r.MessageURL,
+		store.NormalizeKey(r.Key),
+		r.TeamDpsQ2,
+		r.SimVersion,
+		r.SchemaMajor,
+		r.SchemaMinor,
+		recordProvider(r),
+	}
+	ordered = append(ordered, store.ResimCells(r)...)
+	ordered = append(ordered, store.CharacterCells(r)...)
+	ordered = append(ordered, store.DedupCells(r)...)
+	return record{
+		Key:           store.NormalizeKey(r.Key),
+		TeamCharsUI:   fmt.Sprint(ordered[0]),
+		TeamCharsSort: teamChars,
+		TeamConsSort:  teamCons,
+		DpsMean:       r.TeamDpsMean,
+		Row:           normalizeRow(ordered),
 	}
-
-	// Reorder to the local XLSX header layout.
-	ordered := make([]interface{}, len(header))
-	get := func(idx int) interface{} {
-		if idx >= 0 && idx < len(row) {
-			return row[idx]
-		}
-		return ""
-	}
-	teamChars := strings.TrimSpace(fmt.Sprint(get(idxTeamCharacters)))
-	teamCons := strings.TrimSpace(fmt.Sprint(get(idxTeamConstellations)))
-	ordered[0] = buildTeamCharsUI(teamChars, teamCons)
-	ordered[1] = get(idxTeamWeapons)
-	ordered[2] = get(idxTeamDpsMean)
-	ordered[3] = get(idxShareURL)
-	ordered[4] = get(idxConfigFile)
-	ordered[5] = get(idxDiscordMessageCreatedAt)
-	ordered[6] = get(idxDiscordAuthor)
-	ordered[7] = teamChars
-	ordered[8] = teamCons
-
-	ordered[9] = get(idxFetchedAt)
-	ordered[10] = get(idxDiscordGuildID)
-	ordered[11] = get(idxDiscordChannelID)
-	ordered[12] = get(idxDiscordMessageID)
-	ordered[13] = get(idxDiscordMessageURL)
-	ordered[14] = key
-	ordered[15] = get(idxTeamDpsQ2)
-	ordered[16] = get(idxSimVersion)
-	ordered[17] = get(idxSchemaMajor)
-	ordered[18] = get(idxSchemaMinor)
-
-	rec := record{Key: key, Row: normalizeRow(ordered)}
-	rec.TeamCharsUI = strings.TrimSpace(fmt.Sprint(rec.Row[0]))
-	rec.TeamCharsSort = strings.TrimSpace(fmt.Sprint(rec.Row[7]))
-	rec.TeamConsSort = strings.TrimSpace(fmt.Sprint(rec.Row[8]))
-
-	// DPS mean
-	rec.DpsMean = parseFloat(rec.Row[2])
-
-	// FetchedAt parse
-	if s, ok := rec.Row[9].(string); ok {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			rec.FetchedAtTime = t
-		}
-	}
-
-	return rec, nil
 }
 
-func recordFromStrings(r []string, colIndex map[string]int) (record, bool) {
+func storeRecordFromStrings(r []string, colIndex map[string]int) (store.Record, bool) {
 	if len(r) == 0 {
-		return record{}, false
+		return store.Record{}, false
 	}
+	// Minimal positional fallback if header mapping is missing.
 	get := func(name string) string {
 		idx, ok := colIndex[name]
 		if !ok {
-			return ""
+			if len(colIndex) > 0 {
+				// Column missing from an older layout (e.g. Provider).
+				return ""
+			}
+			idx = slices.Index(header, name)
 		}
 		if idx < 0 || idx >= len(r) {
 			return ""
 		}
-		return r[idx]
+		return strings.TrimSpace(r[idx])
 	}
 
-	key := strings.ToLower(strings.TrimSpace(get("Key")))
+	key := store.NormalizeKey(get("Key"))
 	if key == "" {
 		// fallback for files without headers or older formats
 		if len(r) > 7 {
-			key = strings.ToLower(strings.TrimSpace(r[7]))
+			key = store.NormalizeKey(r[7])
 		}
 	}
 	if key == "" {
-		return record{}, false
+		return store.Record{}, false
 	}
 
-	teamChars := strings.TrimSpace(get("TeamCharacters"))
-	teamCons := strings.TrimSpace(get("TeamConstellations"))
-	teamCharsUI := strings.TrimSpace(get("TeamCharactersUI"))
-	if teamCharsUI == "" {
-		teamCharsUI = buildTeamCharsUI(teamChars, teamCons)
-	}
+	teamChars := get("TeamCharacters")
+	teamCons := get("TeamConstellations")
 	if teamChars == "" {
-		teamChars = stripConsFromUI(teamCharsUI)
-	}
-
-	ordered := make([]interface{}, len(header))
-	ordered[0] = teamCharsUI
-	ordered[1] = get("TeamWeapons")
-	ordered[2] = get("TeamDpsMean")
-	ordered[3] = get("ShareURL")
-	ordered[4] = get("ConfigFile")
-	ordered[5] = get("DiscordMessageCreatedAt")
-	ordered[6] = get("DiscordAuthor")
-	ordered[7] = teamChars
-	ordered[8] = teamCons
-
-	ordered[9] = get("FetchedAt")
-	ordered[10] = get("DiscordGuildID")
-	ordered[11] = get("DiscordChannelID")
-	ordered[12] = get("DiscordMessageID")
-	ordered[13] = get("DiscordMessageURL")
-	ordered[14] = key
-	ordered[15] = get("TeamDpsQ2")
-	ordered[16] = get("SimVersion")
-	ordered[17] = get("SchemaMajor")
-	ordered[18] = get("SchemaMinor")
-
-	// Minimal positional fallback if header mapping is missing.
-	for i := 0; i < len(ordered); i++ {
-		if strings.TrimSpace(fmt.Sprint(ordered[i])) == "" && i < len(r) {
-			ordered[i] = r[i]
-		}
-	}
+		// TeamCharactersUI is blank on all but the first row of a group; only use it when set.
+		teamChars = stripConsFromUI(get("TeamCharactersUI"))
+	}
+
+	return store.Record{
+		FetchedAt:          get("FetchedAt"),
+		GuildID:            get("DiscordGuildID"),
+		ChannelID:          get("DiscordChannelID"),
+		MessageID:          get("DiscordMessageID"),
+		MessageURL:         get("DiscordMessageURL"),
+		Author:             get("DiscordAuthor"),
+		MessageCreatedAt:   get("DiscordMessageCreatedAt"),
+		Key:                key,
+		ShareURL:           get("ShareURL"),
+		TeamCharacters:     teamChars,
+		TeamWeapons:        get("TeamWeapons"),
+		TeamDpsMean:        store.ParseFloat(get("TeamDpsMean")),
+		TeamDpsQ2:          store.ParseFloat(get("TeamDpsQ2")),
+		ConfigFile:         get("ConfigFile"),
+		SimVersion:         get("SimVersion"),
+		SchemaMajor:        store.ParseInt(get("SchemaMajor")),
+		SchemaMinor:        store.ParseInt(get("SchemaMinor")),
+		TeamConstellations: teamCons,
+		Provider:           get("Provider"),
+		ResimDpsMean:       store.ParseFloat(get("ResimDpsMean")),
+		ResimDeltaPct:      store.ParseFloat(get("ResimDeltaPct")),
+		ResimStatus:        get("ResimStatus"),
+		ResimSimVersion:    get("ResimSimVersion"),
+		ResimAt:            get("ResimAt"),
+		CharDpsShare:       get("CharDpsShare"),
+		ArtifactSets:       get("ArtifactSets"),
+		TalentLevels:       get("TalentLevels"),
+		ConfigFingerprint:  get("ConfigFingerprint"),
+		DuplicateOf:        store.NormalizeKey(get("DuplicateOf")),
+	}, true
+}
 
-	rec := record{Key: key, Row: normalizeRow(ordered)}
-	rec.TeamCharsUI = strings.TrimSpace(fmt.Sprint(rec.Row[0]))
-	rec.TeamCharsSort = strings.TrimSpace(fmt.Sprint(rec.Row[7]))
-	rec.TeamConsSort = strings.TrimSpace(fmt.Sprint(rec.Row[8]))
-	rec.DpsMean = parseFloat(rec.Row[2])
-	if s, ok := rec.Row[9].(string); ok {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			rec.FetchedAtTime = t
-		}
+// recordProvider fills in the provider of records archived before the Provider column existed.
+func recordProvider(r store.Record) string {
+	if p := strings.TrimSpace(r.Provider); p != "" {
+		return p
 	}
-	return rec, true
+	return shareurl.ProviderOf(r.Key)
 }
 
 func buildTeamCharsUI(teamChars string, teamCons string) string {
@@ -461,18 +442,6 @@ func blockKey(teamChars string, teamCons string) string {
 	return teamChars + "\x1f" + teamCons
 }
 
-func parseFloat(v interface{}) float64 {
-	s := strings.TrimSpace(fmt.Sprint(v))
-	if s == "" {
-		return 0
-	}
-	f, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0
-	}
-	return f
-}
-
 func cellName(colZeroBased int, rowOneBased int) string {
 	return fmt.Sprintf("%s%d", colName(colZeroBased), rowOneBased)
 }
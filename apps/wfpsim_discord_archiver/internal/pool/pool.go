@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Ordered runs do for every item on up to workers goroutines and calls emit with each result
+// in input order, on the caller's goroutine. It returns the first emit error (stopping
+// outstanding work) or ctx's error.
+func Ordered[T, R any](ctx context.Context, items []T, workers int, do func(ctx context.Context, item T) (R, error), emit func(item T, res R, err error) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	type result struct {
+		res R
+		err error
+	}
+	results := make([]chan result, len(items))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	var wg sync.WaitGroup
+	// stop feeding and wait for in-flight work before returning
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	next := make(chan int)
+	for w := 0; w < workers && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				res, err := do(ctx, items[i])
+				results[i] <- result{res: res, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(next)
+		for i := range items {
+			select {
+			case next <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := range items {
+		var r result
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := emit(items[i], r.res, r.err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/localxlsx"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+var columns = []string{"Rank", "Team", "Weapons", "TeamDpsMean", "ResimDpsMean", "Author", "Date", "ShareURL", "Key"}
+
+func cells(rank int, r store.Record) []string {
+	resim := ""
+	if r.ResimStatus == store.ResimOK {
+		resim = strconv.FormatFloat(r.ResimDpsMean, 'f', 0, 64)
+	}
+	date := strings.TrimSpace(r.MessageCreatedAt)
+	if len(date) >= 10 {
+		date = date[:10]
+	}
+	return []string{
+		strconv.Itoa(rank),
+		localxlsx.TeamCharsUI(r.TeamCharacters, r.TeamConstellations),
+		r.TeamWeapons,
+		strconv.FormatFloat(r.TeamDpsMean, 'f', 0, 64),
+		resim,
+		r.Author,
+		date,
+		r.ShareURL,
+		r.Key,
+	}
+}
+
+// WriteTable prints recs as an aligned text table.
+func WriteTable(w io.Writer, recs []store.Record) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for i, r := range recs {
+		fmt.Fprintln(tw, strings.Join(cells(i+1, r), "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes recs as CSV with a header row.
+func WriteCSV(w io.Writer, recs []store.Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for i, r := range recs {
+		if err := cw.Write(cells(i+1, r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteXLSX writes recs into sheetName of the workbook at path (replacing that sheet only).
+func WriteXLSX(path string, sheetName string, recs []store.Record) error {
+	rows := make([][]interface{}, 0, len(recs))
+	for i, r := range recs {
+		c := cells(i+1, r)
+		row := make([]interface{}, len(c))
+		for j, v := range c {
+			row[j] = v
+		}
+		// keep numbers numeric in the sheet
+		row[0] = i + 1
+		row[3] = r.TeamDpsMean
+		if r.ResimStatus == store.ResimOK {
+			row[4] = r.ResimDpsMean
+		}
+		rows = append(rows, row)
+	}
+	return localxlsx.WriteSheet(path, sheetName, columns, rows)
+}
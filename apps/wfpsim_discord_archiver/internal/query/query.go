@@ -0,0 +1,229 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// ConsRange limits a character's constellation to [Min, Max].
+type ConsRange struct {
+	Char     string
+	Min, Max int
+}
+
+// Filter selects archived records. Zero values do not filter.
+type Filter struct {
+	// Chars must all be in the team.
+	Chars []string
+	// Cons constrain constellations of team members (the character must be in the team).
+	Cons []ConsRange
+	// Weapons must all be used in the team (refinement is ignored).
+	Weapons []string
+	MinDPS  float64
+	MaxDPS  float64
+	// Since/Until bound the Discord message date (Until is inclusive of its whole day).
+	Since time.Time
+	Until time.Time
+	// Author is a case-insensitive substring of the message author.
+	Author string
+	// NoDuplicates skips records marked as duplicates of another record (DuplicateOf).
+	NoDuplicates bool
+	// Limit caps the number of results (0 = all).
+	Limit int
+	// Resolver, if set, matches characters by canonical name on both sides ("ei" = "raiden").
+	Resolver *charalias.Resolver
+}
+
+// ParseCons parses "furina:0,raiden:2-6" into constellation ranges ("C" prefixes are accepted).
+func ParseCons(s string) ([]ConsRange, error) {
+	var out []ConsRange
+	for _, part := range splitList(s) {
+		char, spec, ok := strings.Cut(part, ":")
+		char = strings.ToLower(strings.TrimSpace(char))
+		if !ok || char == "" {
+			return nil, fmt.Errorf("invalid constellation filter %q (expected char:N or char:N-M)", part)
+		}
+		lo, hi, isRange := strings.Cut(spec, "-")
+		min, err := parseConsLevel(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constellation filter %q: %w", part, err)
+		}
+		max := min
+		if isRange {
+			if max, err = parseConsLevel(hi); err != nil {
+				return nil, fmt.Errorf("invalid constellation filter %q: %w", part, err)
+			}
+		}
+		if min > max {
+			return nil, fmt.Errorf("invalid constellation filter %q: empty range", part)
+		}
+		out = append(out, ConsRange{Char: char, Min: min, Max: max})
+	}
+	return out, nil
+}
+
+func parseConsLevel(s string) (int, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "C")
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 6 {
+		return 0, fmt.Errorf("constellation must be 0..6, got %q", s)
+	}
+	return n, nil
+}
+
+// ParseDate parses a YYYY-MM-DD date ("" is the zero time).
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// SplitList splits a comma-separated list, dropping empty items and lowercasing.
+func SplitList(s string) []string {
+	var out []string
+	for _, p := range splitList(s) {
+		out = append(out, strings.ToLower(p))
+	}
+	return out
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Apply returns the records matching f, best team DPS first.
+func Apply(recs []store.Record, f Filter) []store.Record {
+	out := make([]store.Record, 0, len(recs))
+	for _, r := range recs {
+		if f.match(r) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].TeamDpsMean != out[j].TeamDpsMean {
+			return out[i].TeamDpsMean > out[j].TeamDpsMean
+		}
+		return out[i].Key < out[j].Key
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out
+}
+
+func (f Filter) match(r store.Record) bool {
+	if f.NoDuplicates && r.DuplicateOf != "" {
+		return false
+	}
+	if f.MinDPS > 0 && r.TeamDpsMean < f.MinDPS {
+		return false
+	}
+	if f.MaxDPS > 0 && r.TeamDpsMean > f.MaxDPS {
+		return false
+	}
+	if strings.TrimSpace(f.Author) != "" && !strings.Contains(strings.ToLower(r.Author), strings.ToLower(strings.TrimSpace(f.Author))) {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		created, err := time.Parse(time.RFC3339, strings.TrimSpace(r.MessageCreatedAt))
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && created.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && !created.Before(f.Until.AddDate(0, 0, 1)) {
+			return false
+		}
+	}
+
+	team := teamMembers(r, f.Resolver)
+	for _, c := range f.Chars {
+		if _, ok := team[charKey(c, f.Resolver)]; !ok {
+			return false
+		}
+	}
+	for _, c := range f.Cons {
+		m, ok := team[charKey(c.Char, f.Resolver)]
+		if !ok || m.cons < 0 || m.cons < c.Min || m.cons > c.Max {
+			return false
+		}
+	}
+	for _, w := range f.Weapons {
+		found := false
+		for _, m := range team {
+			if m.weapon == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type member struct {
+	cons   int // -1 if unknown
+	weapon string
+}
+
+// teamMembers maps the record's characters (by charKey) to their constellation and weapon (the
+// three columns are aligned by position).
+func teamMembers(r store.Record, resolver *charalias.Resolver) map[string]member {
+	chars := strings.Split(r.TeamCharacters, ",")
+	cons := strings.Split(r.TeamConstellations, ",")
+	weps := strings.Split(r.TeamWeapons, ",")
+	out := make(map[string]member, len(chars))
+	for i, c := range chars {
+		c = charKey(c, resolver)
+		if c == "" {
+			continue
+		}
+		m := member{cons: -1}
+		if i < len(cons) {
+			if n, err := parseConsLevel(cons[i]); err == nil && strings.TrimSpace(cons[i]) != "" {
+				m.cons = n
+			}
+		}
+		if i < len(weps) {
+			w := strings.ToLower(strings.TrimSpace(weps[i]))
+			// strip the "(rN)" refinement suffix
+			if k := strings.LastIndex(w, "(r"); k > 0 && strings.HasSuffix(w, ")") {
+				w = w[:k]
+			}
+			m.weapon = w
+		}
+		out[c] = m
+	}
+	return out
+}
+
+// charKey returns the lowercase canonical name of a character; without a resolver, or for names
+// it does not know, the name itself.
+func charKey(name string, resolver *charalias.Resolver) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canon, ok := resolver.Canonicalize(name); ok {
+		return canon
+	}
+	return name
+}
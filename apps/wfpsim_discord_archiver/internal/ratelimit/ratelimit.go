@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket shared by concurrent callers: Rate tokens per second refill up to
+// Burst. PauseUntil stops handing out tokens until a point in time (e.g. a Retry-After).
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	paused time.Time
+
+	// Now and Sleep can be replaced in tests.
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New returns a limiter allowing ratePerSec requests per second with bursts of up to burst.
+// A rate <= 0 disables limiting.
+func New(ratePerSec float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: ratePerSec, burst: float64(burst), tokens: float64(burst), Now: time.Now, Sleep: sleepCtx}
+}
+
+// Wait blocks until a token is available (or ctx is done).
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return ctx.Err()
+	}
+	for {
+		d := l.reserve()
+		if d <= 0 {
+			return ctx.Err()
+		}
+		if err := l.Sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token and returns 0, or returns how long to wait before trying again.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.Now()
+	if now.Before(l.paused) {
+		return l.paused.Sub(now)
+	}
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// PauseUntil holds all callers until t; the bucket restarts empty so requests resume at Rate.
+func (l *Limiter) PauseUntil(t time.Time) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.paused) {
+		l.paused = t
+		l.tokens = 0
+		l.last = t
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -1,52 +1,150 @@
 package shareurl
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
-// Share URL format:
+// Share providers recognised in messages.
+const (
+	ProviderWfpsim  = "wfpsim"
+	ProviderGcsim   = "gcsim"
+	ProviderGcsimDB = "gcsim-db"
+)
+
+// Link is a share link found in a message.
+type Link struct {
+	Provider string
+	ID       string
+}
+
+type pattern struct {
+	provider string
+	re       *regexp.Regexp
+	// url formats the canonical link for an id.
+	url string
+	// search is the Discord search query that finds the links.
+	search string
+}
+
+// Share URL formats:
 // https://wfpsim.com/sh/<uuid>
-var shareURLRe = regexp.MustCompile(`https?://wfpsim\.com/sh/(?P<key>[0-9a-fA-F-]{36})`)
+// https://gcsim.app/sh/<uuid>
+// https://gcsim.app/db/<id> (database entry; the id is a uuid or a 24-char hex object id)
+var patterns = []pattern{
+	{ProviderWfpsim, regexp.MustCompile(`https?://(?:www\.)?wfpsim\.com/sh/(?P<key>[0-9a-fA-F-]{36})`), "https://wfpsim.com/sh/%s", "wfpsim.com/sh/"},
+	{ProviderGcsim, regexp.MustCompile(`https?://(?:www\.)?gcsim\.app/sh/(?P<key>[0-9a-fA-F-]{36})`), "https://gcsim.app/sh/%s", "gcsim.app/sh/"},
+	{ProviderGcsimDB, regexp.MustCompile(`https?://(?:www\.)?gcsim\.app/db/(?P<key>[0-9a-fA-F-]{36}|[0-9a-fA-F]{24})\b`), "https://gcsim.app/db/%s", "gcsim.app/db/"},
+}
 
-// ExtractKeyFromURL returns a lowercased UUID key from a wfpsim share URL.
+// Key is the archive key of the link. wfpsim keys are the bare uuid (as before other providers
+// were supported); other providers are prefixed with "<provider>:".
+func (l Link) Key() string {
+	if l.Provider == ProviderWfpsim {
+		return l.ID
+	}
+	return l.Provider + ":" + l.ID
+}
+
+// URL returns the canonical share URL.
+func (l Link) URL() string {
+	for _, p := range patterns {
+		if p.provider == l.Provider {
+			return fmt.Sprintf(p.url, l.ID)
+		}
+	}
+	return ""
+}
+
+// SearchQueries returns one Discord search query per share link format.
+func SearchQueries() []string {
+	out := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		out = append(out, p.search)
+	}
+	return out
+}
+
+// ParseKey splits an archive key into provider and id.
+func ParseKey(key string) (Link, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return Link{}, false
+	}
+	provider, id, ok := strings.Cut(key, ":")
+	if !ok {
+		return Link{Provider: ProviderWfpsim, ID: key}, true
+	}
+	for _, p := range patterns {
+		if p.provider == provider && id != "" {
+			return Link{Provider: provider, ID: id}, true
+		}
+	}
+	return Link{}, false
+}
+
+// ProviderOf returns the provider of an archive key ("" if the key is not valid).
+func ProviderOf(key string) string {
+	l, _ := ParseKey(key)
+	return l.Provider
+}
+
+// ExtractKeyFromURL returns the archive key (lowercased) of a share URL.
 func ExtractKeyFromURL(url string) (string, bool) {
-	m := shareURLRe.FindStringSubmatch(url)
-	if len(m) == 0 {
+	links := ExtractLinks(url)
+	if len(links) == 0 {
 		return "", false
 	}
-	idx := shareURLRe.SubexpIndex("key")
-	if idx <= 0 || idx >= len(m) {
-		return "", false
+	return links[0].Key(), true
+}
+
+// ExtractLinks finds share links of all providers in text and returns a deduplicated list
+// (ids lowercased), in encounter order.
+func ExtractLinks(content string) []Link {
+	type found struct {
+		pos  int
+		link Link
 	}
-	k := strings.ToLower(m[idx])
-	if strings.TrimSpace(k) == "" {
-		return "", false
+	var all []found
+	for _, p := range patterns {
+		idx := p.re.SubexpIndex("key")
+		for _, m := range p.re.FindAllStringSubmatchIndex(content, -1) {
+			if idx <= 0 || 2*idx+1 >= len(m) || m[2*idx] < 0 {
+				continue
+			}
+			id := strings.ToLower(content[m[2*idx]:m[2*idx+1]])
+			if strings.TrimSpace(id) == "" {
+				continue
+			}
+			all = append(all, found{pos: m[0], link: Link{Provider: p.provider, ID: id}})
+		}
 	}
-	return k, true
+	sort.SliceStable(all, func(i, j int) bool { return all[i].pos < all[j].pos })
+
+	seen := map[Link]struct{}{}
+	out := make([]Link, 0, len(all))
+	for _, f := range all {
+		if _, ok := seen[f.link]; ok {
+			continue
+		}
+		seen[f.link] = struct{}{}
+		out = append(out, f.link)
+	}
+	return out
 }
 
-// ExtractKeysFromText finds all wfpsim share keys in the given text and returns
+// ExtractKeysFromText finds all share keys in the given text and returns
 // a deduplicated list (lowercased), in encounter order.
 func ExtractKeysFromText(content string) []string {
-	matches := shareURLRe.FindAllStringSubmatch(content, -1)
-	if len(matches) == 0 {
+	links := ExtractLinks(content)
+	if len(links) == 0 {
 		return nil
 	}
-
-	idx := shareURLRe.SubexpIndex("key")
-	seen := map[string]struct{}{}
-	out := make([]string, 0, len(matches))
-	for _, m := range matches {
-		if idx <= 0 || idx >= len(m) {
-			continue
-		}
-		k := strings.ToLower(m[idx])
-		if _, ok := seen[k]; ok {
-			continue
-		}
-		seen[k] = struct{}{}
-		out = append(out, k)
+	out := make([]string, 0, len(links))
+	for _, l := range links {
+		out = append(out, l.Key())
 	}
 	return out
 }
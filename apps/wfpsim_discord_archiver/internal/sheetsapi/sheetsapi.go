@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
 )
 
 type Client struct {
@@ -32,6 +34,11 @@ type postRequest struct {
 	SheetID   string     `json:"sheetId"`
 	SheetName string     `json:"sheetName"`
 	Record    postRecord `json:"record"`
+	// RowVersion is the layout of Record.Row (store.RowVersion); missing means version 1.
+	RowVersion int `json:"rowVersion"`
+	// Update rewrites the updatable columns (re-simulation, deduplication) of an existing row
+	// with the same key.
+	Update bool `json:"update,omitempty"`
 }
 
 type postRecord struct {
@@ -42,17 +49,29 @@ type postResponse struct {
 	OK       bool   `json:"ok"`
 	Error    string `json:"error"`
 	Appended int    `json:"appended"`
+	Updated  int    `json:"updated"`
 }
 
 func (c *Client) AppendRow(ctx context.Context, row []interface{}, key string, messageID string) error {
 	_ = key
 	_ = messageID
+	return c.post(ctx, row, false)
+}
+
+// UpdateRow writes the re-simulation and deduplication columns of row into the existing row
+// with the same key (the row is appended if the key is missing).
+func (c *Client) UpdateRow(ctx context.Context, row []interface{}) error {
+	return c.post(ctx, row, true)
+}
 
+func (c *Client) post(ctx context.Context, row []interface{}, update bool) error {
 	reqBody := postRequest{
-		APIKey:    c.apiKey,
-		SheetID:   c.sheetID,
-		SheetName: c.sheetTab,
-		Record:    postRecord{Row: row},
+		APIKey:     c.apiKey,
+		SheetID:    c.sheetID,
+		SheetName:  c.sheetTab,
+		Record:     postRecord{Row: row},
+		RowVersion: store.RowVersion,
+		Update:     update,
 	}
 
 	b, err := json.Marshal(reqBody)
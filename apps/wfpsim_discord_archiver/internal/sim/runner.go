@@ -0,0 +1,166 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type SimulationResult struct {
+	SimVersion string `json:"sim_version"`
+
+	Statistics struct {
+		DPS struct {
+			Mean *float64 `json:"mean"`
+		} `json:"dps"`
+	} `json:"statistics"`
+}
+
+// ConfigError is returned when the engine rejects a config (parse or run error), as opposed to
+// the engine itself being unavailable.
+type ConfigError struct {
+	Output string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	msg := fmt.Sprintf("engine rejected config: %v", e.Err)
+	if e.Output != "" {
+		msg += "\n" + e.Output
+	}
+	return msg
+}
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// Summary is the first non-empty line of the engine output (or the exit error).
+func (e *ConfigError) Summary() string {
+	for _, line := range strings.Split(e.Output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return e.Err.Error()
+}
+
+// Runner simulates a config in workDir; a config the engine cannot run yields a *ConfigError.
+type Runner interface {
+	Run(ctx context.Context, workDir string, configStr string) (*SimulationResult, error)
+}
+
+// CLIRunner runs an engine via its gcsim.exe CLI.
+// Expected layout (as produced by scripts/engines/bootstrap.ps1): <repoRoot>/engines/bins/<engine>/gcsim.exe.
+type CLIRunner struct {
+	EngineRoot string
+	// Iterations overrides the config's iteration count when > 0.
+	Iterations int
+}
+
+// Check verifies that the engine CLI exists.
+func (r CLIRunner) Check() error {
+	_, err := resolveEngineCLI(r.EngineRoot)
+	return err
+}
+
+var (
+	// reIteration matches the iteration count of the options statement (up to its ';').
+	reIteration = regexp.MustCompile(`(?m)^(\s*options\b[^;]*?\biteration\s*=\s*)\d+`)
+	reOptions   = regexp.MustCompile(`(?m)^(\s*options\b)`)
+)
+
+// PrepareConfig applies the runner's iteration override to the options statement of a config;
+// iteration elsewhere (e.g. a script variable) is left alone.
+func (r CLIRunner) PrepareConfig(configStr string) string {
+	if r.Iterations <= 0 {
+		return configStr
+	}
+	if reIteration.MatchString(configStr) {
+		return reIteration.ReplaceAllString(configStr, fmt.Sprintf("${1}%d", r.Iterations))
+	}
+	it := fmt.Sprintf("iteration=%d", r.Iterations)
+	if loc := reOptions.FindStringSubmatchIndex(configStr); loc != nil {
+		return configStr[:loc[3]] + " " + it + configStr[loc[3]:]
+	}
+	return "options " + it + ";\n" + configStr
+}
+
+// Run writes configStr to workDir and simulates it. A config the engine cannot run yields a
+// *ConfigError.
+func (r CLIRunner) Run(ctx context.Context, workDir string, configStr string) (*SimulationResult, error) {
+	engineExe, err := resolveEngineCLI(r.EngineRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return nil, err
+	}
+	workDir, err = filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	configPath := filepath.Join(workDir, "config.txt")
+	if err := os.WriteFile(configPath, []byte(r.PrepareConfig(configStr)), 0o644); err != nil {
+		return nil, err
+	}
+	outPath := filepath.Join(workDir, "last_result.json")
+	_ = os.Remove(outPath)
+
+	cmd := exec.CommandContext(ctx, engineExe, "-c", configPath, "-out", outPath)
+	cmd.Dir = r.EngineRoot
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	elapsed := time.Since(start)
+	if err != nil {
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		out := bytes.TrimSpace(append(stdout.Bytes(), stderr.Bytes()...))
+		const max = 16 * 1024
+		if len(out) > max {
+			out = append(out[:max], []byte("\n...<truncated>\n")...)
+		}
+		return nil, &ConfigError{Output: string(out), Err: fmt.Errorf("engine CLI failed after %s: %w", elapsed.Round(time.Millisecond), err)}
+	}
+
+	b, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("read engine result %q: %w", outPath, err)
+	}
+
+	var res SimulationResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("parse engine result %q: %w", outPath, err)
+	}
+	if res.Statistics.DPS.Mean == nil {
+		return nil, fmt.Errorf("engine result missing statistics.dps.mean (%q)", outPath)
+	}
+	return &res, nil
+}
+
+func resolveEngineCLI(engineRoot string) (string, error) {
+	if engineRoot == "" {
+		return "", fmt.Errorf("engine root is empty")
+	}
+
+	parent := filepath.Dir(engineRoot)
+	if filepath.Base(parent) == "engines" {
+		bins := filepath.Join(parent, "bins", filepath.Base(engineRoot), "gcsim.exe")
+		if _, err := os.Stat(bins); err == nil {
+			return bins, nil
+		}
+		return "", fmt.Errorf("cannot find engine CLI at %q (run scripts/engines/bootstrap.ps1)", bins)
+	}
+	return "", fmt.Errorf("engine root %q is not under an 'engines' directory; cannot derive engines/bins path", engineRoot)
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// FailedKey is a share key whose fetch failed. It is retried on later runs (NextRetry) until it
+// succeeds or is given up (Permanent), independently of the channel/search/export checkpoints.
+type FailedKey struct {
+	// Message the key was found in; needed to build the row when a retry succeeds.
+	GuildID   string    `json:"guildId,omitempty"`
+	ChannelID string    `json:"channelId,omitempty"`
+	MessageID string    `json:"messageId"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// ErrorClass is a wfpsim.Fail* class; Error is the last error message.
+	ErrorClass  string    `json:"errorClass"`
+	Error       string    `json:"error"`
+	Attempts    int       `json:"attempts"`
+	FirstFailed time.Time `json:"firstFailed"`
+	LastFailed  time.Time `json:"lastFailed"`
+	NextRetry   time.Time `json:"nextRetry,omitempty"`
+	Permanent   bool      `json:"permanent,omitempty"`
+}
+
+// RetryPolicy schedules retries of failed keys: the n-th failure waits Backoff*2^(n-1), at most
+// MaxBackoff, and a key is given up after MaxAttempts failures.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// RecordFailure records a failed fetch of key. f carries the message reference and error; the
+// attempt count and schedule are derived from the previous failure of key, if any. final marks
+// errors that will not go away (e.g. 404), which are given up immediately.
+func (st *State) RecordFailure(key string, f FailedKey, final bool, p RetryPolicy, now time.Time) FailedKey {
+	if st.FailedKeys == nil {
+		st.FailedKeys = map[string]FailedKey{}
+	}
+	prev, ok := st.FailedKeys[key]
+	f.Attempts = 1
+	f.FirstFailed = now
+	if ok {
+		f.Attempts = prev.Attempts + 1
+		f.FirstFailed = prev.FirstFailed
+	}
+	f.LastFailed = now
+	f.NextRetry = time.Time{}
+	f.Permanent = final || (p.MaxAttempts > 0 && f.Attempts >= p.MaxAttempts)
+	if !f.Permanent {
+		wait := p.Backoff
+		for i := 1; i < f.Attempts && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+			wait *= 2
+		}
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+		f.NextRetry = now.Add(wait)
+	}
+	st.FailedKeys[key] = f
+	return f
+}
+
+// DueFailedKeys returns the keys that are not given up and due for a retry at now, sorted.
+func (st *State) DueFailedKeys(now time.Time) []string {
+	var out []string
+	for k, f := range st.FailedKeys {
+		if !f.Permanent && !f.NextRetry.After(now) {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
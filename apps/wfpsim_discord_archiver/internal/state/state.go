@@ -9,11 +9,15 @@ import (
 )
 
 type State struct {
-	Channels       map[string]ChannelState `json:"channels"`
-	ProcessedKeys  map[string]time.Time    `json:"processedKeys"`
-	LastSearchIDs  map[string]string       `json:"lastSearchMessageIds,omitempty"`
-	LastRunStarted time.Time               `json:"lastRunStarted"`
-	LastRunEnded   time.Time               `json:"lastRunEnded"`
+	Channels      map[string]ChannelState `json:"channels"`
+	ProcessedKeys map[string]time.Time    `json:"processedKeys"`
+	LastSearchIDs map[string]string       `json:"lastSearchMessageIds,omitempty"`
+	// Messages from Discord exports (run.mode=export) that were already processed.
+	ProcessedMessageIDs map[string]time.Time `json:"processedMessageIds,omitempty"`
+	// Share keys whose fetch failed, with their retry schedule (see FailedKey).
+	FailedKeys     map[string]FailedKey `json:"failedKeys,omitempty"`
+	LastRunStarted time.Time            `json:"lastRunStarted"`
+	LastRunEnded   time.Time            `json:"lastRunEnded"`
 }
 
 type ChannelState struct {
@@ -25,7 +29,7 @@ func Load(path string) (State, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return State{Channels: map[string]ChannelState{}, ProcessedKeys: map[string]time.Time{}}, nil
+			return State{Channels: map[string]ChannelState{}, ProcessedKeys: map[string]time.Time{}, ProcessedMessageIDs: map[string]time.Time{}, FailedKeys: map[string]FailedKey{}}, nil
 		}
 		return State{}, err
 	}
@@ -43,6 +47,12 @@ func Load(path string) (State, error) {
 	if st.LastSearchIDs == nil {
 		st.LastSearchIDs = map[string]string{}
 	}
+	if st.ProcessedMessageIDs == nil {
+		st.ProcessedMessageIDs = map[string]time.Time{}
+	}
+	if st.FailedKeys == nil {
+		st.FailedKeys = map[string]FailedKey{}
+	}
 	return st, nil
 }
 
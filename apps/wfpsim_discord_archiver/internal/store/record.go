@@ -0,0 +1,227 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Record is one archived share.
+type Record struct {
+	FetchedAt          string  `json:"fetchedAt"`
+	GuildID            string  `json:"guildId"`
+	ChannelID          string  `json:"channelId"`
+	MessageID          string  `json:"messageId"`
+	MessageURL         string  `json:"messageUrl"`
+	Author             string  `json:"author"`
+	MessageCreatedAt   string  `json:"messageCreatedAt"`
+	Key                string  `json:"key"`
+	ShareURL           string  `json:"shareUrl"`
+	TeamCharacters     string  `json:"teamCharacters"`
+	TeamWeapons        string  `json:"teamWeapons"`
+	TeamDpsMean        float64 `json:"teamDpsMean"`
+	TeamDpsQ2          float64 `json:"teamDpsQ2"`
+	ConfigFile         string  `json:"configFile"`
+	SimVersion         string  `json:"simVersion"`
+	SchemaMajor        int     `json:"schemaMajor"`
+	SchemaMinor        int     `json:"schemaMinor"`
+	TeamConstellations string  `json:"teamConstellations"`
+	// Provider is the share host (shareurl.ProviderWfpsim, ...); empty in records archived before
+	// other providers were supported, which are all wfpsim.
+	Provider string `json:"provider,omitempty"`
+
+	// Per-character details, comma-separated in TeamCharacters order (empty in records archived
+	// before row version 2): share of team DPS in percent, artifact sets ("set(4)" or
+	// "set(2)+set(2)") and talent levels ("attack/skill/burst").
+	CharDpsShare string `json:"charDpsShare,omitempty"`
+	ArtifactSets string `json:"artifactSets,omitempty"`
+	TalentLevels string `json:"talentLevels,omitempty"`
+
+	// ConfigFingerprint identifies the normalised config (see dedup.Fingerprint); records with
+	// the same fingerprint are duplicates, and DuplicateOf is the key of the group's canonical
+	// record (empty for the canonical record itself and for records without duplicates).
+	ConfigFingerprint string `json:"configFingerprint,omitempty"`
+	DuplicateOf       string `json:"duplicateOf,omitempty"`
+
+	// Re-simulation of ConfigFile against the local engine (-resim); empty until then.
+	ResimStatus     string  `json:"resimStatus,omitempty"`
+	ResimDpsMean    float64 `json:"resimDpsMean,omitempty"`
+	ResimDeltaPct   float64 `json:"resimDeltaPct,omitempty"`
+	ResimSimVersion string  `json:"resimSimVersion,omitempty"`
+	ResimAt         string  `json:"resimAt,omitempty"`
+	ResimError      string  `json:"resimError,omitempty"`
+}
+
+// Re-simulation statuses.
+const (
+	ResimOK = "ok"
+	// ResimInvalid: the current engine no longer parses or runs the archived config.
+	ResimInvalid = "invalid"
+	// ResimNoConfig: the share has no config to re-simulate.
+	ResimNoConfig = "no_config"
+)
+
+// RowVersion is the version of the row layout below; it is sent to Apps Script with every row.
+// Columns are only ever appended, so a row of an older version is a prefix of the current one:
+//
+//	1: idxFetchedAt .. idxResimAt (rowLenV1 columns)
+//	2: + idxCharDpsShare, idxArtifactSets, idxTalentLevels
+//	3: + idxConfigFingerprint, idxDuplicateOf
+const RowVersion = 3
+
+// Indexes in the row produced by BuildRow (kept stable for Apps Script).
+const (
+	idxFetchedAt               = 0
+	idxDiscordGuildID          = 1
+	idxDiscordChannelID        = 2
+	idxDiscordMessageID        = 3
+	idxDiscordMessageURL       = 4
+	idxDiscordAuthor           = 5
+	idxDiscordMessageCreatedAt = 6
+	idxKey                     = 7
+	idxShareURL                = 8
+	idxTeamCharacters          = 9
+	idxTeamWeapons             = 10
+	idxTeamDpsMean             = 11
+	idxTeamDpsQ2               = 12
+	idxConfigFile              = 13
+	idxSimVersion              = 14
+	idxSchemaMajor             = 15
+	idxSchemaMinor             = 16
+	idxTeamConstellations      = 17
+	idxProvider                = 18
+	idxResimDpsMean            = 19
+	idxResimDeltaPct           = 20
+	idxResimStatus             = 21
+	idxResimSimVersion         = 22
+	idxResimAt                 = 23
+	rowLenV1                   = 24
+	idxCharDpsShare            = 24
+	idxArtifactSets            = 25
+	idxTalentLevels            = 26
+	idxConfigFingerprint       = 27
+	idxDuplicateOf             = 28
+	rowLen                     = 29
+)
+
+// RecordFromRow converts a row in the Apps Script layout into a Record.
+func RecordFromRow(row []interface{}) Record {
+	get := func(idx int) string {
+		if idx >= 0 && idx < len(row) && row[idx] != nil {
+			return strings.TrimSpace(fmt.Sprint(row[idx]))
+		}
+		return ""
+	}
+	return Record{
+		FetchedAt:          get(idxFetchedAt),
+		GuildID:            get(idxDiscordGuildID),
+		ChannelID:          get(idxDiscordChannelID),
+		MessageID:          get(idxDiscordMessageID),
+		MessageURL:         get(idxDiscordMessageURL),
+		Author:             get(idxDiscordAuthor),
+		MessageCreatedAt:   get(idxDiscordMessageCreatedAt),
+		Key:                NormalizeKey(get(idxKey)),
+		ShareURL:           get(idxShareURL),
+		TeamCharacters:     get(idxTeamCharacters),
+		TeamWeapons:        get(idxTeamWeapons),
+		TeamDpsMean:        ParseFloat(get(idxTeamDpsMean)),
+		TeamDpsQ2:          ParseFloat(get(idxTeamDpsQ2)),
+		ConfigFile:         get(idxConfigFile),
+		SimVersion:         get(idxSimVersion),
+		SchemaMajor:        ParseInt(get(idxSchemaMajor)),
+		SchemaMinor:        ParseInt(get(idxSchemaMinor)),
+		TeamConstellations: get(idxTeamConstellations),
+		Provider:           get(idxProvider),
+		ResimDpsMean:       ParseFloat(get(idxResimDpsMean)),
+		ResimDeltaPct:      ParseFloat(get(idxResimDeltaPct)),
+		ResimStatus:        get(idxResimStatus),
+		ResimSimVersion:    get(idxResimSimVersion),
+		ResimAt:            get(idxResimAt),
+		CharDpsShare:       get(idxCharDpsShare),
+		ArtifactSets:       get(idxArtifactSets),
+		TalentLevels:       get(idxTalentLevels),
+		ConfigFingerprint:  get(idxConfigFingerprint),
+		DuplicateOf:        NormalizeKey(get(idxDuplicateOf)),
+	}
+}
+
+// RowFromRecord lays rec out in the Apps Script row layout (the inverse of RecordFromRow).
+func RowFromRecord(rec Record) []interface{} {
+	row := make([]interface{}, rowLen)
+	row[idxFetchedAt] = rec.FetchedAt
+	row[idxDiscordGuildID] = rec.GuildID
+	row[idxDiscordChannelID] = rec.ChannelID
+	row[idxDiscordMessageID] = rec.MessageID
+	row[idxDiscordMessageURL] = rec.MessageURL
+	row[idxDiscordAuthor] = rec.Author
+	row[idxDiscordMessageCreatedAt] = rec.MessageCreatedAt
+	row[idxKey] = rec.Key
+	row[idxShareURL] = rec.ShareURL
+	row[idxTeamCharacters] = rec.TeamCharacters
+	row[idxTeamWeapons] = rec.TeamWeapons
+	row[idxTeamDpsMean] = rec.TeamDpsMean
+	row[idxTeamDpsQ2] = rec.TeamDpsQ2
+	row[idxConfigFile] = rec.ConfigFile
+	row[idxSimVersion] = rec.SimVersion
+	row[idxSchemaMajor] = rec.SchemaMajor
+	row[idxSchemaMinor] = rec.SchemaMinor
+	row[idxTeamConstellations] = rec.TeamConstellations
+	row[idxProvider] = rec.Provider
+	row[idxResimDpsMean] = resimNumber(rec, rec.ResimDpsMean)
+	row[idxResimDeltaPct] = resimNumber(rec, rec.ResimDeltaPct)
+	row[idxResimStatus] = rec.ResimStatus
+	row[idxResimSimVersion] = rec.ResimSimVersion
+	row[idxResimAt] = rec.ResimAt
+	row[idxCharDpsShare] = rec.CharDpsShare
+	row[idxArtifactSets] = rec.ArtifactSets
+	row[idxTalentLevels] = rec.TalentLevels
+	row[idxConfigFingerprint] = rec.ConfigFingerprint
+	row[idxDuplicateOf] = rec.DuplicateOf
+	return row
+}
+
+// ResimCells returns the re-simulation cells of rec in column order (ResimDpsMean .. ResimAt).
+func ResimCells(rec Record) []interface{} {
+	return RowFromRecord(rec)[idxResimDpsMean:rowLenV1]
+}
+
+// CharacterCells returns the per-character cells of rec in column order (CharDpsShare .. TalentLevels).
+func CharacterCells(rec Record) []interface{} {
+	return RowFromRecord(rec)[idxCharDpsShare : idxTalentLevels+1]
+}
+
+// DedupCells returns the deduplication cells of rec in column order (ConfigFingerprint, DuplicateOf).
+func DedupCells(rec Record) []interface{} {
+	return RowFromRecord(rec)[idxConfigFingerprint:rowLen]
+}
+
+// resimNumber leaves resim numbers blank unless the re-simulation succeeded.
+func resimNumber(rec Record, v float64) interface{} {
+	if rec.ResimStatus != ResimOK {
+		return ""
+	}
+	return v
+}
+
+// NormalizeKey is the canonical form of a share key (keys are unique case-insensitively).
+func NormalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// ParseFloat parses a number cell; empty or invalid values are 0.
+func ParseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// ParseInt parses an integer cell; empty or invalid values are 0.
+func ParseInt(s string) int {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return int(ParseFloat(s))
+}
@@ -0,0 +1,351 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Store is the archive's source of truth: an append-only JSONL file of records (one per line)
+// with an index file "<path>.idx" of "<key> <offset> <length>" lines. The index is rebuilt
+// from the JSONL file when it is missing or does not cover the whole file (e.g. after a crash
+// between the two appends). A later line for the same key supersedes the earlier one (Put);
+// Compact drops superseded lines.
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	data  *os.File
+	idx   *os.File
+	size  int64
+	index map[string]span
+	keys  []string // in append order
+	lines int      // including superseded records
+}
+
+type span struct {
+	offset int64
+	length int64
+}
+
+// Open opens (or creates) the store at path.
+func Open(path string) (*Store, error) {
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens the data and index files and loads (or rebuilds) the index.
+func (s *Store) open() error {
+	data, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("store open %s: %w", s.path, err)
+	}
+	st, err := data.Stat()
+	if err != nil {
+		_ = data.Close()
+		return err
+	}
+	s.data, s.size = data, st.Size()
+	s.resetIndex()
+
+	if ok := s.loadIndex(); !ok {
+		if err := s.rebuildIndex(); err != nil {
+			_ = data.Close()
+			return err
+		}
+	}
+	s.idx, err = os.OpenFile(s.indexPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		_ = data.Close()
+		return fmt.Errorf("store open index: %w", err)
+	}
+	return nil
+}
+
+// reopen closes the current handles and opens the files on disk again.
+func (s *Store) reopen() error {
+	_ = s.data.Close()
+	_ = s.idx.Close()
+	if err := s.open(); err != nil {
+		return fmt.Errorf("store reopen: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) indexPath() string { return s.path + ".idx" }
+
+// Path returns the JSONL file path.
+func (s *Store) Path() string { return s.path }
+
+// loadIndex reads the index file; it reports false when the index does not match the data file.
+func (s *Store) loadIndex() bool {
+	b, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return s.size == 0 && errors.Is(err, os.ErrNotExist)
+	}
+	var end int64
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return s.resetIndex()
+		}
+		off, err1 := strconv.ParseInt(fields[1], 10, 64)
+		n, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || off != end {
+			return s.resetIndex()
+		}
+		s.add(fields[0], span{offset: off, length: n})
+		end = off + n
+	}
+	if end != s.size {
+		return s.resetIndex()
+	}
+	return true
+}
+
+func (s *Store) resetIndex() bool {
+	s.index = map[string]span{}
+	s.keys = nil
+	s.lines = 0
+	return false
+}
+
+// rebuildIndex scans the data file, drops a torn last line and rewrites the index file.
+func (s *Store) rebuildIndex() error {
+	s.resetIndex()
+	if _, err := s.data.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(s.data)
+	var offset int64
+	var idx bytes.Buffer
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var rec Record
+			if jerr := json.Unmarshal(line, &rec); jerr != nil {
+				return fmt.Errorf("store %s: corrupt record at offset %d: %w", s.path, offset, jerr)
+			}
+			sp := span{offset: offset, length: int64(len(line))}
+			s.add(rec.Key, sp)
+			fmt.Fprintf(&idx, "%s %d %d\n", rec.Key, sp.offset, sp.length)
+			offset += sp.length
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if offset != s.size {
+		// Incomplete last line: the append did not finish.
+		if err := s.data.Truncate(offset); err != nil {
+			return err
+		}
+		s.size = offset
+	}
+	return writeFileAtomic(s.indexPath(), idx.Bytes())
+}
+
+func (s *Store) add(key string, sp span) {
+	if _, ok := s.index[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.index[key] = sp
+	s.lines++
+}
+
+// Len returns the number of records.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+// Has reports whether a record with key exists.
+func (s *Store) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.index[NormalizeKey(key)]
+	return ok
+}
+
+// Append adds rec unless a record with the same key exists; it reports whether rec was added.
+func (s *Store) Append(rec Record) (bool, error) {
+	rec.Key = NormalizeKey(rec.Key)
+	if rec.Key == "" {
+		return false, errors.New("store: empty key")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.index[rec.Key]; ok {
+		return false, nil
+	}
+	return true, s.write(rec)
+}
+
+// Put adds rec or replaces the record with the same key (keeping its position in All).
+func (s *Store) Put(rec Record) error {
+	rec.Key = NormalizeKey(rec.Key)
+	if rec.Key == "" {
+		return errors.New("store: empty key")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(rec)
+}
+
+func (s *Store) write(rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := s.data.WriteAt(line, s.size); err != nil {
+		return fmt.Errorf("store append: %w", err)
+	}
+	if err := s.data.Sync(); err != nil {
+		return fmt.Errorf("store sync: %w", err)
+	}
+	sp := span{offset: s.size, length: int64(len(line))}
+	if _, err := fmt.Fprintf(s.idx, "%s %d %d\n", rec.Key, sp.offset, sp.length); err != nil {
+		return fmt.Errorf("store index append: %w", err)
+	}
+	s.size += sp.length
+	s.add(rec.Key, sp)
+	return nil
+}
+
+// Compact rewrites the store without superseded records (no-op if there are none).
+func (s *Store) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lines == len(s.keys) {
+		return nil
+	}
+
+	var data, idx bytes.Buffer
+	for _, k := range s.keys {
+		sp := s.index[k]
+		buf := make([]byte, sp.length)
+		if _, err := s.data.ReadAt(buf, sp.offset); err != nil {
+			return fmt.Errorf("store read at %d: %w", sp.offset, err)
+		}
+		nsp := span{offset: int64(data.Len()), length: sp.length}
+		data.Write(buf)
+		fmt.Fprintf(&idx, "%s %d %d\n", k, nsp.offset, nsp.length)
+	}
+
+	// Replace the data file first: until the rename succeeds the old file and our handles are
+	// untouched. Afterwards the handles point at the replaced file, so reopen whatever happens
+	// to the index (a stale index is rebuilt on open).
+	if err := writeFileAtomic(s.path, data.Bytes()); err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+	ierr := writeFileAtomic(s.indexPath(), idx.Bytes())
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	if ierr != nil {
+		return fmt.Errorf("store compact index: %w", ierr)
+	}
+	return nil
+}
+
+// Get returns the record with key.
+func (s *Store) Get(key string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp, ok := s.index[NormalizeKey(key)]
+	if !ok {
+		return Record{}, false, nil
+	}
+	rec, err := s.readAt(sp)
+	return rec, err == nil, err
+}
+
+// All returns every record in append order.
+func (s *Store) All() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Record, 0, len(s.keys))
+	for _, k := range s.keys {
+		rec, err := s.readAt(s.index[k])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (s *Store) readAt(sp span) (Record, error) {
+	buf := make([]byte, sp.length)
+	if _, err := s.data.ReadAt(buf, sp.offset); err != nil {
+		return Record{}, fmt.Errorf("store read at %d: %w", sp.offset, err)
+	}
+	var rec Record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		return Record{}, fmt.Errorf("store decode at %d: %w", sp.offset, err)
+	}
+	return rec, nil
+}
+
+// Close closes the store files.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.data.Close()
+	if ierr := s.idx.Close(); err == nil {
+		err = ierr
+	}
+	return err
+}
+
+// writeFileAtomic writes b to a temporary file next to path, syncs it and renames it over path,
+// so readers (and a crash) see either the old or the new content.
+func writeFileAtomic(path string, b []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
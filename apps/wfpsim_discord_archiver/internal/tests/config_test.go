@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+)
+
+func TestLoadFetchRate(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		yaml string
+		want float64
+	}{
+		{"", 2},
+		{"  fetchRate: 0\n", 0}, // unlimited
+		{"  fetchRate: 0.5\n", 0.5},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, "config.yaml")
+		text := "run:\n  mode: export\n  dryRun: true\n  exportFiles: [x.json]\n" + c.yaml
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("%q: %v", c.yaml, err)
+		}
+		if *cfg.Run.FetchRate != c.want {
+			t.Errorf("%q: fetchRate = %v, want %v", c.yaml, *cfg.Run.FetchRate, c.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/daemon"
+)
+
+func TestDaemonLoopAndStatus(t *testing.T) {
+	status := daemon.NewStatus(time.Now())
+	srv := httptest.NewServer(status.Handler())
+	t.Cleanup(srv.Close)
+
+	health := func() int {
+		t.Helper()
+		resp, err := srv.Client().Get(srv.URL + "/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := health(); code != http.StatusOK {
+		t.Errorf("health before any run = %d", code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	var healthDuring []int
+	run := func(ctx context.Context) (int, error) {
+		runs++
+		if runs > 1 {
+			// the previous run's result is visible while this one runs
+			healthDuring = append(healthDuring, health())
+		}
+		switch runs {
+		case 1:
+			return 2, errors.New("discord unavailable")
+		case 2:
+			return 3, nil
+		default:
+			cancel()
+			return 1, ctx.Err()
+		}
+	}
+	if err := daemon.Loop(ctx, time.Millisecond, status, run); err != nil {
+		t.Fatalf("Loop = %v, want nil on cancellation", err)
+	}
+	if runs != 3 {
+		t.Fatalf("runs = %d", runs)
+	}
+	if len(healthDuring) != 2 || healthDuring[0] != http.StatusServiceUnavailable || healthDuring[1] != http.StatusOK {
+		t.Errorf("health during runs = %v", healthDuring)
+	}
+
+	resp, err := srv.Client().Get(srv.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var s daemon.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	// the interrupted run is not a failure
+	if s.Runs != 3 || s.FailedRuns != 1 || s.TotalNewKeys != 6 || s.LastNewKeys != 1 || s.LastError != "" || s.Running {
+		t.Errorf("status = %+v", s)
+	}
+	if s.LastErrorAt.IsZero() || s.LastRunEnded.IsZero() || !s.NextRun.IsZero() {
+		t.Errorf("status times = %+v", s)
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/charalias"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/dedup"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/query"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+const dedupConfig = `raiden char lvl=90/90 cons=2 talent=9,9,9;
+raiden add weapon="engulfinglightning" refine=1;
+xingqiu char lvl=90/90 cons=6 talent=9,9,9;
+options iteration=1000 duration=90 swap_delay=12;
+active raiden;
+raiden skill;
+xingqiu burst, skill;`
+
+// testResolver loads a resolver from a minimal engine shortcut file.
+func testResolver(t *testing.T) *charalias.Resolver {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "pkg", "shortcut")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `package shortcut
+
+var CharNameToKey = map[string]keys.Char{
+	"raiden": keys.Raiden,
+	"ei":     keys.Raiden,
+	"xq":     keys.Xingqiu,
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "characters.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := charalias.LoadFromEngineRoot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFingerprintIgnoresTrivialDifferences(t *testing.T) {
+	r := testResolver(t)
+	want := dedup.Fingerprint(dedupConfig, r)
+	if want == "" {
+		t.Fatal("empty fingerprint")
+	}
+
+	same := []string{
+		// comments, whitespace and case
+		"# posted by someone\n" + dedupConfig + "\n// end\n",
+		"RAIDEN  char lvl = 90/90  cons=2 talent=9,9,9 ;\n\n" + dedupConfig[len("raiden char lvl=90/90 cons=2 talent=9,9,9;"):],
+		// iteration count and options order
+		`raiden char lvl=90/90 cons=2 talent=9,9,9;
+raiden add weapon="engulfinglightning" refine=1;
+xingqiu char lvl=90/90 cons=6 talent=9,9,9;
+options swap_delay=12 duration=90 iteration=100;
+active raiden;
+raiden skill;
+xingqiu burst, skill;`,
+		// aliases
+		`ei char lvl=90/90 cons=2 talent=9,9,9;
+ei add weapon="engulfinglightning" refine=1;
+xq char lvl=90/90 cons=6 talent=9,9,9;
+options iteration=1000 duration=90 swap_delay=12;
+active ei;
+ei skill;
+xq burst,skill;`,
+	}
+	for i, cfg := range same {
+		if got := dedup.Fingerprint(cfg, r); got != want {
+			t.Errorf("variant %d: fingerprint %s, want %s\n%s", i, got, want, dedup.Normalize(cfg, r))
+		}
+	}
+
+	different := []string{
+		dedupConfig[:len(dedupConfig)-len("xingqiu burst, skill;")] + "xingqiu skill, burst;",
+		"raiden char lvl=90/90 cons=3 talent=9,9,9;" + dedupConfig[len("raiden char lvl=90/90 cons=2 talent=9,9,9;"):],
+	}
+	for i, cfg := range different {
+		if got := dedup.Fingerprint(cfg, r); got == want {
+			t.Errorf("different config %d has the same fingerprint", i)
+		}
+	}
+	// only the options iteration count is ignored
+	if dedup.Fingerprint(dedupConfig+"\nlet iteration = 3;", r) == dedup.Fingerprint(dedupConfig+"\nlet iteration = 4;", r) {
+		t.Error("iteration outside options was dropped")
+	}
+	if fp := dedup.Fingerprint(" # only a comment\n", r); fp != "" {
+		t.Errorf("empty config fingerprint = %q", fp)
+	}
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	recs := []store.Record{
+		{Key: "a", ConfigFingerprint: "fp1", TeamDpsMean: 100, MessageCreatedAt: "2025-01-03T00:00:00Z"},
+		{Key: "b", ConfigFingerprint: "fp1", TeamDpsMean: 120, MessageCreatedAt: "2025-01-01T00:00:00Z"},
+		{Key: "c", ConfigFingerprint: "fp1", TeamDpsMean: 90, MessageCreatedAt: "2025-01-02T00:00:00Z", DuplicateOf: "b"},
+		{Key: "d", ConfigFingerprint: "fp2", TeamDpsMean: 50},
+		{Key: "e", TeamDpsMean: 500},
+	}
+
+	changed := dedup.Group(recs, dedup.KeepDPS)
+	wantDup := map[string]string{"a": "b", "b": "", "c": "b", "d": "", "e": ""}
+	for _, r := range recs {
+		if r.DuplicateOf != wantDup[r.Key] {
+			t.Errorf("dps: %s.DuplicateOf = %q, want %q", r.Key, r.DuplicateOf, wantDup[r.Key])
+		}
+	}
+	if len(changed) != 1 || changed[0].Key != "a" {
+		t.Errorf("dps: changed = %+v", changed)
+	}
+	if again := dedup.Group(recs, dedup.KeepDPS); len(again) != 0 {
+		t.Errorf("regrouping changed %d records", len(again))
+	}
+
+	changed = dedup.Group(recs, dedup.KeepNewest)
+	wantDup = map[string]string{"a": "", "b": "a", "c": "a", "d": "", "e": ""}
+	for _, r := range recs {
+		if r.DuplicateOf != wantDup[r.Key] {
+			t.Errorf("newest: %s.DuplicateOf = %q, want %q", r.Key, r.DuplicateOf, wantDup[r.Key])
+		}
+	}
+	if len(changed) != 3 {
+		t.Errorf("newest: changed %d records, want 3", len(changed))
+	}
+
+	res := query.Apply(recs, query.Filter{NoDuplicates: true})
+	if len(res) != 3 || res[0].Key != "e" || res[1].Key != "a" || res[2].Key != "d" {
+		t.Errorf("query without duplicates = %+v", res)
+	}
+}
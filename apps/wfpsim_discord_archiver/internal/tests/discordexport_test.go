@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discordexport"
+)
+
+func loadExport(t *testing.T, name string) discordexport.Export {
+	t.Helper()
+	ex, err := discordexport.Load(filepath.Join("testdata", "discordexport", name))
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return ex
+}
+
+func messageIDs(ex discordexport.Export) []string {
+	var ids []string
+	for _, m := range ex.Messages {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestLoadJSONExport(t *testing.T) {
+	ex := loadExport(t, "channel.json")
+	if ex.GuildID != "111111111111111111" || ex.ChannelID != "222222222222222222" {
+		t.Fatalf("guild/channel = %s/%s", ex.GuildID, ex.ChannelID)
+	}
+	if got := messageIDs(ex); !reflect.DeepEqual(got, []string{"1300000000000000001", "1300000000000000002"}) {
+		t.Fatalf("messages not in ID order: %v", got)
+	}
+	first, second := ex.Messages[0], ex.Messages[1]
+	if first.Author != "Alice" || second.Author != "simbot" {
+		t.Errorf("authors = %q, %q", first.Author, second.Author)
+	}
+	if !first.CreatedAt.Equal(time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("created = %v", first.CreatedAt)
+	}
+	if first.ChannelID != ex.ChannelID {
+		t.Errorf("message channel = %q", first.ChannelID)
+	}
+	// share links that only appear in embeds are kept
+	if !strings.Contains(second.Content, "https://wfpsim.com/sh/aaaaaaaa-0000-0000-0000-000000000002") {
+		t.Errorf("embed url missing: %q", second.Content)
+	}
+
+	dm := loadExport(t, "dm.json")
+	if dm.GuildID != "" || dm.ChannelID != "333333333333333333" {
+		t.Errorf("dm guild/channel = %q/%q", dm.GuildID, dm.ChannelID)
+	}
+	// without a timestamp the time comes from the snowflake
+	if dm.Messages[0].CreatedAt.IsZero() {
+		t.Error("dm message has no time")
+	}
+}
+
+func TestLoadHTMLExport(t *testing.T) {
+	ex := loadExport(t, "Theorycrafting - sims [222222222222222222].html")
+	// the link to another channel in the first message must not win over the file name
+	if ex.GuildID != "111111111111111111" || ex.ChannelID != "222222222222222222" {
+		t.Fatalf("guild/channel = %s/%s", ex.GuildID, ex.ChannelID)
+	}
+	if got := messageIDs(ex); !reflect.DeepEqual(got, []string{"1300000000000000001", "1300000000000000002"}) {
+		t.Fatalf("messages = %v", got)
+	}
+	first, second := ex.Messages[0], ex.Messages[1]
+	if first.Author != "Alice" || second.Author != "Alice" {
+		t.Errorf("authors = %q, %q (the group author is inherited)", first.Author, second.Author)
+	}
+	if !strings.Contains(first.Content, "https://wfpsim.com/sh/aaaaaaaa-0000-0000-0000-000000000001") {
+		t.Errorf("share link missing: %q", first.Content)
+	}
+	if !strings.Contains(second.Content, "& more") {
+		t.Errorf("entities not unescaped: %q", second.Content)
+	}
+
+	ex = loadExport(t, "preamble.html")
+	if ex.GuildID != "111111111111111111" || ex.ChannelID != "222222222222222222" {
+		t.Errorf("preamble: guild/channel = %s/%s", ex.GuildID, ex.ChannelID)
+	}
+
+	ex = loadExport(t, "unnamed.html")
+	if ex.GuildID != "" || ex.ChannelID != "" {
+		t.Errorf("unnamed: guild/channel = %s/%s, want unknown", ex.GuildID, ex.ChannelID)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/state"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
+)
+
+func TestErrorClass(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{&wfpsim.StatusError{Provider: "wfpsim", Code: 404}, wfpsim.FailNotFound},
+		{fmt.Errorf("wrapped: %w", &wfpsim.StatusError{Provider: "gcsim", Code: 410}), wfpsim.FailNotFound},
+		{&wfpsim.StatusError{Provider: "wfpsim", Code: 429}, wfpsim.FailRateLimited},
+		{&wfpsim.StatusError{Provider: "wfpsim", Code: 503}, wfpsim.FailServer},
+		{&wfpsim.StatusError{Provider: "wfpsim", Code: 403}, wfpsim.FailClient},
+		{&url.Error{Op: "Get", URL: "https://wfpsim.com", Err: errors.New("connection reset")}, wfpsim.FailNetwork},
+		{errors.New("unexpected EOF"), wfpsim.FailInvalid},
+	}
+	for _, c := range cases {
+		if got := wfpsim.ErrorClass(c.err); got != c.want {
+			t.Errorf("ErrorClass(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestRecordFailureSchedule(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := state.RetryPolicy{MaxAttempts: 4, Backoff: time.Hour, MaxBackoff: 3 * time.Hour}
+	st := state.State{}
+	ref := state.FailedKey{MessageID: "m1", ErrorClass: wfpsim.FailServer, Error: "wfpsim api status 502"}
+
+	wantWaits := []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}
+	for i, want := range wantWaits {
+		f := st.RecordFailure("k1", ref, false, p, now)
+		if f.Attempts != i+1 || f.Permanent || f.NextRetry.Sub(now) != want {
+			t.Fatalf("failure %d: %+v, want wait %s", i+1, f, want)
+		}
+		if got := st.DueFailedKeys(now); len(got) != 0 {
+			t.Fatalf("due right after failure: %v", got)
+		}
+		if got := st.DueFailedKeys(f.NextRetry); len(got) != 1 || got[0] != "k1" {
+			t.Fatalf("due at NextRetry = %v", got)
+		}
+		now = f.NextRetry
+	}
+	f := st.RecordFailure("k1", ref, false, p, now)
+	if !f.Permanent || f.Attempts != 4 || !f.NextRetry.IsZero() {
+		t.Errorf("after MaxAttempts: %+v", f)
+	}
+	if f.FirstFailed.Equal(f.LastFailed) {
+		t.Errorf("FirstFailed not kept: %+v", f)
+	}
+
+	// a final error is given up on the first failure and never comes due
+	f = st.RecordFailure("k2", ref, true, p, now)
+	if !f.Permanent || f.Attempts != 1 {
+		t.Errorf("final failure: %+v", f)
+	}
+	if got := st.DueFailedKeys(now.Add(365 * 24 * time.Hour)); len(got) != 0 {
+		t.Errorf("permanent keys came due: %v", got)
+	}
+}
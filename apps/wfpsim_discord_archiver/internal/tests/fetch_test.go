@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/pool"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/ratelimit"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
+)
+
+func TestPoolOrderedKeepsInputOrder(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+	var running, peak atomic.Int32
+	do := func(ctx context.Context, i int) (int, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+		running.Add(-1)
+		if i%7 == 0 {
+			return 0, errors.New("boom")
+		}
+		return i * i, nil
+	}
+
+	var got []int
+	err := pool.Ordered(context.Background(), items, 4, do, func(i int, res int, err error) error {
+		if (err != nil) != (i%7 == 0) || (err == nil && res != i*i) {
+			t.Errorf("item %d: res=%d err=%v", i, res, err)
+		}
+		got = append(got, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("emit order = %v", got)
+		}
+	}
+	if len(got) != len(items) {
+		t.Fatalf("emitted %d of %d", len(got), len(items))
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("peak concurrency = %d, want <= 4", p)
+	}
+
+	// an emit error stops the run
+	stop := errors.New("stop")
+	calls := 0
+	err = pool.Ordered(context.Background(), items, 4, do, func(i int, res int, err error) error {
+		calls++
+		if i == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 4 {
+		t.Errorf("emit error: err=%v calls=%d", err, calls)
+	}
+}
+
+// fakeClock drives a limiter without real sleeping.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestLimiterTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	start := clock.now
+	l := ratelimit.New(2, 2)
+	l.Now = clock.Now
+	l.Sleep = clock.Sleep
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// burst of 2, then one every 500ms
+	if got := clock.now.Sub(start); got != 1500*time.Millisecond {
+		t.Errorf("5 requests took %s, want 1.5s", got)
+	}
+
+	pauseEnd := clock.now.Add(10 * time.Second)
+	l.PauseUntil(pauseEnd)
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if clock.now.Before(pauseEnd) {
+		t.Errorf("Wait returned at %s during pause until %s", clock.now, pauseEnd)
+	}
+}
+
+func TestClientRetriesWithRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(shareJSON))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	var waits []time.Duration
+	c := wfpsim.New()
+	c.Register(wfpsim.NewShareAPI(shareurl.ProviderWfpsim, srv.URL, srv.Client()))
+	c.Backoff = time.Second
+	c.Sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	share, err := c.FetchShare(context.Background(), wfpsimID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.Statistics.DPS.Mean != 1234.5 {
+		t.Errorf("decoded %+v", share)
+	}
+	// Retry-After beats the 1s backoff; the second retry uses the doubled backoff.
+	if want := []time.Duration{7 * time.Second, 2 * time.Second}; len(waits) != 2 || waits[0] != want[0] || waits[1] != want[1] {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+
+	// A Retry-After above MaxBackoff is honoured in full; MaxBackoff caps only the computed backoff.
+	calls.Store(0)
+	srvLong := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "300")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(shareJSON))
+		}
+	}))
+	t.Cleanup(srvLong.Close)
+	c.Register(wfpsim.NewShareAPI(shareurl.ProviderWfpsim, srvLong.URL, srvLong.Client()))
+	c.Backoff = 40 * time.Second
+	c.MaxBackoff = time.Minute
+	waits = nil
+	if _, err := c.FetchShare(context.Background(), wfpsimID); err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Duration{300 * time.Second, time.Minute}; len(waits) != 2 || waits[0] != want[0] || waits[1] != want[1] {
+		t.Errorf("long Retry-After: waits = %v, want %v", waits, want)
+	}
+
+	// 404 is final
+	calls.Store(0)
+	srv404 := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv404.Close)
+	c.Register(wfpsim.NewShareAPI(shareurl.ProviderWfpsim, srv404.URL, srv404.Client()))
+	waits = nil
+	if _, err := c.FetchShare(context.Background(), wfpsimID); err == nil || !strings.Contains(err.Error(), "status 404") || len(waits) != 0 {
+		t.Errorf("404: err=%v waits=%v", err, waits)
+	}
+}
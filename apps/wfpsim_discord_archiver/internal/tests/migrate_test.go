@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/app"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/localxlsx"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestMigrateXLSXIntoStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	want := []store.Record{
+		{
+			FetchedAt:          "2025-01-05T10:01:00Z",
+			GuildID:            "1",
+			ChannelID:          "2",
+			MessageID:          "3",
+			MessageURL:         "https://discord.com/channels/1/2/3",
+			Author:             "Alice",
+			MessageCreatedAt:   "2025-01-05T10:00:00Z",
+			Key:                "hyper",
+			ShareURL:           "https://wfpsim.com/sh/hyper",
+			TeamCharacters:     "furina,neuvillette,kazuha,baizhu",
+			TeamWeapons:        "splendoroftranquilwaters(r1),tomeoftheeternalflow(r1),freedomsworn(r1),jadefallssplendor(r1)",
+			TeamDpsMean:        80000.5,
+			TeamDpsQ2:          79000,
+			ConfigFile:         "furina char lvl=90/90 cons=0 talent=9,9,9;",
+			SimVersion:         "v2.30.0",
+			SchemaMajor:        4,
+			SchemaMinor:        2,
+			TeamConstellations: "C0,C1,C0,C0",
+			Provider:           "wfpsim",
+		},
+		{
+			Key:                "national",
+			TeamCharacters:     "bennett,furina,raiden,xiangling",
+			TeamConstellations: "C6,C2,C0,C6",
+			TeamDpsMean:        60000,
+			Provider:           "wfpsim",
+			ConfigFingerprint:  "abc",
+			DuplicateOf:        "hyper",
+		},
+	}
+	xlsxPath := filepath.Join("output", "wfpsim_discord_archiver", "archive.xlsx")
+	if err := localxlsx.New(xlsxPath, "wfpsim").Export(want); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	var cfg config.Config
+	cfg.Sheet.Name = "wfpsim"
+	cfg.Run.StoreFile = filepath.Join(dir, "work", "archive.jsonl")
+	if err := app.ExportXLSX(context.Background(), cfg); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	s := openTestStore(t, cfg.Run.StoreFile)
+	got, err := s.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := map[string]store.Record{}
+	for _, r := range got {
+		byKey[r.Key] = r
+	}
+	if len(got) != len(want) {
+		t.Fatalf("migrated %d records, want %d", len(got), len(want))
+	}
+	for _, w := range want {
+		if g := byKey[w.Key]; !reflect.DeepEqual(g, w) {
+			t.Errorf("record %s:\n got %+v\nwant %+v", w.Key, g, w)
+		}
+	}
+
+	// The store is the source of truth now: a second run does not import again.
+	if err := os.Remove(xlsxPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.ExportXLSX(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if recs, _ := localxlsx.ReadRecords(xlsxPath, "wfpsim"); len(recs) != len(want) {
+		t.Fatalf("re-export has %d records", len(recs))
+	}
+}
+
+func TestReadRecordsHeaderlessSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.xlsx")
+	f := excelize.NewFile()
+	// Old layout without a header row: the key is the eighth column.
+	rows := [][]interface{}{
+		{"Furina C0", "", 1000, "", "", "", "", "first"},
+		{"Raiden C2", "", 2000, "", "", "", "", "Second"},
+	}
+	for i, r := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	recs, err := localxlsx.ReadRecords(path, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first row is taken as the header.
+	if len(recs) != 1 || recs[0].Key != "second" {
+		t.Fatalf("records = %+v", recs)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
+)
+
+const (
+	wfpsimID = "0123abcd-0123-0123-0123-0123456789ab"
+	gcsimID  = "fedcba98-7654-3210-fedc-ba9876543210"
+	dbID     = "65a1b2c3d4e5f6a7b8c9d0e1"
+)
+
+func TestExtractLinksAllProviders(t *testing.T) {
+	text := "first https://gcsim.app/db/" + strings.ToUpper(dbID) +
+		" then https://wfpsim.com/sh/" + wfpsimID +
+		" and <https://gcsim.app/sh/" + gcsimID + "> again https://wfpsim.com/sh/" + wfpsimID +
+		" not a share https://gcsim.app/viewer/" + gcsimID
+
+	got := shareurl.ExtractKeysFromText(text)
+	want := []string{"gcsim-db:" + dbID, wfpsimID, "gcsim:" + gcsimID}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+
+	for _, key := range want {
+		link, ok := shareurl.ParseKey(key)
+		if !ok {
+			t.Fatalf("ParseKey(%q) failed", key)
+		}
+		if link.Key() != key {
+			t.Errorf("ParseKey(%q).Key() = %q", key, link.Key())
+		}
+		if k, ok := shareurl.ExtractKeyFromURL(link.URL()); !ok || k != key {
+			t.Errorf("URL round trip of %q = %q, %v", key, k, ok)
+		}
+	}
+	if _, ok := shareurl.ParseKey("pastebin:abc"); ok {
+		t.Errorf("unknown provider key parsed")
+	}
+}
+
+const shareJSON = `{
+	"character_details": [{"name": "raiden", "weapon": {"name": "engulfinglightning", "refine": 1}}],
+	"config_file": "raiden char lvl=90/90 cons=2 talent=9,9,9;",
+	"schema_version": {"major": "4", "minor": 2},
+	"sim_version": "abc123",
+	"statistics": {"dps": {"mean": 1234.5, "q2": 1200}}
+}`
+
+// shareStandIn serves /api/share/<id> for one id and /api/db/id/<id> entries pointing at it.
+func shareStandIn(t *testing.T, shareID string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/share/", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if strings.TrimPrefix(r.URL.Path, "/api/share/") != shareID {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(shareJSON))
+	})
+	mux.HandleFunc("/api/db/id/", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if strings.TrimPrefix(r.URL.Path, "/api/db/id/") != dbID {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"_id": "` + dbID + `", "shareKey": "` + shareID + `"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestClientDispatchesByProvider(t *testing.T) {
+	wfp, wfpCalls := shareStandIn(t, wfpsimID)
+	gc, gcCalls := shareStandIn(t, gcsimID)
+
+	c := wfpsim.New()
+	gcShares := wfpsim.NewShareAPI(shareurl.ProviderGcsim, gc.URL, gc.Client())
+	c.Register(wfpsim.NewShareAPI(shareurl.ProviderWfpsim, wfp.URL, wfp.Client()))
+	c.Register(gcShares)
+	c.Register(wfpsim.NewDBAPI(shareurl.ProviderGcsimDB, gc.URL, gcShares, gc.Client()))
+
+	ctx := context.Background()
+	for _, key := range []string{wfpsimID, "gcsim:" + gcsimID, "gcsim-db:" + dbID} {
+		share, err := c.FetchShare(ctx, key)
+		if err != nil {
+			t.Fatalf("FetchShare(%q): %v", key, err)
+		}
+		if share.Statistics.DPS.Mean != 1234.5 || share.SchemaVersion.Major != 4 || len(share.CharacterDetails) != 1 {
+			t.Errorf("FetchShare(%q) decoded %+v", key, share)
+		}
+	}
+	if got := wfpCalls.Load(); got != 1 {
+		t.Errorf("wfpsim calls = %d, want 1", got)
+	}
+	// gcsim share + db entry + the share it points at
+	if got := gcCalls.Load(); got != 3 {
+		t.Errorf("gcsim calls = %d, want 3", got)
+	}
+
+	if _, err := c.FetchShare(ctx, "gcsim:"+wfpsimID); err == nil || !strings.Contains(err.Error(), "gcsim api status 404") {
+		t.Errorf("missing gcsim share err = %v", err)
+	}
+	if _, err := c.FetchShare(ctx, "pastebin:abc"); err == nil {
+		t.Errorf("unknown provider key fetched")
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/config"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/query"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+var queryArchive = []store.Record{
+	{
+		Key:                "hyper",
+		TeamCharacters:     "furina,neuvillette,kazuha,baizhu",
+		TeamConstellations: "C0,C1,C0,C0",
+		TeamWeapons:        "splendoroftranquilwaters(r1),tomeoftheeternalflow(r1),freedomsworn(r1),jadefallssplendor(r1)",
+		TeamDpsMean:        80000,
+		Author:             "Alice",
+		MessageCreatedAt:   "2025-01-05T10:00:00Z",
+	},
+	{
+		Key:                "national",
+		TeamCharacters:     "bennett,furina,raiden,xiangling",
+		TeamConstellations: "C6,C2,C0,C6",
+		TeamWeapons:        "aquilafavonia(r1),favoniussword(r5),engulfinglightning(r1),thecatch(r5)",
+		TeamDpsMean:        60000,
+		Author:             "Bob",
+		MessageCreatedAt:   "2025-02-05T23:30:00Z",
+	},
+	{
+		Key:                "natlan",
+		TeamCharacters:     "citlali,furina,mavuika,xilonen",
+		TeamConstellations: "C0,C0,C0,",
+		TeamWeapons:        "starcallerswatch(r1),splendoroftranquilwaters(r1),ashgravenfirestorm(r1),peakpatrolsong(r1)",
+		TeamDpsMean:        90000,
+		Author:             "alice2",
+		MessageCreatedAt:   "2025-03-05T10:00:00Z",
+	},
+}
+
+func queryKeys(t *testing.T, args ...string) []string {
+	t.Helper()
+	qc, err := config.LoadQuery(args)
+	if err != nil {
+		t.Fatalf("LoadQuery(%v): %v", args, err)
+	}
+	var keys []string
+	for _, r := range query.Apply(queryArchive, qc.Filter) {
+		keys = append(keys, r.Key)
+	}
+	return keys
+}
+
+func TestQueryFilters(t *testing.T) {
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"natlan", "hyper", "national"}},
+		{[]string{"-limit", "1"}, []string{"natlan"}},
+		{[]string{"-chars", "Furina,raiden"}, []string{"national"}},
+		{[]string{"-cons", "furina:0"}, []string{"natlan", "hyper"}},
+		{[]string{"-cons", "furina:c1-c6"}, []string{"national"}},
+		// unknown constellation (empty cell) never matches a constellation filter
+		{[]string{"-cons", "xilonen:0-6"}, nil},
+		{[]string{"-weapons", "splendoroftranquilwaters,freedomsworn"}, []string{"hyper"}},
+		{[]string{"-min-dps", "70000", "-max-dps", "85000"}, []string{"hyper"}},
+		{[]string{"-author", "ALICE"}, []string{"natlan", "hyper"}},
+		{[]string{"-since", "2025-02-01", "-until", "2025-02-05"}, []string{"national"}},
+	}
+	for _, c := range cases {
+		if got := queryKeys(t, c.args...); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("query %v = %v, want %v", c.args, got, c.want)
+		}
+	}
+}
+
+func TestQueryArgsValidation(t *testing.T) {
+	for _, args := range [][]string{
+		{"-cons", "furina"},
+		{"-cons", "furina:7"},
+		{"-cons", "furina:3-1"},
+		{"-since", "05.01.2025"},
+		{"-min-dps", "10", "-max-dps", "5"},
+		{"-format", "json"},
+		{"extra"},
+	} {
+		if _, err := config.LoadQuery(args); err == nil {
+			t.Errorf("LoadQuery(%v) accepted", args)
+		}
+	}
+}
+
+func TestQueryCharAliases(t *testing.T) {
+	qc, err := config.LoadQuery([]string{"-chars", "ei", "-cons", "ei:0", "-config", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Apply(queryArchive, qc.Filter); len(got) != 0 {
+		t.Errorf("without a resolver aliases must not match, got %d records", len(got))
+	}
+
+	qc.Filter.Resolver = testResolver(t)
+	var keys []string
+	for _, r := range query.Apply(queryArchive, qc.Filter) {
+		keys = append(keys, r.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"national"}) {
+		t.Errorf("-chars ei = %v, want [national]", keys)
+	}
+
+	// archived names are canonicalised too
+	archive := []store.Record{{Key: "alias", TeamCharacters: "Ei,xq", TeamConstellations: "C0,C6"}}
+	if got := query.Apply(archive, query.Filter{Chars: []string{"raiden", "xingqiu"}, Resolver: qc.Filter.Resolver}); len(got) != 1 {
+		t.Errorf("archived aliases not resolved, got %d records", len(got))
+	}
+}
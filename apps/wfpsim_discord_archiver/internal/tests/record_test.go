@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/app"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/discord"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/wfpsim"
+)
+
+func TestShareDecodesCharacterDetails(t *testing.T) {
+	const body = `{
+		"character_details": [
+			{"name": "raiden", "element": "electro", "level": 90, "max_level": 90, "cons": 2,
+			 "weapon": {"name": "engulfinglightning", "refine": 1},
+			 "talents": {"attack": 9, "skill": 9, "burst": 10},
+			 "sets": {"emblemofseveredfate": 4, "gladiatorsfinale": 1}}
+		],
+		"statistics": {
+			"dps": {"mean": 1000},
+			"character_dps": [{"mean": 400, "min": null}],
+			"source_reactions": [{"sources": {"overload": {"mean": 12.5}}}],
+			"end_stats": [{"ending_energy": {"mean": 30}}]
+		}
+	}`
+	var share wfpsim.Share
+	if err := json.Unmarshal([]byte(body), &share); err != nil {
+		t.Fatal(err)
+	}
+	c := share.CharacterDetails[0]
+	if c.Cons != 2 || c.Talents != (wfpsim.Talents{Attack: 9, Skill: 9, Burst: 10}) || c.Sets["emblemofseveredfate"] != 4 {
+		t.Errorf("character = %+v", c)
+	}
+	st := share.Statistics
+	if len(st.CharacterDPS) != 1 || st.CharacterDPS[0].Mean != 400 {
+		t.Errorf("character_dps = %+v", st.CharacterDPS)
+	}
+	if st.SourceReactions[0].Sources["overload"].Mean != 12.5 || st.EndStats[0].EndingEnergy.Mean != 30 {
+		t.Errorf("reactions/energy = %+v %+v", st.SourceReactions, st.EndStats)
+	}
+}
+
+func TestRowVersions(t *testing.T) {
+	rec := store.Record{
+		Key:            "k1",
+		TeamCharacters: "bennett,raiden",
+		TeamDpsMean:    1000,
+		Provider:       "wfpsim",
+		CharDpsShare:   "10.0,40.0",
+		ArtifactSets:   "noblesseoblige(4),emblemofseveredfate(4)",
+		TalentLevels:   "1/9/9,9/9/10",
+	}
+	row := store.RowFromRecord(rec)
+	if got := store.RecordFromRow(row); got != rec {
+		t.Errorf("round trip = %+v, want %+v", got, rec)
+	}
+	if cells := store.CharacterCells(rec); len(cells) != 3 || cells[0] != rec.CharDpsShare || cells[2] != rec.TalentLevels {
+		t.Errorf("CharacterCells = %v", cells)
+	}
+
+	// a version 1 row (24 cells) is a prefix of the current layout; the newer columns stay empty
+	v1 := row[:24]
+	got := store.RecordFromRow(v1)
+	if got.CharDpsShare != "" || got.ArtifactSets != "" || got.TalentLevels != "" || got.Provider != "wfpsim" || got.Key != "k1" {
+		t.Errorf("v1 row = %+v", got)
+	}
+	if n := len(store.ResimCells(rec)); n != 5 {
+		t.Errorf("ResimCells has %d cells, want 5", n)
+	}
+}
+
+func TestBuildRowCharacterColumns(t *testing.T) {
+	share := wfpsim.Share{
+		ConfigFile: "xingqiu char lvl=90/90 cons=6 talent=9,9,9;\nbennett char lvl=90/90 cons=5 talent=1,9,9;\nraiden char lvl=90/90 cons=2 talent=9,9,10;",
+		CharacterDetails: []wfpsim.Character{
+			{Name: "xingqiu", Weapon: wfpsim.Weapon{Name: "sacrificialsword", Refine: 5},
+				Talents: wfpsim.Talents{Attack: 9, Skill: 9, Burst: 9},
+				Sets:    map[string]int{"noblesseoblige": 2, "emblemofseveredfate": 2, "gladiatorsfinale": 1}},
+			{Name: "bennett", Weapon: wfpsim.Weapon{Name: "aquilafavonia", Refine: 1},
+				Talents: wfpsim.Talents{Attack: 1, Skill: 9, Burst: 9},
+				Sets:    map[string]int{"noblesseoblige": 4}},
+			{Name: "raiden", Weapon: wfpsim.Weapon{Name: "engulfinglightning", Refine: 1},
+				Talents: wfpsim.Talents{Attack: 9, Skill: 9, Burst: 10},
+				Sets:    map[string]int{"emblemofseveredfate": 4}},
+		},
+	}
+	share.Statistics.DPS.Mean = 1000
+	share.Statistics.CharacterDPS = []wfpsim.DPS{{Mean: 250}, {Mean: 100}, {Mean: 650}}
+	msg := discord.Message{ID: "3", ChannelID: "2", Author: "Alice", CreatedAt: time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)}
+
+	row, err := app.BuildRow("1", msg, wfpsimID, share, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := store.RecordFromRow(row)
+	// per-character columns follow the sorted TeamCharacters, not CharacterDetails order
+	want := map[string]string{
+		"TeamCharacters":     "bennett,raiden,xingqiu",
+		"TeamConstellations": "C5,C2,C6",
+		"TeamWeapons":        "aquilafavonia(r1),engulfinglightning(r1),sacrificialsword(r5)",
+		"CharDpsShare":       "10.0,65.0,25.0",
+		"ArtifactSets":       "noblesseoblige(4),emblemofseveredfate(4),emblemofseveredfate(2)+noblesseoblige(2)",
+		"TalentLevels":       "1/9/9,9/9/10,9/9/9",
+	}
+	got := map[string]string{
+		"TeamCharacters":     rec.TeamCharacters,
+		"TeamConstellations": rec.TeamConstellations,
+		"TeamWeapons":        rec.TeamWeapons,
+		"CharDpsShare":       rec.CharDpsShare,
+		"ArtifactSets":       rec.ArtifactSets,
+		"TalentLevels":       rec.TalentLevels,
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+	if rec.ConfigFingerprint == "" || rec.Provider != "wfpsim" {
+		t.Errorf("fingerprint = %q, provider = %q", rec.ConfigFingerprint, rec.Provider)
+	}
+
+	// shares without character_dps leave the column empty instead of ",,"
+	share.Statistics.CharacterDPS = nil
+	row, err = app.BuildRow("1", msg, wfpsimID, share, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := store.RecordFromRow(row); rec.CharDpsShare != "" || rec.TalentLevels == "" {
+		t.Errorf("without character_dps: CharDpsShare = %q, TalentLevels = %q", rec.CharDpsShare, rec.TalentLevels)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/app"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/sim"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+// stubRunner returns res or err and records the configs it was given.
+type stubRunner struct {
+	res     *sim.SimulationResult
+	err     error
+	configs []string
+}
+
+func (r *stubRunner) Run(ctx context.Context, workDir string, configStr string) (*sim.SimulationResult, error) {
+	r.configs = append(r.configs, configStr)
+	return r.res, r.err
+}
+
+func simResult(dps float64, version string) *sim.SimulationResult {
+	res := &sim.SimulationResult{SimVersion: version}
+	res.Statistics.DPS.Mean = &dps
+	return res
+}
+
+// staleResim is a record with the results of an earlier re-simulation.
+func staleResim(config string) store.Record {
+	return store.Record{
+		Key:             "k",
+		ConfigFile:      config,
+		TeamDpsMean:     30000,
+		ResimStatus:     store.ResimInvalid,
+		ResimDpsMean:    1,
+		ResimDeltaPct:   -99,
+		ResimSimVersion: "old",
+		ResimError:      "old error",
+	}
+}
+
+func TestResimRecordOK(t *testing.T) {
+	runner := &stubRunner{res: simResult(31234.5, "v2")}
+	rec, err := app.ResimRecord(context.Background(), runner, t.TempDir(), staleResim("raiden char;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.configs) != 1 || runner.configs[0] != "raiden char;" {
+		t.Errorf("runner configs = %q", runner.configs)
+	}
+	if rec.ResimStatus != store.ResimOK || rec.ResimDpsMean != 31234.5 || rec.ResimSimVersion != "v2" {
+		t.Errorf("got %+v", rec)
+	}
+	// 31234.5 / 30000 = +4.115% -> rounded to one decimal
+	if rec.ResimDeltaPct != 4.1 {
+		t.Errorf("ResimDeltaPct = %v, want 4.1", rec.ResimDeltaPct)
+	}
+	if rec.ResimError != "" || rec.ResimAt == "" {
+		t.Errorf("ResimError = %q, ResimAt = %q", rec.ResimError, rec.ResimAt)
+	}
+
+	// No posted DPS: no delta.
+	stale := staleResim("raiden char;")
+	stale.TeamDpsMean = 0
+	rec, err = app.ResimRecord(context.Background(), runner, t.TempDir(), stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ResimStatus != store.ResimOK || rec.ResimDeltaPct != 0 {
+		t.Errorf("without posted dps: %+v", rec)
+	}
+}
+
+func TestResimRecordInvalid(t *testing.T) {
+	runner := &stubRunner{err: &sim.ConfigError{Output: "\n  parse error: unknown char foo\nat line 1", Err: errors.New("exit status 1")}}
+	stale := staleResim("foo char;")
+	stale.ResimStatus = store.ResimOK
+	rec, err := app.ResimRecord(context.Background(), runner, t.TempDir(), stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ResimStatus != store.ResimInvalid || rec.ResimError != "parse error: unknown char foo" {
+		t.Errorf("status %q, error %q", rec.ResimStatus, rec.ResimError)
+	}
+	if rec.ResimDpsMean != 0 || rec.ResimDeltaPct != 0 || rec.ResimSimVersion != "" {
+		t.Errorf("stale results kept: %+v", rec)
+	}
+}
+
+func TestResimRecordNoConfig(t *testing.T) {
+	runner := &stubRunner{res: simResult(1, "v2")}
+	rec, err := app.ResimRecord(context.Background(), runner, t.TempDir(), staleResim("  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.configs) != 0 {
+		t.Error("runner called without a config")
+	}
+	if rec.ResimStatus != store.ResimNoConfig || rec.ResimError != "" || rec.ResimSimVersion != "" || rec.ResimDpsMean != 0 {
+		t.Errorf("got %+v", rec)
+	}
+}
+
+func TestResimRecordEngineFailure(t *testing.T) {
+	runner := &stubRunner{err: errors.New("engine result missing statistics.dps.mean")}
+	if _, err := app.ResimRecord(context.Background(), runner, t.TempDir(), staleResim("raiden char;")); err == nil {
+		t.Fatal("expected engine failure to be returned")
+	}
+}
+
+func TestPrepareConfigIterations(t *testing.T) {
+	r := sim.CLIRunner{Iterations: 50}
+	cases := []struct{ in, want string }{
+		{"options iteration=1000 duration=90;\nraiden char;", "options iteration=50 duration=90;\nraiden char;"},
+		{"options duration=90;\nraiden char;", "options iteration=50 duration=90;\nraiden char;"},
+		{"raiden char;", "options iteration=50;\nraiden char;"},
+		{
+			"let iteration = 0;\noptions swap_delay=12 iteration = 1000;\nwhile iteration < 3 { iteration = iteration + 1; }",
+			"let iteration = 0;\noptions swap_delay=12 iteration = 50;\nwhile iteration < 3 { iteration = iteration + 1; }",
+		},
+		{"let iteration = 0;\nraiden char;", "options iteration=50;\nlet iteration = 0;\nraiden char;"},
+	}
+	for _, c := range cases {
+		if got := r.PrepareConfig(c.in); got != c.want {
+			t.Errorf("PrepareConfig(%q)\n got %q\nwant %q", c.in, got, c.want)
+		}
+	}
+	if got := (sim.CLIRunner{}).PrepareConfig(cases[0].in); got != cases[0].in {
+		t.Errorf("no override changed config: %q", got)
+	}
+}
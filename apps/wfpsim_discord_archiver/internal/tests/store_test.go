@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/store"
+)
+
+func openTestStore(t *testing.T, path string) *store.Store {
+	t.Helper()
+	s, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func storeKeys(t *testing.T, s *store.Store) []string {
+	t.Helper()
+	recs, err := s.All()
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	var keys []string
+	for _, r := range recs {
+		keys = append(keys, r.Key)
+	}
+	return keys
+}
+
+func TestStorePutReplacesInPlace(t *testing.T) {
+	s := openTestStore(t, filepath.Join(t.TempDir(), "archive.jsonl"))
+	for _, k := range []string{"a", "b", "c"} {
+		if _, err := s.Append(store.Record{Key: k, TeamDpsMean: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Put(store.Record{Key: "b", TeamDpsMean: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storeKeys(t, s); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("keys = %v", got)
+	}
+	rec, ok, err := s.Get("b")
+	if err != nil || !ok || rec.TeamDpsMean != 2 {
+		t.Fatalf("get b = %+v, %v, %v", rec, ok, err)
+	}
+}
+
+func TestStoreCompactDropsSupersededLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	for _, k := range []string{"a", "b"} {
+		if _, err := s.Append(store.Record{Key: k, TeamDpsMean: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Put(store.Record{Key: "a", TeamDpsMean: 3}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(path)
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Fatalf("size %d -> %d, want smaller", before.Size(), after.Size())
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file left behind: %v", err)
+	}
+
+	// The store keeps working after the swap and the files agree on reopen.
+	if _, err := s.Append(store.Record{Key: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	s2 := openTestStore(t, path)
+	if got := storeKeys(t, s2); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("keys after reopen = %v", got)
+	}
+	if rec, _, _ := s2.Get("a"); rec.TeamDpsMean != 3 {
+		t.Fatalf("a = %+v, want the Put value", rec)
+	}
+}
+
+func TestStoreCompactFailureKeepsStoreUsable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	if _, err := s.Append(store.Record{Key: "a", TeamDpsMean: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(store.Record{Key: "a", TeamDpsMean: 2}); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in place of the temp file makes the rewrite fail before the rename.
+	if err := os.Mkdir(path+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Compact(); err == nil {
+		t.Fatal("compact succeeded, want error")
+	}
+	if err := s.Put(store.Record{Key: "b"}); err != nil {
+		t.Fatalf("put after failed compact: %v", err)
+	}
+	if got := storeKeys(t, s); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("keys = %v", got)
+	}
+	if rec, _, err := s.Get("a"); err != nil || rec.TeamDpsMean != 2 {
+		t.Fatalf("a = %+v, %v", rec, err)
+	}
+}
+
+func TestStoreIgnoresLeftoverTempFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	if _, err := s.Append(store.Record{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(store.Record{Key: "a", TeamDpsMean: 5}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	// A crash mid-rewrite leaves a torn temp file; the archive itself is intact.
+	if err := os.WriteFile(path+".tmp", []byte(`{"key":"torn`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s2 := openTestStore(t, path)
+	if rec, ok, err := s2.Get("a"); err != nil || !ok || rec.TeamDpsMean != 5 {
+		t.Fatalf("a = %+v, %v, %v", rec, ok, err)
+	}
+	if err := s2.Compact(); err != nil {
+		t.Fatalf("compact over leftover temp file: %v", err)
+	}
+	if got := storeKeys(t, s2); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("keys = %v", got)
+	}
+}
+
+func TestStoreAppendAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	for _, k := range []string{"B", "a", "c"} {
+		if ok, err := s.Append(store.Record{Key: k}); err != nil || !ok {
+			t.Fatalf("append %s = %v, %v", k, ok, err)
+		}
+	}
+	if ok, err := s.Append(store.Record{Key: "b"}); err != nil || ok {
+		t.Fatalf("duplicate append = %v, %v", ok, err)
+	}
+	_ = s.Close()
+
+	s2 := openTestStore(t, path)
+	if got := storeKeys(t, s2); !reflect.DeepEqual(got, []string{"b", "a", "c"}) {
+		t.Fatalf("keys after reopen = %v", got)
+	}
+	if !s2.Has("A") {
+		t.Fatal("Has is not case-insensitive")
+	}
+}
+
+func TestStoreDropsTruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	if _, err := s.Append(store.Record{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	whole, _ := os.ReadFile(path)
+
+	// A crash mid-append leaves a line without its newline.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"key":"b","teamDps`)
+	_ = f.Close()
+
+	s2 := openTestStore(t, path)
+	if got := storeKeys(t, s2); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("keys = %v", got)
+	}
+	if b, _ := os.ReadFile(path); string(b) != string(whole) {
+		t.Fatalf("torn line not truncated: %q", b)
+	}
+	if _, err := s2.Append(store.Record{Key: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storeKeys(t, s2); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("keys after append = %v", got)
+	}
+}
+
+func TestStoreRebuildsStaleIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	s := openTestStore(t, path)
+	if _, err := s.Append(store.Record{Key: "a", TeamDpsMean: 1}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	staleIdx, _ := os.ReadFile(path + ".idx")
+
+	s = openTestStore(t, path)
+	if _, err := s.Append(store.Record{Key: "b", TeamDpsMean: 2}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	for name, idx := range map[string][]byte{
+		"behind":  staleIdx, // crash between the data and index appends
+		"garbage": []byte("not an index\n"),
+	} {
+		if err := os.WriteFile(path+".idx", idx, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		s := openTestStore(t, path)
+		if got := storeKeys(t, s); !reflect.DeepEqual(got, []string{"a", "b"}) {
+			t.Fatalf("%s: keys = %v", name, got)
+		}
+		if rec, _, err := s.Get("b"); err != nil || rec.TeamDpsMean != 2 {
+			t.Fatalf("%s: b = %+v, %v", name, rec, err)
+		}
+		_ = s.Close()
+	}
+}
@@ -0,0 +1,171 @@
+package wfpsim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider fetches shares from one share host and decodes them into Share.
+type Provider interface {
+	// Name is the shareurl provider name (shareurl.ProviderWfpsim, ...).
+	Name() string
+	FetchShare(ctx context.Context, id string) (Share, error)
+}
+
+// ShareAPI serves "<BaseURL>/api/share/<id>" (wfpsim.com and gcsim.app return the same result JSON).
+type ShareAPI struct {
+	name    string
+	BaseURL string
+	hc      *http.Client
+}
+
+func NewShareAPI(name string, baseURL string, hc *http.Client) *ShareAPI {
+	return &ShareAPI{name: name, BaseURL: strings.TrimRight(baseURL, "/"), hc: hc}
+}
+
+func (p *ShareAPI) Name() string { return p.name }
+
+func (p *ShareAPI) FetchShare(ctx context.Context, id string) (Share, error) {
+	var out Share
+	if err := getJSON(ctx, p.hc, p.name, fmt.Sprintf("%s/api/share/%s", p.BaseURL, id), &out); err != nil {
+		return Share{}, err
+	}
+	return out, nil
+}
+
+// DBAPI serves gcsim.app database entries: "<BaseURL>/api/db/id/<id>" returns the entry, whose
+// share key is then fetched from Shares.
+type DBAPI struct {
+	name    string
+	BaseURL string
+	Shares  Provider
+	hc      *http.Client
+}
+
+func NewDBAPI(name string, baseURL string, shares Provider, hc *http.Client) *DBAPI {
+	return &DBAPI{name: name, BaseURL: strings.TrimRight(baseURL, "/"), Shares: shares, hc: hc}
+}
+
+func (p *DBAPI) Name() string { return p.name }
+
+// dbEntry accepts both proto field names and their JSON (camelCase) form.
+type dbEntry struct {
+	ShareKey      string `json:"share_key"`
+	ShareKeyCamel string `json:"shareKey"`
+}
+
+func (p *DBAPI) FetchShare(ctx context.Context, id string) (Share, error) {
+	var entry dbEntry
+	if err := getJSON(ctx, p.hc, p.name, fmt.Sprintf("%s/api/db/id/%s", p.BaseURL, id), &entry); err != nil {
+		return Share{}, err
+	}
+	key := strings.TrimSpace(entry.ShareKey)
+	if key == "" {
+		key = strings.TrimSpace(entry.ShareKeyCamel)
+	}
+	if key == "" {
+		return Share{}, fmt.Errorf("%s entry %s has no share key", p.name, id)
+	}
+	return p.Shares.FetchShare(ctx, key)
+}
+
+func getJSON(ctx context.Context, hc *http.Client, provider string, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "gcsim-rostering wfpsim_discord_archiver")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Provider: provider, Code: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	return dec.Decode(out)
+}
+
+// StatusError is a non-2xx response of a share API.
+type StatusError struct {
+	Provider   string
+	Code       int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s api status %d", e.Provider, e.Code)
+}
+
+// Retryable reports whether the request may succeed later (rate limited or server error).
+func (e *StatusError) Retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Failure classes of FetchShare errors (see ErrorClass).
+const (
+	FailNotFound    = "not_found"
+	FailRateLimited = "rate_limited"
+	FailServer      = "server_error"
+	FailClient      = "client_error"
+	FailNetwork     = "network"
+	FailInvalid     = "invalid"
+)
+
+// ErrorClass classifies a FetchShare error. Only FailNotFound is final; the other classes may
+// succeed on a later run.
+func ErrorClass(err error) string {
+	var se *StatusError
+	switch {
+	case errors.As(err, &se):
+		switch {
+		case se.Code == http.StatusNotFound || se.Code == http.StatusGone:
+			return FailNotFound
+		case se.Code == http.StatusTooManyRequests:
+			return FailRateLimited
+		case se.Code >= 500:
+			return FailServer
+		default:
+			return FailClient
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailNetwork
+	}
+	var ue *url.Error
+	var ne net.Error
+	if errors.As(err, &ue) || errors.As(err, &ne) {
+		return FailNetwork
+	}
+	// undecodable responses, DB entries without a share key, ...
+	return FailInvalid
+}
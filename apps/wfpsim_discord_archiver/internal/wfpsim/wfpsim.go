@@ -2,20 +2,57 @@ package wfpsim
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/ratelimit"
+	"github.com/genshinsim/gcsim/apps/wfpsim_discord_archiver/internal/shareurl"
 )
 
+// Client fetches shares by archive key, dispatching to the provider the key belongs to
+// (see shareurl.ParseKey). It is safe for concurrent use; Limiter is shared by all callers.
 type Client struct {
-	hc *http.Client
+	hc        *http.Client
+	providers map[string]Provider
+
+	// Limiter throttles share fetches (nil = unlimited). A Retry-After pauses it for every caller.
+	Limiter *ratelimit.Limiter
+	// Rate-limited (429) and server error responses are retried MaxRetries times, waiting
+	// Backoff, doubled per attempt, up to MaxBackoff; a longer Retry-After is honoured in full
+	// (MaxBackoff caps only the computed backoff).
+	MaxRetries int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Sleep waits between retries (replaced in tests).
+	Sleep func(ctx context.Context, d time.Duration) error
 }
 
+// New returns a client with the wfpsim.com and gcsim.app providers registered.
 func New() *Client {
-	return &Client{hc: &http.Client{Timeout: 25 * time.Second}}
+	hc := &http.Client{Timeout: 25 * time.Second}
+	c := &Client{
+		hc:         hc,
+		providers:  map[string]Provider{},
+		MaxRetries: 4,
+		Backoff:    2 * time.Second,
+		MaxBackoff: time.Minute,
+		Sleep:      sleepCtx,
+	}
+	gcsimShares := NewShareAPI(shareurl.ProviderGcsim, "https://gcsim.app", hc)
+	c.Register(NewShareAPI(shareurl.ProviderWfpsim, "https://wfpsim.com", hc))
+	c.Register(gcsimShares)
+	c.Register(NewDBAPI(shareurl.ProviderGcsimDB, "https://gcsim.app", gcsimShares, hc))
+	return c
+}
+
+// Register adds p, replacing any provider with the same name.
+func (c *Client) Register(p Provider) {
+	c.providers[p.Name()] = p
 }
 
 type Share struct {
@@ -72,8 +109,15 @@ func (v *IntOrString) UnmarshalJSON(b []byte) error {
 }
 
 type Character struct {
-	Name   string `json:"name"`
-	Weapon Weapon `json:"weapon"`
+	Name     string  `json:"name"`
+	Element  string  `json:"element"`
+	Level    int     `json:"level"`
+	MaxLevel int     `json:"max_level"`
+	Cons     int     `json:"cons"`
+	Weapon   Weapon  `json:"weapon"`
+	Talents  Talents `json:"talents"`
+	// Sets maps artifact set keys to the number of equipped pieces.
+	Sets map[string]int `json:"sets"`
 }
 
 type Weapon struct {
@@ -81,10 +125,24 @@ type Weapon struct {
 	Refine int    `json:"refine"`
 }
 
+type Talents struct {
+	Attack int `json:"attack"`
+	Skill  int `json:"skill"`
+	Burst  int `json:"burst"`
+}
+
+// Statistics are the aggregated results of the share's iterations. Per-character slices are in
+// CharacterDetails order.
 type Statistics struct {
-	DPS DPS `json:"dps"`
+	DPS          DPS   `json:"dps"`
+	TotalDamage  DPS   `json:"total_damage"`
+	CharacterDPS []DPS `json:"character_dps"`
+	// SourceReactions counts the reactions triggered per character, by reaction name.
+	SourceReactions []SourceStats `json:"source_reactions"`
+	EndStats        []EndStats    `json:"end_stats"`
 }
 
+// DPS is a descriptive statistic over iterations (also used for damage, energy and counts).
 type DPS struct {
 	Mean float64 `json:"mean"`
 	Q2   float64 `json:"q2"`
@@ -92,30 +150,68 @@ type DPS struct {
 	Max  float64 `json:"max"`
 }
 
-func (c *Client) FetchShare(ctx context.Context, key string) (Share, error) {
-	url := fmt.Sprintf("https://wfpsim.com/api/share/%s", key)
+type SourceStats struct {
+	Sources map[string]DPS `json:"sources"`
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return Share{}, err
-	}
-	req.Header.Set("User-Agent", "gcsim-rostering wfpsim_discord_archiver")
-	req.Header.Set("Accept", "application/json")
+type EndStats struct {
+	EndingEnergy DPS `json:"ending_energy"`
+}
 
-	resp, err := c.hc.Do(req)
-	if err != nil {
-		return Share{}, err
+// FetchShare fetches the share for an archive key.
+func (c *Client) FetchShare(ctx context.Context, key string) (Share, error) {
+	link, ok := shareurl.ParseKey(key)
+	if !ok {
+		return Share{}, fmt.Errorf("invalid share key %q", key)
+	}
+	p, ok := c.providers[link.Provider]
+	if !ok {
+		return Share{}, fmt.Errorf("no share provider registered for %s", link.Provider)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return Share{}, fmt.Errorf("wfpsim api status %d", resp.StatusCode)
+	delay := c.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return Share{}, err
+		}
+		share, err := p.FetchShare(ctx, link.ID)
+		if err == nil {
+			return share, nil
+		}
+		if ctx.Err() != nil {
+			return Share{}, ctx.Err()
+		}
+		var se *StatusError
+		if !errors.As(err, &se) || !se.Retryable() || attempt >= c.MaxRetries {
+			return Share{}, err
+		}
+
+		wait := delay
+		if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+			wait = c.MaxBackoff
+		}
+		if se.RetryAfter > wait {
+			wait = se.RetryAfter
+		}
+		if se.Code == http.StatusTooManyRequests {
+			// everyone sharing the limiter backs off, not just this caller
+			c.Limiter.PauseUntil(time.Now().Add(wait))
+		}
+		fmt.Fprintf(os.Stderr, "warn: %v for %s; retrying in %s\n", err, key, wait)
+		if err := c.Sleep(ctx, wait); err != nil {
+			return Share{}, err
+		}
+		delay *= 2
 	}
+}
 
-	var out Share
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&out); err != nil {
-		return Share{}, err
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
-	return out, nil
 }